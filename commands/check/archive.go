@@ -0,0 +1,150 @@
+// Package check 实现了压缩包内容的校验。
+// 该文件负责将 <压缩包>!/<条目路径> 形式的记录按压缩包分组, 每个压缩包只流式读取一次, 无需解压即可校验包内文件。
+package check
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"time"
+
+	"gitee.com/MM-Q/fck/commands/internal/archive"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// archiveGroup 同一压缩包内待校验的记录
+type archiveGroup struct {
+	path    string                            // 压缩包路径
+	entries map[string]types.VirtualHashEntry // 条目路径 -> 校验记录
+}
+
+// useArchive 将校验文件中的所有路径视为指定压缩包内的条目
+//
+// 参数:
+//   - hashMap: 虚拟哈希映射表
+//   - archivePath: 压缩包路径
+//
+// 返回:
+//   - error: 压缩包格式不支持或不存在时返回错误
+//
+// 注意:
+//   - 记录路径本身为 <压缩包>!/<条目路径> 形式时仅使用条目路径, 便于用其他压缩包校验同一份清单
+func useArchive(hashMap types.VirtualHashMap, archivePath string) error {
+	if !archive.IsSupported(archivePath) {
+		return exitcode.Usagef("不支持的压缩包格式: %s", archivePath)
+	}
+	if _, err := os.Stat(archivePath); err != nil {
+		return fmt.Errorf("指定的压缩包不存在: %s", archivePath)
+	}
+
+	for filePath, entry := range hashMap {
+		name := archive.CleanName(filePath)
+		if _, inner, ok := archive.Split(filePath); ok {
+			name = inner
+		}
+		entry.RealPath = archive.Join(archivePath, name)
+		hashMap[filePath] = entry
+	}
+	return nil
+}
+
+// splitArchiveEntries 将位于压缩包内的记录按压缩包分组
+//
+// 参数:
+//   - hashMap: 虚拟哈希映射表
+//
+// 返回:
+//   - []archiveGroup: 按压缩包分组的记录(没有压缩包内的记录时为nil)
+//   - types.VirtualHashMap: 其余的普通文件记录
+func splitArchiveEntries(hashMap types.VirtualHashMap) ([]archiveGroup, types.VirtualHashMap) {
+	var groups []archiveGroup
+	files := make(types.VirtualHashMap, len(hashMap))
+	index := make(map[string]int) // 压缩包路径 -> 分组序号
+
+	for filePath, entry := range hashMap {
+		archivePath, name, ok := archive.Split(entry.RealPath)
+		if !ok {
+			files[filePath] = entry
+			continue
+		}
+
+		i, ok := index[archivePath]
+		if !ok {
+			i = len(groups)
+			index[archivePath] = i
+			groups = append(groups, archiveGroup{path: archivePath, entries: make(map[string]types.VirtualHashEntry)})
+		}
+		groups[i].entries[name] = entry
+	}
+
+	if groups == nil {
+		return nil, hashMap
+	}
+	return groups, files
+}
+
+// checkArchive 流式读取压缩包, 校验其中被记录的条目
+//
+// 参数:
+//   - group: 同一压缩包内待校验的记录
+//   - results: 校验结果通道
+//
+// 注意:
+//   - 所有记录的条目校验完成后立即停止读取
+//   - 压缩包中不存在的条目按文件不存在处理, 压缩包无法读取时剩余条目均报告错误
+func (c *fileChecker) checkArchive(group archiveGroup, results chan<- checkResult) {
+	pending := maps.Clone(group.entries)
+
+	err := archive.Walk(group.path, func(name string, r io.Reader) error {
+		if c.ctx.Err() != nil {
+			return fs.SkipAll
+		}
+		entry, ok := pending[name]
+		if !ok {
+			return nil
+		}
+		delete(pending, name)
+
+		start := time.Now()
+		result := checkResult{
+			filePath:     entry.RealPath,
+			expectedHash: entry.Hash,
+		}
+		sums, size, err := digest.HashReaderMulti(digest.LimitReader(r), []string{c.hashType})
+		if err != nil {
+			result.err = fmt.Errorf("计算文件哈希失败: %v", err)
+		} else {
+			result.actualHash = sums[0]
+			result.size = size
+		}
+		result.duration = time.Since(start)
+		c.stopOnFailure(result)
+		results <- result
+
+		if len(pending) == 0 {
+			return fs.SkipAll
+		}
+		return nil
+	})
+
+	// 快速失败模式下已取消校验, 其余条目不再报告
+	if c.ctx.Err() != nil {
+		return
+	}
+	for _, entry := range pending {
+		result := checkResult{
+			filePath:     entry.RealPath,
+			expectedHash: entry.Hash,
+		}
+		if err != nil {
+			result.err = fmt.Errorf("读取压缩包 %s 失败: %w", group.path, err)
+		} else {
+			result.err = fmt.Errorf("压缩包 %s 中不存在该文件", group.path)
+		}
+		results <- result
+	}
+}
@@ -0,0 +1,127 @@
+package check
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/output"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// writeTestZip 创建包含指定文件的 zip 压缩包
+func writeTestZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("创建压缩包失败: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("写入条目失败: %v", err)
+		}
+		_, _ = w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("关闭压缩包失败: %v", err)
+	}
+}
+
+func TestFileChecker_CheckArchive(t *testing.T) {
+	// 初始化命令标志
+	InitCheckCmd()
+
+	tempDir := t.TempDir()
+	zipPath := filepath.Join(tempDir, "d.zip")
+	writeTestZip(t, zipPath, map[string]string{"a.txt": "a", "sub/b.txt": "b"})
+	plainFile := filepath.Join(tempDir, "plain.txt")
+	if err := os.WriteFile(plainFile, []byte("a"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	const hashA = "0cc175b9c0f1b6a831c399e269772661" // md5("a")
+	hashMap := types.VirtualHashMap{
+		"d.zip!/a.txt":     {RealPath: zipPath + "!/a.txt", Hash: hashA},
+		"d.zip!/sub/b.txt": {RealPath: zipPath + "!/sub/b.txt", Hash: hashA},
+		"d.zip!/c.txt":     {RealPath: zipPath + "!/c.txt", Hash: hashA},
+		"lost.zip!/a.txt":  {RealPath: filepath.Join(tempDir, "lost.zip") + "!/a.txt", Hash: hashA},
+		"plain.txt":        {RealPath: plainFile, Hash: hashA},
+	}
+
+	var buf bytes.Buffer
+	records, err := output.NewWriter(&buf, output.FormatJSON, checkRecordColumns)
+	if err != nil {
+		t.Fatalf("创建写入器失败: %v", err)
+	}
+	checker := newFileChecker(colorlib.New(), "md5")
+	checker.records = records
+	if err := checker.checkFiles(hashMap); exitcode.Code(err) != exitcode.Failure {
+		t.Fatalf("checkFiles() 期望返回校验失败错误, 实际: %v", err)
+	}
+
+	var report struct {
+		Files []checkRecord `json:"files"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("解析JSON输出失败: %v\n%s", err, buf.String())
+	}
+
+	want := map[string]string{
+		zipPath + "!/a.txt":                            statusOK,
+		zipPath + "!/sub/b.txt":                        statusMismatch,
+		zipPath + "!/c.txt":                            statusMissing,
+		filepath.Join(tempDir, "lost.zip") + "!/a.txt": statusMissing,
+		plainFile: statusOK,
+	}
+	if len(report.Files) != len(want) {
+		t.Fatalf("期望 %d 条记录, 实际 %d 条", len(want), len(report.Files))
+	}
+	for _, r := range report.Files {
+		if want[r.Path] != r.Status {
+			t.Errorf("%s 状态 = %s, 期望 %s", r.Path, r.Status, want[r.Path])
+		}
+	}
+}
+
+func TestUseArchive(t *testing.T) {
+	tempDir := t.TempDir()
+	zipPath := filepath.Join(tempDir, "d.zip")
+	writeTestZip(t, zipPath, map[string]string{"a.txt": "a"})
+
+	hashMap := types.VirtualHashMap{
+		"./a.txt":          {RealPath: "a.txt", Hash: "1"},
+		"other.zip!/b.txt": {RealPath: "other.zip!/b.txt", Hash: "2"},
+		"dir\\sub\\c.txt":  {RealPath: "dir/sub/c.txt", Hash: "3"},
+	}
+	if err := useArchive(hashMap, zipPath); err != nil {
+		t.Fatalf("useArchive() 返回错误: %v", err)
+	}
+
+	want := map[string]string{
+		"./a.txt":          zipPath + "!/a.txt",
+		"other.zip!/b.txt": zipPath + "!/b.txt",
+		"dir\\sub\\c.txt":  zipPath + "!/dir/sub/c.txt",
+	}
+	for key, realPath := range want {
+		if got := hashMap[key].RealPath; got != realPath {
+			t.Errorf("%s 的路径 = %s, 期望 %s", key, got, realPath)
+		}
+	}
+
+	if err := useArchive(hashMap, filepath.Join(tempDir, "plain.txt")); err == nil {
+		t.Error("不支持的压缩包格式应返回错误")
+	}
+	if err := useArchive(hashMap, filepath.Join(tempDir, "lost.zip")); err == nil {
+		t.Error("压缩包不存在时应返回错误")
+	}
+}
@@ -3,50 +3,88 @@
 package check
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/hashcache"
+	"gitee.com/MM-Q/fck/commands/internal/merkle"
+	"gitee.com/MM-Q/fck/commands/internal/output"
 	"gitee.com/MM-Q/fck/commands/internal/types"
-	"gitee.com/MM-Q/go-kit/hash"
 )
 
 // fileChecker 文件校验器
 type fileChecker struct {
 	cl         *colorlib.ColorLib // 颜色库
 	hashType   string             // 哈希算法
-	maxWorkers int                // 最大并发数(默认: 逻辑处理器数量)
+	maxWorkers int                // 最大并发数(默认: 逻辑处理器数量, 可通过 --jobs 指定)
+	cache      *hashcache.Cache   // 哈希缓存(为nil时不使用缓存)
+	tree       *merkle.Options    // 目录树选项(为nil时按文件校验)
+	records    *output.Writer     // 结构化输出写入器(为nil时输出文本)
+	chunkSize  int64              // 分块大小(校验文件记录了分块哈希值时不为0)
+	metadata   []string           // 元数据字段(校验文件记录了元数据时不为nil)
+	symlinks   string             // 软链接处理策略(校验文件未记录时为空, 跟随软链接)
+	strictDir  string             // 严格模式下查找未记录文件的基准目录(为空时不查找)
+	strictSkip []string           // 严格模式下不报告的文件(校验文件本身及其签名文件)
+	report     *checkReport       // 校验报告(为nil时不生成报告)
+	refresh    *manifestRefresh   // 刷新模式下需要写回校验文件的变更(为nil时不刷新)
+	progress   *checkProgress     // 校验进度(为nil时不显示进度条)
+	resume     *resumeState       // 续校验状态(仅 --resume, 为nil时完整读取记录了分块哈希值的文件)
+	ctx        context.Context    // 校验上下文, 取消后不再开始新的校验
+	cancel     context.CancelFunc // 发现未通过的文件时取消校验(仅 --fail-fast, 为nil时校验全部文件)
 }
 
+// errStopped 快速失败模式下发现未通过的文件时取消校验上下文的原因
+var errStopped = errors.New("发现未通过的文件, 已停止校验")
+
 // newFileChecker 创建新的文件校验器
 func newFileChecker(cl *colorlib.ColorLib, hashType string) *fileChecker {
 	return &fileChecker{
 		cl:         cl,
 		hashType:   hashType,
 		maxWorkers: runtime.NumCPU(),
+		ctx:        context.Background(),
 	}
 }
 
 // checkResult 校验结果
 type checkResult struct {
-	filePath     string // 文件路径
-	expectedHash string // 期望的哈希值
-	actualHash   string // 实际的哈希值
-	err          error  // 错误信息
+	filePath     string        // 文件路径
+	expectedHash string        // 期望的哈希值
+	actualHash   string        // 实际的哈希值
+	err          error         // 错误信息
+	ranges       []byteRange   // 不一致的字节范围(仅记录了分块哈希值的文件)
+	drift        []string      // 发生变化的元数据(仅记录了元数据的文件)
+	unexpected   bool          // 磁盘上存在但校验文件中没有记录(仅严格模式)
+	size         int64         // 文件大小(字节)
+	duration     time.Duration // 校验耗时
 }
 
 // checkFiles 并发校验文件
 func (c *fileChecker) checkFiles(hashMap types.VirtualHashMap) error {
 	if len(hashMap) == 0 {
 		c.cl.PrintWarnf("没有文件需要校验\n")
+		if c.records != nil {
+			return c.records.Close(checkSummary{})
+		}
 		return nil
 	}
 
+	// 压缩包内的记录按压缩包分组, 每个压缩包只读取一次
+	groups, files := splitArchiveEntries(hashMap)
+
 	// 创建工作通道
-	jobs := make(chan types.VirtualHashEntry, len(hashMap))
+	jobs := make(chan types.VirtualHashEntry, len(files))
+	archiveJobs := make(chan archiveGroup, len(groups))
 	results := make(chan checkResult, len(hashMap))
 
 	// 启动工作协程
@@ -59,13 +97,39 @@ func (c *fileChecker) checkFiles(hashMap types.VirtualHashMap) error {
 		)
 	}
 
+	// 严格模式: 查找校验文件中没有记录的文件
+	if c.strictDir != "" {
+		wg.Go(
+			func() {
+				c.findUnexpected(hashMap, results)
+			},
+		)
+	}
+
+	for i := 0; i < min(c.maxWorkers, len(groups)); i++ {
+		wg.Go(
+			func() {
+				for group := range archiveJobs {
+					if c.ctx.Err() != nil {
+						return
+					}
+					c.checkArchive(group, results)
+				}
+			},
+		)
+	}
+
 	// 发送任务
 	go func() {
 		defer close(jobs)
-		for _, entry := range hashMap {
+		for _, entry := range files {
 			jobs <- entry
 		}
 	}()
+	for _, group := range groups {
+		archiveJobs <- group
+	}
+	close(archiveJobs)
 
 	// 等待所有工作完成
 	go func() {
@@ -77,84 +141,303 @@ func (c *fileChecker) checkFiles(hashMap types.VirtualHashMap) error {
 	return c.collectResults(results, len(hashMap))
 }
 
-// worker 工作协程
+// worker 工作协程, 校验上下文取消后退出
 func (c *fileChecker) worker(jobs <-chan types.VirtualHashEntry, results chan<- checkResult) {
 	for entry := range jobs {
-		result := checkResult{
-			filePath:     entry.RealPath,
-			expectedHash: entry.Hash,
+		if c.ctx.Err() != nil {
+			return
 		}
-
-		// 检查文件是否存在，如果不存在则发送错误结果
-		if _, err := os.Stat(entry.RealPath); err != nil {
-			results <- checkResult{
-				filePath: entry.RealPath,
-				err:      err,
-			}
-			continue
+		result := c.checkEntry(entry)
+		if isInterrupted(c.ctx) {
+			return // 中断时读取被取消的文件不输出结果
 		}
+		c.stopOnFailure(result)
+		results <- result
+	}
+}
+
+// withFailFast 启用快速失败模式, 发现未通过的文件时以 errStopped 取消校验上下文
+//
+// 返回:
+//   - context.CancelFunc: 校验结束后释放上下文的函数
+func (c *fileChecker) withFailFast() context.CancelFunc {
+	ctx, cancel := context.WithCancelCause(c.ctx)
+	c.ctx = ctx
+	c.cancel = func() { cancel(errStopped) }
+	return func() { cancel(nil) }
+}
+
+// isInterrupted 判断校验上下文是否因中断信号(而非快速失败)被取消
+//
+// 参数:
+//   - ctx: 校验上下文
+//
+// 返回:
+//   - bool: 收到中断信号时返回 true
+func isInterrupted(ctx context.Context) bool {
+	return ctx.Err() != nil && !errors.Is(context.Cause(ctx), errStopped)
+}
+
+// stopOnFailure 快速失败模式下校验结果未通过时立即取消其余校验
+//
+// 参数:
+//   - result: 校验结果
+//
+// 注意:
+//   - 由产生结果的协程调用, 结果通道有缓冲时工作协程不必等待结果被输出即可停止领取新任务
+func (c *fileChecker) stopOnFailure(result checkResult) {
+	if c.cancel != nil && resultStatus(result) != statusOK {
+		c.cancel()
+	}
+}
+
+// checkEntry 校验单条记录
+//
+// 参数:
+//   - entry: 校验文件中的记录
+//
+// 返回:
+//   - checkResult: 校验结果
+func (c *fileChecker) checkEntry(entry types.VirtualHashEntry) checkResult {
+	start := time.Now()
+	result := checkResult{
+		filePath:     entry.RealPath,
+		expectedHash: entry.Hash,
+	}
+
+	// 检查文件是否存在，如果不存在则返回错误结果(除 follow 策略外不跟随软链接)
+	linkMode := c.symlinks == types.SymlinksLink || c.metadata != nil
+	stat := os.Stat
+	if linkMode || c.symlinks == types.SymlinksSkip {
+		stat = os.Lstat
+	}
+	info, err := stat(entry.RealPath)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	isLink := info.Mode()&fs.ModeSymlink != 0
+	result.size = info.Size()
 
+	switch {
+	case isLink && linkMode:
+		// 软链接以链接目标作为内容
+		c.checkLink(entry, &result)
+	case isLink:
+		// skip 策略下生成校验文件时不会记录软链接
+		result.err = fmt.Errorf("该文件已变为软链接, 按校验文件记录的软链接处理策略(skip)不予校验")
+	case c.chunkSize > 0 && len(entry.Chunks) > 0 && c.resume != nil:
+		// 断点续校验: 跳过上次已通过校验的分块
+		c.resumeChunks(entry, info, &result)
+	case c.chunkSize > 0 && len(entry.Chunks) > 0:
+		// 记录了分块哈希值的文件逐块校验
+		c.checkChunks(entry, &result)
+	default:
 		// 计算文件哈希
-		actualHash, err := hash.Checksum(entry.RealPath, c.hashType)
+		actualHash, err := c.checksum(entry.RealPath)
 		if err != nil {
 			result.err = fmt.Errorf("计算文件哈希失败: %v", err)
 		} else {
 			result.actualHash = actualHash
 		}
+	}
 
-		results <- result
+	// 比较元数据
+	if result.err == nil && c.metadata != nil && entry.Metadata != "" {
+		c.checkMetadata(entry, &result)
 	}
+
+	result.duration = time.Since(start)
+	return result
+}
+
+// checksum 计算文件哈希值, 启用缓存且文件未变化时直接返回缓存结果
+//
+// 注意:
+//   - 目录树模式下计算目录的 Merkle 根摘要
+func (c *fileChecker) checksum(filePath string) (string, error) {
+	if c.tree == nil {
+		return c.cache.Checksum(filePath, c.hashType, digest.Checksum)
+	}
+
+	files, err := merkle.Collect(filePath, c.tree.Hidden)
+	if err != nil {
+		return "", err
+	}
+
+	sum := func(filePath, algorithm string) (string, error) {
+		return c.cache.Checksum(filePath, algorithm, digest.Checksum)
+	}
+
+	tree, err := merkle.Build(filePath, files, *c.tree, sum)
+	if err != nil {
+		return "", err
+	}
+
+	return tree.Root(), nil
 }
 
 // collectResults 收集校验结果
+//
+// 返回:
+//   - error: 输出失败时返回错误, 存在未通过的文件时返回 summaryError 生成的错误
 func (c *fileChecker) collectResults(results <-chan checkResult, totalFiles int) error {
-	var (
-		passedCount    int // 校验通过的文件数
-		mismatchCount  int // 哈希不匹配的文件数
-		notFoundCount  int // 文件不存在的文件数
-		errorCount     int // 其他错误的文件数
-		processedCount int // 总处理文件数
-	)
+	summary := checkSummary{Total: totalFiles}
 
 	for result := range results {
-		processedCount++
-
-		if result.err != nil {
-			// 检查是否是文件不存在错误
-			if os.IsNotExist(result.err) ||
-				strings.Contains(result.err.Error(), "不存在") ||
-				strings.Contains(result.err.Error(), "no such file") {
-				c.cl.Yellowf("文件 %s 不存在，跳过校验\n", result.filePath)
-				notFoundCount++
-			} else {
-				c.cl.Redf("%s ✗ (错误: %v)\n", result.filePath, result.err)
-				errorCount++
-			}
-			continue
+		status, err := c.handleResult(result)
+		if err != nil {
+			return err
 		}
+		summary.add(status)
+	}
+	c.progress.stop()
 
-		// 比较哈希值
-		if result.actualHash != result.expectedHash {
+	return c.finish(summary)
+}
+
+// handleResult 输出单个校验结果并记录到校验报告
+//
+// 参数:
+//   - result: 校验结果
+//
+// 返回:
+//   - string: 校验状态
+//   - error: 结构化输出失败时返回错误
+func (c *fileChecker) handleResult(result checkResult) (string, error) {
+	status := resultStatus(result)
+
+	// 记录到校验报告
+	if c.report != nil {
+		c.report.add(result, status)
+	}
+
+	// 记录刷新模式下需要写回的变更
+	if c.refresh != nil {
+		c.refresh.add(result, status)
+	}
+
+	// 更新校验进度
+	c.progress.add(result.size)
+
+	// 结构化输出
+	if c.records != nil {
+		if err := c.records.Write(newCheckRecord(result, status)); err != nil {
+			return status, fmt.Errorf("输出校验记录失败: %w", err)
+		}
+		return status, nil
+	}
+
+	switch status {
+	case statusMissing:
+		c.cl.Yellowf("文件 %s 不存在，跳过校验\n", result.filePath)
+	case statusError:
+		c.cl.Redf("%s ✗ (错误: %v)\n", result.filePath, result.err)
+	case statusUnexpected:
+		c.cl.Redf("%s ✗ (校验文件中没有该文件的记录)\n", result.filePath)
+	case statusMismatch:
+		switch {
+		case result.actualHash == result.expectedHash:
+			c.cl.Redf("%s ✗ (元数据不一致: %s)\n", result.filePath, strings.Join(result.drift, ", "))
+		case len(result.drift) > 0:
+			c.cl.Redf("%s ✗ (哈希不匹配, 元数据不一致: %s)\n", result.filePath, strings.Join(result.drift, ", "))
+		case len(result.ranges) > 0:
+			c.cl.Redf("%s ✗ (哈希不匹配, 不一致的字节范围: %s)\n", result.filePath, formatRanges(result.ranges))
+		default:
 			c.cl.Redf("%s ✗ (哈希不匹配, 文件可能已经被篡改)\n", result.filePath)
-			mismatchCount++ // 哈希不匹配
-		} else {
-			if !checkCmdQuiet.Get() {
-				// 非静默模式输出
-				c.cl.Greenf("%s ✓\n", result.filePath)
-			}
-			passedCount++ // 校验通过
+		}
+	default:
+		if !checkCmdQuiet.Get() {
+			// 非静默模式输出
+			c.cl.Greenf("%s ✓\n", result.filePath)
+		}
+	}
+	return status, nil
+}
+
+// finish 写入校验报告, 输出校验结果统计并生成对应退出码的错误
+//
+// 参数:
+//   - summary: 校验结果统计
+//
+// 返回:
+//   - error: 写入失败时返回错误, 收到中断信号时返回 exitcode.ErrInterrupted, 否则返回 summaryError 生成的错误
+func (c *fileChecker) finish(summary checkSummary) error {
+	// 收到中断信号时校验结果不完整, 不写入报告和统计
+	if isInterrupted(c.ctx) {
+		return exitcode.ErrInterrupted
+	}
+
+	// 写入校验报告
+	if c.report != nil {
+		if err := c.report.write(summary); err != nil {
+			return err
 		}
 	}
 
 	// 输出校验结果统计
-	c.printSummary(passedCount, mismatchCount, notFoundCount, errorCount, totalFiles)
+	c.warnStopped(summary)
+	if c.records != nil {
+		if err := c.records.Close(summary); err != nil {
+			return err
+		}
+	} else {
+		c.printSummary(summary.Passed, summary.Mismatched, summary.Missing, summary.Errors, summary.Unexpected, summary.Total)
+	}
+
+	return summaryError(summary)
+}
+
+// warnStopped 快速失败模式下提示因提前停止而未校验的文件数
+//
+// 参数:
+//   - summary: 校验结果统计
+func (c *fileChecker) warnStopped(summary checkSummary) {
+	if c.cancel == nil || !errors.Is(context.Cause(c.ctx), errStopped) {
+		return
+	}
+	skipped := summary.Total - (summary.Passed + summary.Mismatched + summary.Missing + summary.Errors)
+	if skipped > 0 {
+		c.cl.PrintWarnf("发现未通过的文件, 已停止校验(--fail-fast), %d 个文件未校验\n", skipped)
+	}
+}
 
-	return nil
+// summaryError 根据校验结果统计生成对应退出码的错误
+//
+// 参数:
+//   - summary: 校验结果统计
+//
+// 返回:
+//   - error: 全部通过时返回nil, 存在无法校验的文件时退出码为 exitcode.Partial,
+//     存在不匹配、不存在或未记录的文件时退出码为 exitcode.Failure
+//
+// 注意:
+//   - 校验结果已经输出, 返回的错误不携带错误信息
+func summaryError(summary checkSummary) error {
+	switch {
+	case summary.Errors > 0:
+		return exitcode.New(exitcode.Partial, nil)
+	case summary.Mismatched > 0 || summary.Missing > 0 || summary.Unexpected > 0:
+		return exitcode.New(exitcode.Failure, nil)
+	default:
+		return nil
+	}
 }
 
 // printSummary 打印校验结果摘要
-func (c *fileChecker) printSummary(passed, mismatched, notFound, errors, total int) {
+func (c *fileChecker) printSummary(passed, mismatched, notFound, errors, unexpected, total int) {
 	c.cl.Bluef("校验完成: ")
+	c.printCounts(passed, mismatched, notFound, errors, unexpected, total)
+
+	// 输出缓存命中统计
+	if c.cache != nil {
+		hits, misses := c.cache.Stats()
+		c.cl.Bluef("缓存统计: 命中 %d 个, 未命中 %d 个\n", hits, misses)
+	}
+}
+
+// printCounts 打印各校验状态的文件数量
+func (c *fileChecker) printCounts(passed, mismatched, notFound, errors, unexpected, total int) {
 	c.cl.Greenf("%d个通过", passed)
 
 	if mismatched > 0 {
@@ -172,5 +455,10 @@ func (c *fileChecker) printSummary(passed, mismatched, notFound, errors, total i
 		c.cl.Redf("%d个错误", errors)
 	}
 
+	if unexpected > 0 {
+		fmt.Print(", ")
+		c.cl.Redf("%d个未记录的文件", unexpected)
+	}
+
 	c.cl.Whitef(" (总计: %d个文件)\n", total)
 }
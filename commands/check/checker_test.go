@@ -1,14 +1,18 @@
 package check
 
 import (
+	"bytes"
 	"crypto/md5"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 
 	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/output"
 	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
@@ -46,9 +50,9 @@ func TestFileChecker_CheckFiles(t *testing.T) {
 	wrongHash := "00000000000000000000000000000000"
 
 	tests := []struct {
-		name        string
-		hashMap     types.VirtualHashMap
-		expectError bool
+		name       string
+		hashMap    types.VirtualHashMap
+		expectCode int
 	}{
 		{
 			name: "所有文件匹配",
@@ -62,7 +66,7 @@ func TestFileChecker_CheckFiles(t *testing.T) {
 					Hash:     hash2,
 				},
 			},
-			expectError: false,
+			expectCode: exitcode.OK,
 		},
 		{
 			name: "部分文件不匹配",
@@ -76,7 +80,7 @@ func TestFileChecker_CheckFiles(t *testing.T) {
 					Hash:     wrongHash,
 				},
 			},
-			expectError: false,
+			expectCode: exitcode.Failure,
 		},
 		{
 			name: "文件不存在",
@@ -86,28 +90,20 @@ func TestFileChecker_CheckFiles(t *testing.T) {
 					Hash:     hash1,
 				},
 			},
-			expectError: false,
+			expectCode: exitcode.Failure,
 		},
 		{
-			name:        "空的哈希映射",
-			hashMap:     types.VirtualHashMap{},
-			expectError: false,
+			name:       "空的哈希映射",
+			hashMap:    types.VirtualHashMap{},
+			expectCode: exitcode.OK,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			err := checker.checkFiles(tt.hashMap)
-
-			if tt.expectError {
-				if err == nil {
-					t.Errorf("期望错误但没有发生错误")
-				}
-				return
-			}
-
-			if err != nil {
-				t.Errorf("不期望错误但发生了错误: %v", err)
+			if code := exitcode.Code(err); code != tt.expectCode {
+				t.Errorf("期望退出码 %d, 实际 %d (错误: %v)", tt.expectCode, code, err)
 			}
 		})
 	}
@@ -257,7 +253,7 @@ func TestFileChecker_PrintSummary(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// 这个测试主要验证函数不会panic
 			// 实际的输出验证需要捕获stdout，这里简化处理
-			checker.printSummary(tt.processed, tt.mismatched, tt.errors, tt.total, tt.total)
+			checker.printSummary(tt.processed, tt.mismatched, tt.errors, tt.total, 0, tt.total)
 		})
 	}
 }
@@ -317,7 +313,141 @@ func TestFileChecker_CollectResults(t *testing.T) {
 
 	// 收集结果
 	err := checker.collectResults(results, 3)
+	if exitcode.Code(err) != exitcode.Partial {
+		t.Errorf("collectResults期望返回部分失败错误, 实际: %v", err)
+	}
+}
+
+func TestFileChecker_WorkerSymlinkPolicy(t *testing.T) {
+	tempDir := t.TempDir()
+	target := filepath.Join(tempDir, "a.txt")
+	if err := os.WriteFile(target, []byte("a"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	link := filepath.Join(tempDir, "link")
+	if err := os.Symlink("a.txt", link); err != nil {
+		t.Skipf("当前平台不支持创建软链接: %v", err)
+	}
+
+	const (
+		hashContent = "0cc175b9c0f1b6a831c399e269772661" // md5("a")
+		hashTarget  = "a5e54d1fd7bb69a228ef0dcd2431367e" // md5("a.txt")
+	)
+
+	tests := []struct {
+		name      string
+		policy    string
+		wantHash  string
+		expectErr bool
+	}{
+		{name: "未记录策略时跟随软链接", policy: "", wantHash: hashContent},
+		{name: "跟随软链接", policy: types.SymlinksFollow, wantHash: hashContent},
+		{name: "以链接目标作为内容", policy: types.SymlinksLink, wantHash: hashTarget},
+		{name: "跳过策略下文件变为软链接", policy: types.SymlinksSkip, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := newFileChecker(colorlib.New(), "md5")
+			checker.symlinks = tt.policy
+
+			jobs := make(chan types.VirtualHashEntry, 1)
+			results := make(chan checkResult, 1)
+			jobs <- types.VirtualHashEntry{RealPath: link, Hash: tt.wantHash}
+			close(jobs)
+			checker.worker(jobs, results)
+			close(results)
+
+			result := <-results
+			if tt.expectErr {
+				if result.err == nil {
+					t.Error("期望错误但没有发生错误")
+				}
+				return
+			}
+			if result.err != nil {
+				t.Fatalf("worker处理出错: %v", result.err)
+			}
+			if result.actualHash != tt.wantHash {
+				t.Errorf("实际哈希 = %s, 期望 %s", result.actualHash, tt.wantHash)
+			}
+		})
+	}
+}
+
+// TestFileChecker_FailFast 测试快速失败模式的取消逻辑
+func TestFileChecker_FailFast(t *testing.T) {
+	// 初始化命令标志
+	InitCheckCmd()
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "a.txt")
+	if err := os.WriteFile(testFile, []byte("a"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	checker := newFileChecker(colorlib.New(), "md5")
+	defer checker.withFailFast()()
+
+	// 校验通过的文件不会取消校验
+	checker.stopOnFailure(checkResult{filePath: testFile, expectedHash: "h", actualHash: "h"})
+	if checker.ctx.Err() != nil {
+		t.Fatal("校验通过时不应取消校验")
+	}
+
+	// 第一个不匹配的文件取消其余校验
+	checker.stopOnFailure(checkResult{filePath: testFile, expectedHash: "h", actualHash: "x"})
+	if checker.ctx.Err() == nil {
+		t.Fatal("发现不匹配的文件后应取消校验")
+	}
+
+	// 取消后工作协程不再开始新的校验
+	jobs := make(chan types.VirtualHashEntry, 2)
+	results := make(chan checkResult, 2)
+	jobs <- types.VirtualHashEntry{RealPath: testFile, Hash: "0cc175b9c0f1b6a831c399e269772661"}
+	jobs <- types.VirtualHashEntry{RealPath: testFile, Hash: "0cc175b9c0f1b6a831c399e269772661"}
+	close(jobs)
+	checker.worker(jobs, results)
+	if len(results) != 0 {
+		t.Errorf("取消后期望不再校验文件, 实际校验了 %d 个", len(results))
+	}
+}
+
+// TestFileChecker_FailFastCheckFiles 测试快速失败模式下 checkFiles 提前停止
+func TestFileChecker_FailFastCheckFiles(t *testing.T) {
+	// 初始化命令标志
+	InitCheckCmd()
+
+	const total = 100
+	tempDir := t.TempDir()
+	hashMap := make(types.VirtualHashMap, total)
+	for i := range total {
+		name := fmt.Sprintf("f%03d.txt", i)
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+		hashMap[name] = types.VirtualHashEntry{RealPath: path, Hash: "00000000000000000000000000000000"}
+	}
+
+	var buf bytes.Buffer
+	records, err := output.NewWriter(&buf, output.FormatNDJSON, checkRecordColumns)
 	if err != nil {
-		t.Errorf("collectResults返回了错误: %v", err)
+		t.Fatalf("创建写入器失败: %v", err)
+	}
+
+	checker := newFileChecker(colorlib.New(), "md5")
+	checker.maxWorkers = 1
+	checker.records = records
+	defer checker.withFailFast()()
+
+	if err := checker.checkFiles(hashMap); exitcode.Code(err) != exitcode.Failure {
+		t.Fatalf("checkFiles() 期望返回校验失败错误, 实际: %v", err)
+	}
+
+	// 最后一行为汇总信息
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if got := len(lines) - 1; got >= total {
+		t.Errorf("快速失败模式下期望提前停止, 实际输出了 %d 条记录", got)
 	}
 }
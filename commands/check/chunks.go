@@ -0,0 +1,86 @@
+// Package check 实现了分块校验。
+// 该文件负责对记录了分块哈希值的文件逐块比较, 并将不一致的分块合并为字节范围, 便于定位大文件中被修改的位置。
+package check
+
+import (
+	"fmt"
+	"strings"
+
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// byteRange 字节范围 [start, end]
+type byteRange struct {
+	start int64 // 起始偏移(包含)
+	end   int64 // 结束偏移(包含)
+}
+
+// String 返回 start-end 形式的字节范围
+func (r byteRange) String() string {
+	return fmt.Sprintf("%d-%d", r.start, r.end)
+}
+
+// checkChunks 一次读取计算文件的整体哈希值和分块哈希值, 整体哈希不匹配时找出不一致的字节范围
+//
+// 参数:
+//   - entry: 校验文件中的记录(包含分块哈希值)
+//   - result: 校验结果
+func (c *fileChecker) checkChunks(entry types.VirtualHashEntry, result *checkResult) {
+	sums, chunks, size, err := digest.ChecksumChunks(entry.RealPath, []string{c.hashType}, c.chunkSize)
+	if err != nil {
+		result.err = fmt.Errorf("计算文件哈希失败: %v", err)
+		return
+	}
+
+	result.actualHash = sums[0]
+	if result.actualHash == result.expectedHash {
+		return
+	}
+
+	result.ranges = mismatchedRanges(entry.Chunks, chunks[0], c.chunkSize, max(entry.ChunkedSize, size))
+}
+
+// mismatchedRanges 比较分块哈希值, 将相邻的不一致分块合并为字节范围
+//
+// 参数:
+//   - expected: 校验文件中记录的分块哈希值
+//   - actual: 实际计算的分块哈希值
+//   - chunkSize: 分块大小
+//   - extent: 记录时与当前文件大小中的较大值
+//
+// 返回:
+//   - []byteRange: 不一致的字节范围(按偏移升序)
+//
+// 注意:
+//   - 文件变长或变短时, 多出或缺少的分块同样视为不一致
+func mismatchedRanges(expected, actual []string, chunkSize, extent int64) []byteRange {
+	var ranges []byteRange
+
+	for i := 0; i < max(len(expected), len(actual)); i++ {
+		if i < len(expected) && i < len(actual) && expected[i] == actual[i] {
+			continue
+		}
+
+		start := int64(i) * chunkSize
+		end := min(start+chunkSize, extent) - 1
+
+		// 与上一个不一致的分块相邻时合并
+		if n := len(ranges); n > 0 && ranges[n-1].end+1 == start {
+			ranges[n-1].end = end
+			continue
+		}
+		ranges = append(ranges, byteRange{start: start, end: end})
+	}
+
+	return ranges
+}
+
+// formatRanges 将字节范围列表格式化为以逗号分隔的字符串
+func formatRanges(ranges []byteRange) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,73 @@
+package check
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestMismatchedRanges 测试不一致分块到字节范围的转换
+func TestMismatchedRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+		actual   []string
+		extent   int64
+		want     []byteRange
+	}{
+		{
+			name:     "全部一致",
+			expected: []string{"a", "b", "c"},
+			actual:   []string{"a", "b", "c"},
+			extent:   250,
+			want:     nil,
+		},
+		{
+			name:     "相邻分块合并",
+			expected: []string{"a", "b", "c", "d"},
+			actual:   []string{"a", "x", "y", "d"},
+			extent:   400,
+			want:     []byteRange{{start: 100, end: 299}},
+		},
+		{
+			name:     "不相邻分块分别报告",
+			expected: []string{"a", "b", "c"},
+			actual:   []string{"x", "b", "y"},
+			extent:   250,
+			want:     []byteRange{{start: 0, end: 99}, {start: 200, end: 249}},
+		},
+		{
+			name:     "文件被截断",
+			expected: []string{"a", "b", "c"},
+			actual:   []string{"a", "b", "x"},
+			extent:   300,
+			want:     []byteRange{{start: 200, end: 299}},
+		},
+		{
+			name:     "文件变短缺少分块",
+			expected: []string{"a", "b", "c"},
+			actual:   []string{"a", "x"},
+			extent:   250,
+			want:     []byteRange{{start: 100, end: 249}},
+		},
+		{
+			name:     "文件变长多出分块",
+			expected: []string{"a"},
+			actual:   []string{"a", "b", "c"},
+			extent:   210,
+			want:     []byteRange{{start: 100, end: 209}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mismatchedRanges(tt.expected, tt.actual, 100, tt.extent)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mismatchedRanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := formatRanges([]byteRange{{start: 0, end: 99}, {start: 200, end: 249}}); got != "0-99, 200-249" {
+		t.Errorf("formatRanges() = %q", got)
+	}
+}
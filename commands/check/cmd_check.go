@@ -3,15 +3,32 @@
 package check
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/hashcache"
+	"gitee.com/MM-Q/fck/commands/internal/merkle"
+	"gitee.com/MM-Q/fck/commands/internal/output"
+	"gitee.com/MM-Q/fck/commands/internal/ratelimit"
+	"gitee.com/MM-Q/fck/commands/internal/signature"
 	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
 // CheckCmdMain 是 check 命令的主函数
-func CheckCmdMain(cl *colorlib.ColorLib) error {
+//
+// 参数:
+//   - ctx: 上下文, 取消后停止校验并返回 exitcode.ErrInterrupted
+//   - cl: 颜色库对象
+//
+// 返回:
+//   - error: 错误信息
+func CheckCmdMain(ctx context.Context, cl *colorlib.ColorLib) error {
 	// 获取校验文件路径
 	checkFile := checkCmdFile.Get()
 	if checkFile == "" {
@@ -21,18 +38,78 @@ func CheckCmdMain(cl *colorlib.ColorLib) error {
 		}
 	}
 
+	// 结构化输出: 标准输出仅保留记录, 提示信息改为输出到标准错误
+	structured := output.IsStructured(checkCmdOutput.Get())
+	if structured {
+		cl = colorlib.NewColorLibWithWriter(os.Stderr)
+	}
+
 	// 设置颜色输出
 	cl.SetColor(checkCmdColor.Get())
 
+	// 仅在显式指定时使用用户选择的哈希算法, 为空时自动推断
+	hashType := strings.ToLower(checkCmdType.Get())
+
+	// 检查并发数和读取带宽限制
+	if checkCmdJobs.Get() < 0 {
+		return exitcode.Usagef("--jobs 不能为负数: %d", checkCmdJobs.Get())
+	}
+	if checkCmdBwLimit.Get() != "" {
+		rate, err := ratelimit.ParseRate(checkCmdBwLimit.Get())
+		if err != nil {
+			return exitcode.New(exitcode.Usage, err)
+		}
+		digest.SetReadLimiter(ratelimit.New(rate))
+		defer digest.SetReadLimiter(nil)
+	}
+
+	// 检查报告参数
+	if checkCmdReportFormat.IsSet() && checkCmdReport.Get() == "" {
+		return exitcode.Usagef("--report-format 需要与 --report 一起使用")
+	}
+
+	// 检查刷新参数
+	if checkCmdYes.Get() && !checkCmdRefresh.Get() {
+		return exitcode.Usagef("--yes 需要与 --refresh 一起使用")
+	}
+
+	// 递归校验根目录下的所有校验文件
+	if checkCmdRecursive.Get() {
+		return recursiveCheck(ctx, cl, hashType, structured)
+	}
+	if checkCmdName.IsSet() {
+		return exitcode.Usagef("--name 需要与 --recursive 一起使用")
+	}
+	if checkCmdRefresh.Get() && checkCmdArchive.Get() != "" {
+		return exitcode.Usagef("--refresh 不能与 --archive 同时使用")
+	}
+	if checkCmdRefresh.Get() && checkCmdFailFast.Get() {
+		return exitcode.Usagef("--refresh 需要校验全部文件, 不能与 --fail-fast 同时使用")
+	}
+	if checkCmdResume.Get() && (checkCmdRefresh.Get() || checkCmdArchive.Get() != "") {
+		return exitcode.Usagef("--resume 不能与 --refresh 或 --archive 同时使用")
+	}
+
 	// 检查校验文件是否存在
 	if _, err := os.Stat(checkFile); err != nil {
 		return fmt.Errorf("指定的校验文件不存在: %s, 请确认文件路径是否正确", checkFile)
 	}
 
+	// 验证校验文件签名
+	var content []byte
+	if checkCmdVerify.Get() != "" {
+		var err error
+		if content, err = verifyChecksumFile(cl, checkFile, checkCmdVerify.Get()); err != nil {
+			return err
+		}
+	}
+
 	cl.Blue("正在校验完整性...")
 
 	// 创建解析器
 	parser := newHashFileParser(cl)
+	parser.hashType = hashType
+	parser.content = content
 
 	// 获取用户指定的基准目录
 	userBaseDir := checkCmdBaseDir.Get()
@@ -43,13 +120,164 @@ func CheckCmdMain(cl *colorlib.ColorLib) error {
 		return fmt.Errorf("解析校验文件失败: %v", err)
 	}
 
+	// 严格模式按文件校验, 遍历磁盘上的基准目录
+	if checkCmdStrict.Get() && (parser.header.IsTreeMode() || checkCmdArchive.Get() != "") {
+		return exitcode.Usagef("--strict 不能用于目录树模式的校验文件, 也不能与 --archive 同时使用")
+	}
+
+	// 校验指定压缩包的内容
+	if checkCmdArchive.Get() != "" {
+		if parser.header.IsTreeMode() {
+			return exitcode.Usagef("目录树模式的校验文件不能与 --archive 同时使用")
+		}
+		if err := useArchive(hashMap, checkCmdArchive.Get()); err != nil {
+			return err
+		}
+	}
+
+	// 启用 HMAC 模式: 密钥与校验文件头必须一致
+	keyed := parser.header.Keyed
+	if keyed && checkCmdHMACKey.Get() == "" {
+		return fmt.Errorf("校验文件 %s 使用 HMAC 密钥模式生成, 请通过 --hmac-key-file 指定生成时使用的密钥文件", checkFile)
+	}
+	if !keyed && checkCmdHMACKey.Get() != "" {
+		return fmt.Errorf("校验文件 %s 不是 HMAC 密钥模式生成的, 不能使用 --hmac-key-file", checkFile)
+	}
+	if keyed {
+		if !digest.IsHMACSupported(hashFunc) {
+			return exitcode.Usagef("HMAC 模式不支持非加密哈希算法: %s", hashFunc)
+		}
+		key, err := digest.LoadHMACKey(checkCmdHMACKey.Get())
+		if err != nil {
+			return err
+		}
+		digest.SetHMACKey(key)
+		defer digest.SetHMACKey(nil)
+	}
+
 	// 创建校验器
 	checker := newFileChecker(cl, hashFunc)
+	checker.ctx = ctx
+	if checkCmdJobs.Get() > 0 {
+		checker.maxWorkers = checkCmdJobs.Get()
+	}
+	if err := configureChecker(checker, checkFile, parser.header, hashFunc); err != nil {
+		return err
+	}
+	if checkCmdStrict.Get() {
+		checker.strictDir = strictBaseDir(parser.header, userBaseDir)
+		checker.strictSkip = []string{checkFile, signature.SignatureFileName(checkFile)}
+		if checkCmdReport.Get() != "" {
+			checker.strictSkip = append(checker.strictSkip, checkCmdReport.Get())
+		}
+	}
+
+	// 断点续校验: 读取上次中断时保存的进度
+	if checkCmdResume.Get() {
+		if checker.chunkSize == 0 {
+			return exitcode.Usagef("--resume 需要记录了分块哈希值的校验文件(hash --chunk-size)")
+		}
+		resume, err := loadResumeState(resumeFileName(checkFile), checker.chunkSize)
+		if err != nil {
+			return err
+		}
+		checker.resume = resume
+		checker.strictSkip = append(checker.strictSkip, resume.path)
+	}
 
-	// 执行文件校验
-	if err := checker.checkFiles(hashMap); err != nil {
-		return fmt.Errorf("文件校验失败: %v", err)
+	// 刷新模式: 记录需要写回校验文件的变更
+	if checkCmdRefresh.Get() {
+		if content == nil {
+			if content, err = os.ReadFile(checkFile); err != nil {
+				return fmt.Errorf("读取校验文件失败: %v", err)
+			}
+		}
+		checker.refresh = newManifestRefresh(checkFile, content, parser.header, hashMap, strictBaseDir(parser.header, userBaseDir))
+	}
+
+	// 生成校验报告
+	if checkCmdReport.Get() != "" {
+		checker.report = newCheckReport(checkCmdReport.Get(), checkCmdReportFormat.Get(), checkFile, hashFunc)
+	}
+
+	// 创建结构化输出写入器
+	if structured {
+		records, err := output.NewWriter(os.Stdout, checkCmdOutput.Get(), checkRecordColumns)
+		if err != nil {
+			return err
+		}
+		checker.records = records
 	}
 
+	// 启用哈希缓存
+	if checkCmdCache.Get() && keyed {
+		cl.PrintWarn("HMAC 模式下不使用哈希缓存")
+	} else if checkCmdCache.Get() {
+		cache, err := hashcache.OpenDefault()
+		if err != nil {
+			cl.PrintWarnf("哈希缓存不可用, 将直接计算哈希值: %v\n", err)
+		} else {
+			defer func() { _ = cache.Close() }()
+			checker.cache = cache
+		}
+	}
+
+	// 快速失败: 发现未通过的文件时取消其余校验
+	if checkCmdFailFast.Get() {
+		defer checker.withFailFast()()
+	}
+
+	// 显示校验进度
+	if checkCmdProgress.Get() {
+		checker.progress = startCheckProgress(hashMap)
+		defer checker.progress.stop()
+	}
+
+	// 执行文件校验, 未全部通过时返回对应退出码的错误
+	err = checker.checkFiles(hashMap)
+	if checker.resume != nil {
+		checker.finishResume()
+	}
+	var result *exitcode.Error
+	if checker.refresh == nil || err != nil && !(errors.As(err, &result) && result.Err == nil) {
+		return err
+	}
+
+	// 刷新校验文件, 刷新后仅无法校验的文件(记录保持不变)仍返回对应退出码
+	refreshed, refreshErr := checker.refresh.run(checker, checkCmdYes.Get(), os.Stdin)
+	if refreshErr != nil {
+		return refreshErr
+	}
+	if refreshed && exitcode.Code(err) != exitcode.Partial {
+		return nil
+	}
+	return err
+}
+
+// configureChecker 按校验文件头配置校验器
+//
+// 参数:
+//   - checker: 校验器
+//   - checkFile: 校验文件路径(目录树模式下校验文件及其签名文件不参与计算)
+//   - header: 校验文件头信息
+//   - hashFunc: 哈希算法
+//
+// 返回:
+//   - error: 目录树选项无效时返回错误
+func configureChecker(checker *fileChecker, checkFile string, header *types.ChecksumHeader, hashFunc string) error {
+	checker.chunkSize = header.ChunkSize
+	checker.metadata = header.Metadata
+	checker.symlinks = header.Symlinks
+
+	// 目录树模式: 按文件头中的选项计算目录 Merkle 根摘要
+	if header.IsTreeMode() {
+		opts, err := merkle.ParseOptions(hashFunc, header.Tree)
+		if err != nil {
+			return fmt.Errorf("解析校验文件失败: %v", err)
+		}
+		opts.Workers = checkCmdJobs.Get()
+		opts.Exclude = []string{checkFile, signature.SignatureFileName(checkFile)}
+		checker.tree = &opts
+	}
 	return nil
 }
@@ -1,12 +1,17 @@
 package check
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
 )
 
 func TestCheckCmdMain_Integration(t *testing.T) {
@@ -26,7 +31,7 @@ func TestCheckCmdMain_Integration(t *testing.T) {
 	// 创建有效的校验文件
 	validCheckFile := filepath.Join(tempDir, "valid.hash")
 	validContent := `#md5#2024-01-01 10:00:00
-c34652066a18513105ac1ab96fcbef8e ` + testFile
+554a4a6903bc4c5ecaadd2ff5df6c536 ` + testFile
 	err = os.WriteFile(validCheckFile, []byte(validContent), 0644)
 	if err != nil {
 		t.Fatalf("创建校验文件失败: %v", err)
@@ -77,7 +82,7 @@ func TestCheckCmdMain_ErrorCases(t *testing.T) {
 			setupFile: func() string {
 				tempDir := t.TempDir()
 				invalidFile := filepath.Join(tempDir, "invalid.hash")
-				content := "invalid header\nc34652066a18513105ac1ab96fcbef8e test.txt"
+				content := "invalid header\n554a4a6903bc4c5ecaadd2ff5df6c536 test.txt"
 				_ = os.WriteFile(invalidFile, []byte(content), 0644)
 				return invalidFile
 			},
@@ -129,7 +134,7 @@ func TestParseFile_BackwardCompatibility(t *testing.T) {
 	// 创建校验文件
 	checkFile := filepath.Join(tempDir, "compat.hash")
 	checkContent := `#md5#2024-01-01 10:00:00
-c34652066a18513105ac1ab96fcbef8e ` + testFile
+554a4a6903bc4c5ecaadd2ff5df6c536 ` + testFile
 	err = os.WriteFile(checkFile, []byte(checkContent), 0644)
 	if err != nil {
 		t.Fatalf("创建校验文件失败: %v", err)
@@ -187,3 +192,165 @@ c34652066a18513105ac1ab96fcbef8e ` + testFile
 		})
 	}
 }
+
+// TestCheckCmdMain_JobsAndBwLimit 测试并发数和读取带宽限制
+func TestCheckCmdMain_JobsAndBwLimit(t *testing.T) {
+	cl := colorlib.New()
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("test content for main"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	checkFile := filepath.Join(tempDir, "valid.hash")
+	if err := os.WriteFile(checkFile, []byte("554a4a6903bc4c5ecaadd2ff5df6c536  "+testFile+"\n"), 0644); err != nil {
+		t.Fatalf("创建校验文件失败: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		jobs        string
+		bwlimit     string
+		expectError bool
+	}{
+		{name: "指定并发数和限速", jobs: "2", bwlimit: "10M/s", expectError: false},
+		{name: "负数并发数", jobs: "-1", expectError: true},
+		{name: "无效的限速", bwlimit: "10X/s", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkCmd = InitCheckCmd()
+			_ = checkCmdFile.Set(checkFile)
+			if tt.jobs != "" {
+				_ = checkCmdJobs.Set(tt.jobs)
+			}
+			if tt.bwlimit != "" {
+				_ = checkCmdBwLimit.Set(tt.bwlimit)
+			}
+
+			err := CheckCmdMain(context.Background(), cl)
+			if tt.expectError && err == nil {
+				t.Error("期望错误但没有发生错误")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("不期望错误但发生了错误: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckCmdMain_HMACKey(t *testing.T) {
+	cl := colorlib.New()
+
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("a"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	keyFile := filepath.Join(tempDir, "hmac.key")
+	if err := os.WriteFile(keyFile, []byte("secret"), 0600); err != nil {
+		t.Fatalf("创建密钥文件失败: %v", err)
+	}
+	otherKeyFile := filepath.Join(tempDir, "other.key")
+	if err := os.WriteFile(otherKeyFile, []byte("other"), 0600); err != nil {
+		t.Fatalf("创建密钥文件失败: %v", err)
+	}
+
+	// HMAC-SHA256("secret", "a")
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("a"))
+	keyedFile := filepath.Join(tempDir, "keyed.hash")
+	keyedContent := "#hmac-sha256#2024-01-01 10:00:00#LOCAL#" + tempDir + "\n" + hex.EncodeToString(mac.Sum(nil)) + "\t\"" + testFile + "\"\n"
+	if err := os.WriteFile(keyedFile, []byte(keyedContent), 0644); err != nil {
+		t.Fatalf("创建校验文件失败: %v", err)
+	}
+	plainFile := filepath.Join(tempDir, "plain.hash")
+	if err := os.WriteFile(plainFile, []byte("0cc175b9c0f1b6a831c399e269772661  "+testFile+"\n"), 0644); err != nil {
+		t.Fatalf("创建校验文件失败: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		checkFile   string
+		keyFile     string
+		expectError bool
+	}{
+		{name: "HMAC校验文件使用正确密钥", checkFile: keyedFile, keyFile: keyFile},
+		{name: "HMAC校验文件缺少密钥", checkFile: keyedFile, expectError: true},
+		{name: "普通校验文件指定密钥", checkFile: plainFile, keyFile: keyFile, expectError: true},
+		{name: "密钥文件不存在", checkFile: keyedFile, keyFile: filepath.Join(tempDir, "missing.key"), expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkCmd = InitCheckCmd()
+			_ = checkCmdFile.Set(tt.checkFile)
+			if tt.keyFile != "" {
+				_ = checkCmdHMACKey.Set(tt.keyFile)
+			}
+
+			err := CheckCmdMain(context.Background(), cl)
+			if tt.expectError && err == nil {
+				t.Error("期望错误但没有发生错误")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("不期望错误但发生了错误: %v", err)
+			}
+		})
+	}
+
+	// 校验结束后恢复为普通哈希
+	if digest.HMACEnabled() {
+		t.Error("校验结束后应清除 HMAC 密钥")
+	}
+
+	// 错误的密钥导致校验不通过
+	parser := newHashFileParser(cl)
+	hashMap, hashType, err := parser.parseFile(keyedFile, "")
+	if err != nil {
+		t.Fatalf("解析校验文件失败: %v", err)
+	}
+	if !parser.header.Keyed || hashType != "sha256" {
+		t.Fatalf("文件头解析不正确: %+v", parser.header)
+	}
+	digest.SetHMACKey([]byte("other"))
+	defer digest.SetHMACKey(nil)
+	for _, entry := range hashMap {
+		sum, err := digest.Checksum(entry.RealPath, hashType)
+		if err != nil {
+			t.Fatalf("计算哈希失败: %v", err)
+		}
+		if sum == entry.Hash {
+			t.Error("使用错误密钥时 HMAC 值不应一致")
+		}
+	}
+}
+
+// TestCheckTypeValidator 测试 --type 标志的哈希算法验证
+func TestCheckTypeValidator(t *testing.T) {
+	tests := []struct {
+		value   any
+		wantErr bool
+	}{
+		{"", false},
+		{"sha256", false},
+		{"BLAKE3", false},
+		{"foo", true},
+		{"md5,sha1", true},
+		{1, true},
+	}
+
+	for _, tt := range tests {
+		err := checkTypeValidator{}.Validate(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%v) 错误 = %v, 期望错误: %v", tt.value, err, tt.wantErr)
+		}
+	}
+
+	// 未指定 --type 时默认值为空, 由校验文件自动推断算法
+	InitCheckCmd()
+	if got := checkCmdType.Get(); got != "" {
+		t.Errorf("--type 默认值 = %q, 期望为空", got)
+	}
+}
@@ -4,27 +4,85 @@ package check
 
 import (
 	"flag"
+	"fmt"
+	"strings"
 
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/output"
+	"gitee.com/MM-Q/fck/commands/internal/types"
 	"gitee.com/MM-Q/qflag"
 )
 
 var (
 	// fck check 子命令
-	checkCmd        *qflag.Cmd
-	checkCmdFile    *qflag.StringFlag // file 标志
-	checkCmdBaseDir *qflag.StringFlag // base-dir 标志
-	checkCmdQuiet   *qflag.BoolFlag   // quiet 标志
-	checkCmdColor   *qflag.BoolFlag   // color 标志
+	checkCmd             *qflag.Cmd
+	checkCmdFile         *qflag.StringFlag // file 标志
+	checkCmdBaseDir      *qflag.StringFlag // base-dir 标志
+	checkCmdQuiet        *qflag.BoolFlag   // quiet 标志
+	checkCmdColor        *qflag.BoolFlag   // color 标志
+	checkCmdType         *qflag.StringFlag // type 标志
+	checkCmdCache        *qflag.BoolFlag   // cache 标志
+	checkCmdOutput       *qflag.EnumFlag   // output 标志
+	checkCmdJobs         *qflag.IntFlag    // jobs 标志
+	checkCmdBwLimit      *qflag.StringFlag // bwlimit 标志
+	checkCmdVerify       *qflag.StringFlag // verify-key 标志
+	checkCmdHMACKey      *qflag.StringFlag // hmac-key-file 标志
+	checkCmdArchive      *qflag.StringFlag // archive 标志
+	checkCmdStrict       *qflag.BoolFlag   // strict 标志
+	checkCmdReport       *qflag.StringFlag // report 标志
+	checkCmdReportFormat *qflag.EnumFlag   // report-format 标志
+	checkCmdRecursive    *qflag.BoolFlag   // recursive 标志
+	checkCmdName         *qflag.StringFlag // name 标志
+	checkCmdRefresh      *qflag.BoolFlag   // refresh 标志
+	checkCmdYes          *qflag.BoolFlag   // yes 标志
+	checkCmdProgress     *qflag.BoolFlag   // progress 标志
+	checkCmdFailFast     *qflag.BoolFlag   // fail-fast 标志
+	checkCmdResume       *qflag.BoolFlag   // resume 标志
 )
 
+// checkTypeValidator 哈希算法验证器
+type checkTypeValidator struct{}
+
+// Validate 检查指定的哈希算法是否受支持, 为空时表示自动推断
+func (checkTypeValidator) Validate(value any) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("哈希算法必须为字符串")
+	}
+	if s != "" && !digest.IsAlgorithmSupported(s) {
+		return fmt.Errorf("不支持的哈希算法: %s", s)
+	}
+	return nil
+}
+
 func InitCheckCmd() *qflag.Cmd {
 	// fck check 子命令
-	checkCmd = qflag.NewCmd("check", "c", flag.ExitOnError)
+	checkCmd = qflag.NewCmd("check", "c", flag.ContinueOnError)
 
 	checkCmdCfg := qflag.CmdConfig{
 		UseChinese: true,
 		Desc:       "文件校验工具, 对比指定目录A和目录B的文件差异, 并支持指定校验类型",
-		Notes:      []string{"校验文件必须包含有效的头信息", "校验时会自动跳过空行和注释行(以#开头的行)"},
+		Notes: []string{
+			"自动识别校验文件格式: fck原生格式(带头信息)、GNU coreutils格式(sha256sum等)和BSD标签格式",
+			"无头信息的GNU格式根据哈希值长度推断算法, 长度相同的算法(如sha256/sha3-256/blake3)需通过--type指定",
+			"校验时会自动跳过空行和注释行(以#开头的行)",
+			"启用--cache后, 设备号/inode/大小/修改时间均未变化的文件直接使用hash命令缓存的哈希值, 不会重新读取文件内容",
+			"指定--verify-key后, 先验证校验文件的签名再执行校验, 校验文件被篡改、缺少签名文件或签名密钥与公钥不一致时直接报错",
+			"文件头算法为 hmac-<算法> 的校验文件必须通过--hmac-key-file 提供生成时使用的同一密钥文件, HMAC 模式下不使用哈希缓存",
+			"校验文件记录了分块哈希值(hash --chunk-size)时, 哈希不匹配的文件会报告不一致的字节范围(start-end, 包含两端)",
+			"--resume 逐块校验记录了分块哈希值的文件, 并将每个文件从开头起连续通过校验的分块数记录到 <校验文件>.resume; 校验被中断(Ctrl+C)或因 --fail-fast 提前停止时保存进度(校验期间每10秒也会保存一次), 再次使用 --resume 校验时, 大小和修改时间均未变化的文件跳过已通过校验的分块, 从中断处继续读取; 全部记录校验完成后删除状态文件; 续校验不计算整体哈希值, 全部分块一致即视为通过, 不一致时实际哈希值为空; 不能与 --recursive/--refresh/--archive 同时使用",
+			"校验文件记录了元数据(hash --with-metadata)时, 会逐个比较权限位、所有者、软链接目标和扩展属性, 报告具体变化的属性",
+			"校验文件记录了软链接策略(hash --symlinks)时按相同策略校验: link 比较链接目标文本, skip 下已变为软链接的文件报告错误, follow 或未记录时跟随软链接",
+			"路径为 <压缩包>!/<条目路径> 的记录(hash --archive 生成)直接流式读取压缩包校验, 无需解压; --archive 将校验文件中的所有路径视为指定压缩包内的条目, 可直接用供应商清单校验压缩包内容",
+			"--strict 遍历基准目录(--base-dir、LOCAL 模式记录的基准路径或当前目录), 将校验文件中没有记录的文件(包括隐藏文件和子目录中的文件)报告为 unexpected, 校验文件本身及其签名文件除外; 校验文件记录的软链接策略为 skip 时不报告软链接(未记录策略时按 follow 处理)",
+			"--report 将每个文件的校验状态、期望与实际哈希值、大小和耗时连同汇总信息写入报告文件, 与 --output 互不影响; junit 格式中每个文件为一个测试用例, 不匹配/不存在/未记录为 failure, 无法校验为 error",
+			"--recursive 查找根目录(位置参数, 默认为当前目录)下所有文件名匹配 --name 的校验文件, 每个校验文件的相对路径以其所在目录为基准解析(LOCAL 模式仍使用记录的基准路径), 所有记录共用 --jobs 指定的工作协程池校验, 最后输出每个校验文件的统计和全局统计; 不能与 --file/--base-dir/--archive/--strict/--hmac-key-file 同时使用",
+			"--refresh 在校验完成后将不匹配文件的新哈希值写回校验文件并删除不存在的文件的记录, 与 --strict 同时使用时追加未记录的文件; 文件头、注释和其余记录的顺序保持不变, 记录了分块哈希值或元数据时一并重新生成, 确认后(或指定 --yes)原子地替换校验文件, 已签名的校验文件需要重新签名",
+			"--progress 在标准错误中显示已校验的文件数和字节数、读取速率及预计剩余时间; 字节数按校验开始时的文件大小统计, 压缩包和目录树模式下仅供参考",
+			"--fail-fast 发现第一个未通过(不匹配、不存在、无法校验或未记录)的文件后不再开始新的校验, 已在校验中的文件完成后立即输出结果并退出, 适用于只需要判断是否全部通过的场景; 不能与 --refresh 同时使用",
+			"--bwlimit 限制所有并发协程读取文件的总速率, 单位 K/M/G/T 按1024进制计算, 如 50M/s",
+			"--output 为 json/ndjson/csv 时标准输出仅包含结构化记录(状态为 ok/mismatch/missing/error, --strict 下还包括 unexpected), json/ndjson 额外输出汇总信息",
+		},
 	}
 
 	checkCmd.ApplyConfig(checkCmdCfg)
@@ -33,6 +91,32 @@ func InitCheckCmd() *qflag.Cmd {
 	checkCmdBaseDir = checkCmd.String("base-dir", "b", "", "手动指定校验基准目录(覆盖自动检测)")
 	checkCmdQuiet = checkCmd.Bool("quiet", "q", false, "是否静默模式, 不输出校验通过的信息避免噪音")
 	checkCmdColor = checkCmd.Bool("color", "c", false, "是否启用颜色输出")
+	checkCmdType = checkCmd.String("type", "t", "", fmt.Sprintf("指定无头信息校验文件的哈希算法(未指定时根据BSD标签或哈希值长度自动推断)，支持 %s", strings.Join(digest.SupportedAlgorithms, "、")))
+	checkCmdType.SetValidator(checkTypeValidator{})
+	checkCmdCache = checkCmd.Bool("cache", "", false, "使用哈希缓存加速校验(默认关闭, 始终读取文件内容)")
+	checkCmdJobs = checkCmd.Int("jobs", "j", 0, "指定并发校验的协程数(默认为逻辑处理器数量), 机械硬盘或网络存储建议调低")
+	checkCmdBwLimit = checkCmd.String("bwlimit", "", "", "限制读取文件的总带宽(如 50M/s、512K), 默认不限速")
+	checkCmdVerify = checkCmd.String("verify-key", "", "", "使用指定的 Ed25519 公钥验证校验文件的签名(<校验文件>.sig), 未签名或签名不匹配时拒绝校验")
+	checkCmdHMACKey = checkCmd.String("hmac-key-file", "", "", "指定 HMAC 密钥文件, 校验 hash --hmac-key-file 生成的校验文件")
+	checkCmdArchive = checkCmd.String("archive", "", "", "将校验文件中的路径视为指定压缩包(zip/tar/tgz/tar.gz/tar.bz2/gz/bz2/zlib)内的条目, 无需解压直接校验包内文件")
+	checkCmdStrict = checkCmd.Bool("strict", "", false, "严格模式, 额外报告基准目录中存在但校验文件没有记录的文件")
+	checkCmdReport = checkCmd.String("report", "", "", "将校验结果写入指定的报告文件, 格式由 --report-format 指定")
+	checkCmdReportFormat = checkCmd.Enum("report-format", "", reportFormatJSON, "指定校验报告的格式，支持以下选项：\n"+
+		"\t\t\t\t[json] - JSON对象, 包含逐文件记录(状态、哈希值、大小、耗时)和汇总信息\n"+
+		"\t\t\t\t[junit] - JUnit XML, 可被 CI 作为测试结果解析\n"+
+		"\t\t\t\t[html] - 独立的 HTML 页面", supportedReportFormats)
+	checkCmdRecursive = checkCmd.Bool("recursive", "r", false, "递归查找并校验根目录下的所有校验文件")
+	checkCmdName = checkCmd.String("name", "n", types.OutputFileName, "递归模式下校验文件的文件名或通配符(如 *.sha256)")
+	checkCmdRefresh = checkCmd.Bool("refresh", "", false, "校验完成后用新的哈希值刷新校验文件(更新不匹配的记录, 删除不存在的文件)")
+	checkCmdYes = checkCmd.Bool("yes", "y", false, "与 --refresh 一起使用, 不经确认直接更新校验文件")
+	checkCmdProgress = checkCmd.Bool("progress", "p", false, "在标准错误中显示校验进度(文件数、字节数、速率和预计剩余时间), 推荐在校验大量数据时使用")
+	checkCmdFailFast = checkCmd.Bool("fail-fast", "", false, "发现第一个未通过的文件后立即停止校验")
+	checkCmdResume = checkCmd.Bool("resume", "", false, "断点续校验, 跳过上次中断前已通过校验的分块(需要 hash --chunk-size 生成的校验文件)")
+	checkCmdOutput = checkCmd.Enum("output", "", output.FormatText, "指定校验结果的输出格式，支持以下选项：\n"+
+		"\t\t\t\t[text] - 文本格式\n"+
+		"\t\t\t\t[json] - JSON对象, 包含逐文件记录(path/status/expected/actual/error)和汇总信息\n"+
+		"\t\t\t\t[ndjson] - 每行一个JSON记录, 最后一行为汇总信息\n"+
+		"\t\t\t\t[csv] - 带表头的CSV记录", output.SupportedFormats)
 
 	// 创建并返回一个命令对象
 	return checkCmd
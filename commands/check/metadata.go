@@ -0,0 +1,49 @@
+// Package check 实现了文件元数据的校验。
+// 该文件负责校验 hash --with-metadata 生成的校验文件: 软链接按链接目标校验, 并逐个比较权限位、所有者、软链接目标和扩展属性, 报告具体发生变化的属性。
+package check
+
+import (
+	"fmt"
+
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/metadata"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// checkLink 以软链接目标文本作为内容计算哈希值
+//
+// 参数:
+//   - entry: 校验文件中的记录
+//   - result: 校验结果
+func (c *fileChecker) checkLink(entry types.VirtualHashEntry, result *checkResult) {
+	sums, err := digest.ChecksumLink(entry.RealPath, []string{c.hashType})
+	if err != nil {
+		result.err = fmt.Errorf("计算文件哈希失败: %v", err)
+		return
+	}
+	result.actualHash = sums[0]
+}
+
+// checkMetadata 比较记录的元数据与文件当前的元数据
+//
+// 参数:
+//   - entry: 校验文件中的记录(包含编码后的元数据)
+//   - result: 校验结果
+//
+// 注意:
+//   - 仅比较校验文件头中记录的字段
+func (c *fileChecker) checkMetadata(entry types.VirtualHashEntry, result *checkResult) {
+	expected, err := metadata.Parse(entry.Metadata)
+	if err != nil {
+		result.err = err
+		return
+	}
+
+	actual, err := metadata.Collect(entry.RealPath, c.metadata)
+	if err != nil {
+		result.err = fmt.Errorf("获取文件元数据失败: %v", err)
+		return
+	}
+
+	result.drift = metadata.Diff(expected, actual)
+}
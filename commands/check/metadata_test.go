@@ -0,0 +1,100 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/metadata"
+	"gitee.com/MM-Q/fck/commands/internal/output"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+func TestFileChecker_CheckMetadata(t *testing.T) {
+	// 初始化命令标志
+	InitCheckCmd()
+
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "a.txt")
+	if err := os.WriteFile(filePath, []byte("a"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	if err := os.Chmod(filePath, 0644); err != nil {
+		t.Fatalf("修改文件权限失败: %v", err)
+	}
+	linkPath := filepath.Join(tempDir, "link")
+	if err := os.Symlink("a.txt", linkPath); err != nil {
+		t.Skipf("当前平台不支持创建软链接: %v", err)
+	}
+	samePath := filepath.Join(tempDir, "same.txt")
+	if err := os.WriteFile(samePath, []byte("a"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	// 记录当前的元数据
+	fields := metadata.DefaultFields
+	hashMap := make(types.VirtualHashMap)
+	for name, hash := range map[string]string{
+		"a.txt":    "0cc175b9c0f1b6a831c399e269772661", // md5("a")
+		"same.txt": "0cc175b9c0f1b6a831c399e269772661",
+		"link":     "a5e54d1fd7bb69a228ef0dcd2431367e", // md5("a.txt")
+	} {
+		realPath := filepath.Join(tempDir, name)
+		attrs, err := metadata.Collect(realPath, fields)
+		if err != nil {
+			t.Fatalf("获取元数据失败: %v", err)
+		}
+		hashMap[name] = types.VirtualHashEntry{RealPath: realPath, Hash: hash, Metadata: attrs.Encode()}
+	}
+
+	// 修改权限位并让软链接指向其他文件
+	if err := os.Chmod(filePath, 0755); err != nil {
+		t.Fatalf("修改文件权限失败: %v", err)
+	}
+	_ = os.Remove(linkPath)
+	if err := os.Symlink("same.txt", linkPath); err != nil {
+		t.Fatalf("创建软链接失败: %v", err)
+	}
+
+	var buf bytes.Buffer
+	records, err := output.NewWriter(&buf, output.FormatJSON, checkRecordColumns)
+	if err != nil {
+		t.Fatalf("创建写入器失败: %v", err)
+	}
+	checker := newFileChecker(colorlib.New(), "md5")
+	checker.records = records
+	checker.metadata = fields
+	if err := checker.checkFiles(hashMap); exitcode.Code(err) != exitcode.Failure {
+		t.Fatalf("checkFiles() 期望返回校验失败错误, 实际: %v", err)
+	}
+
+	var report struct {
+		Files []checkRecord `json:"files"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("解析JSON输出失败: %v\n%s", err, buf.String())
+	}
+
+	want := map[string]struct {
+		status string
+		drift  []string
+	}{
+		filePath: {status: statusMismatch, drift: []string{"mode: 0644 -> 0755"}},
+		samePath: {status: statusOK},
+		linkPath: {status: statusMismatch, drift: []string{"link: a.txt -> same.txt"}},
+	}
+	if len(report.Files) != len(want) {
+		t.Fatalf("期望 %d 条记录, 实际 %d 条", len(want), len(report.Files))
+	}
+	for _, r := range report.Files {
+		w := want[r.Path]
+		if r.Status != w.status || !reflect.DeepEqual(r.Drift, w.drift) {
+			t.Errorf("%s 状态 = %s %v, 期望 %s %v", r.Path, r.Status, r.Drift, w.status, w.drift)
+		}
+	}
+}
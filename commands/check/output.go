@@ -0,0 +1,117 @@
+// Package check 实现了校验结果的结构化输出。
+// 该文件负责将 check 命令的逐文件校验状态及汇总信息以 JSON、NDJSON 或 CSV 记录输出, 便于脚本和流水线解析。
+package check
+
+import (
+	"os"
+	"strings"
+)
+
+// 文件校验状态
+const (
+	statusOK         = "ok"         // 校验通过
+	statusMismatch   = "mismatch"   // 哈希不匹配
+	statusMissing    = "missing"    // 文件不存在
+	statusError      = "error"      // 其他错误
+	statusUnexpected = "unexpected" // 校验文件中没有记录(仅严格模式)
+)
+
+// checkRecordColumns CSV 表头
+var checkRecordColumns = []string{"path", "status", "expected", "actual", "error", "ranges", "drift"}
+
+// checkRecord 单个文件的校验记录
+type checkRecord struct {
+	Path     string   `json:"path"`             // 文件路径
+	Status   string   `json:"status"`           // 校验状态(ok/mismatch/missing/error/unexpected)
+	Expected string   `json:"expected"`         // 期望的哈希值
+	Actual   string   `json:"actual,omitempty"` // 实际的哈希值
+	Error    string   `json:"error,omitempty"`  // 错误信息
+	Ranges   []string `json:"ranges,omitempty"` // 不一致的字节范围(start-end, 仅记录了分块哈希值的文件)
+	Drift    []string `json:"drift,omitempty"`  // 发生变化的元数据(如 "mode: 0644 -> 0755", 仅记录了元数据的文件)
+}
+
+// Values 返回 CSV 字段值
+func (r checkRecord) Values() []string {
+	return []string{r.Path, r.Status, r.Expected, r.Actual, r.Error, strings.Join(r.Ranges, ";"), strings.Join(r.Drift, ";")}
+}
+
+// checkSummary 校验结果汇总
+type checkSummary struct {
+	Passed     int `json:"passed"`     // 校验通过的文件数
+	Mismatched int `json:"mismatched"` // 哈希不匹配的文件数
+	Missing    int `json:"missing"`    // 文件不存在的文件数
+	Errors     int `json:"errors"`     // 其他错误的文件数
+	Unexpected int `json:"unexpected"` // 校验文件中没有记录的文件数(仅严格模式)
+	Total      int `json:"total"`      // 校验文件中记录的总文件数
+}
+
+// add 按校验状态累加文件数
+//
+// 参数:
+//   - status: 校验状态
+func (s *checkSummary) add(status string) {
+	switch status {
+	case statusMissing:
+		s.Missing++
+	case statusError:
+		s.Errors++
+	case statusMismatch:
+		s.Mismatched++ // 哈希不匹配
+	case statusUnexpected:
+		s.Unexpected++ // 未记录的文件
+	default:
+		s.Passed++ // 校验通过
+	}
+}
+
+// resultStatus 判断校验结果的状态
+//
+// 参数:
+//   - result: 校验结果
+//
+// 返回:
+//   - string: 校验状态
+func resultStatus(result checkResult) string {
+	if result.unexpected {
+		return statusUnexpected
+	}
+	if result.err != nil {
+		// 检查是否是文件不存在错误
+		if os.IsNotExist(result.err) ||
+			strings.Contains(result.err.Error(), "不存在") ||
+			strings.Contains(result.err.Error(), "no such file") {
+			return statusMissing
+		}
+		return statusError
+	}
+
+	if result.actualHash != result.expectedHash || len(result.drift) > 0 {
+		return statusMismatch
+	}
+	return statusOK
+}
+
+// newCheckRecord 根据校验结果生成记录
+//
+// 参数:
+//   - result: 校验结果
+//   - status: 校验状态
+//
+// 返回:
+//   - checkRecord: 校验记录
+func newCheckRecord(result checkResult, status string) checkRecord {
+	record := checkRecord{
+		Path:     result.filePath,
+		Status:   status,
+		Expected: result.expectedHash,
+		Actual:   result.actualHash,
+	}
+	if result.err != nil {
+		record.Error = result.err.Error()
+	}
+	for _, r := range result.ranges {
+		record.Ranges = append(record.Ranges, r.String())
+	}
+	record.Drift = result.drift
+	return record
+}
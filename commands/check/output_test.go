@@ -0,0 +1,115 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/output"
+)
+
+func TestResultStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		result checkResult
+		want   string
+	}{
+		{"校验通过", checkResult{expectedHash: "a", actualHash: "a"}, statusOK},
+		{"哈希不匹配", checkResult{expectedHash: "a", actualHash: "b"}, statusMismatch},
+		{"文件不存在", checkResult{err: os.ErrNotExist}, statusMissing},
+		{"其他错误", checkResult{err: fmt.Errorf("计算哈希失败")}, statusError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resultStatus(tt.result); got != tt.want {
+				t.Errorf("resultStatus() = %s, 期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileChecker_CollectResultsRecords(t *testing.T) {
+	// 初始化命令标志
+	InitCheckCmd()
+
+	tests := []struct {
+		format string
+		check  func(t *testing.T, out string)
+	}{
+		{
+			format: output.FormatJSON,
+			check: func(t *testing.T, out string) {
+				var report struct {
+					Files   []checkRecord `json:"files"`
+					Summary checkSummary  `json:"summary"`
+				}
+				if err := json.Unmarshal([]byte(out), &report); err != nil {
+					t.Fatalf("解析JSON输出失败: %v\n%s", err, out)
+				}
+				if len(report.Files) != 4 {
+					t.Fatalf("期望 4 条记录, 实际 %d 条", len(report.Files))
+				}
+				want := checkSummary{Passed: 1, Mismatched: 1, Missing: 1, Errors: 1, Total: 4}
+				if report.Summary != want {
+					t.Errorf("汇总信息不正确: got %+v, want %+v", report.Summary, want)
+				}
+			},
+		},
+		{
+			format: output.FormatNDJSON,
+			check: func(t *testing.T, out string) {
+				lines := strings.Split(strings.TrimSpace(out), "\n")
+				if len(lines) != 5 {
+					t.Fatalf("期望 5 行(4 条记录 + 汇总), 实际 %d 行", len(lines))
+				}
+				var r checkRecord
+				if err := json.Unmarshal([]byte(lines[1]), &r); err != nil {
+					t.Fatalf("解析记录失败: %v", err)
+				}
+				if r.Path != "test2.txt" || r.Status != statusMismatch || r.Actual != "different_hash" {
+					t.Errorf("记录不正确: %+v", r)
+				}
+			},
+		},
+		{
+			format: output.FormatCSV,
+			check: func(t *testing.T, out string) {
+				lines := strings.Split(strings.TrimSpace(out), "\n")
+				if len(lines) != 5 || lines[0] != "path,status,expected,actual,error,ranges,drift" {
+					t.Errorf("CSV输出不正确:\n%s", out)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			records, err := output.NewWriter(&buf, tt.format, checkRecordColumns)
+			if err != nil {
+				t.Fatalf("创建写入器失败: %v", err)
+			}
+
+			checker := newFileChecker(colorlib.New(), "md5")
+			checker.records = records
+
+			results := make(chan checkResult, 4)
+			results <- checkResult{filePath: "test1.txt", expectedHash: "hash1", actualHash: "hash1"}
+			results <- checkResult{filePath: "test2.txt", expectedHash: "hash2", actualHash: "different_hash"}
+			results <- checkResult{filePath: "test3.txt", expectedHash: "hash3", err: os.ErrNotExist}
+			results <- checkResult{filePath: "test4.txt", expectedHash: "hash4", err: fmt.Errorf("计算哈希失败")}
+			close(results)
+
+			if err := checker.collectResults(results, 4); exitcode.Code(err) != exitcode.Partial {
+				t.Fatalf("collectResults期望返回部分失败错误, 实际: %v", err)
+			}
+			tt.check(t, buf.String())
+		})
+	}
+}
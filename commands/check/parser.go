@@ -3,20 +3,32 @@ package check
 
 import (
 	"bufio"
+	"bytes"
+	"cmp"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 
 	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/metadata"
 	"gitee.com/MM-Q/fck/commands/internal/types"
-	"gitee.com/MM-Q/go-kit/hash"
 )
 
+// headerPrefixRegex 匹配 fck 校验文件头的开头(#算法#), 其他以 # 开头的行视为注释
+var headerPrefixRegex = regexp.MustCompile(`^#[\w-]+#`)
+
 // hashFileParser 校验文件解析器
 type hashFileParser struct {
 	validator *hashLineValidator
 	cl        *colorlib.ColorLib
+	hashType  string                // 用户指定的哈希算法(仅用于无文件头的GNU/BSD格式, 为空时自动推断)
+	header    *types.ChecksumHeader // 最近一次解析得到的文件头信息
+	content   []byte                // 已验证签名的校验文件内容(不为nil时直接解析, 不再读取文件)
+	baseDir   string                // 便携模式下相对路径的基准目录(递归校验时为校验文件所在目录, 为空时为当前目录)
 }
 
 // newHashFileParser 创建校验文件解析器
@@ -38,28 +50,50 @@ func newHashFileParser(cl *colorlib.ColorLib) *hashFileParser {
 //   - string: 哈希算法类型
 //   - error: 错误信息
 func (p *hashFileParser) parseFile(checkFile string, userBaseDir string) (types.VirtualHashMap, string, error) {
-	// 检查文件是否存在
-	if _, err := os.Stat(checkFile); err != nil {
-		return nil, "", fmt.Errorf("指定的校验文件不存在: %s, 请确认文件路径是否正确", checkFile)
+	var reader io.Reader
+	if p.content != nil {
+		// 使用验证签名时读取的内容, 避免验证后校验文件被替换
+		reader = bytes.NewReader(p.content)
+	} else {
+		// 检查文件是否存在
+		if _, err := os.Stat(checkFile); err != nil {
+			return nil, "", fmt.Errorf("指定的校验文件不存在: %s, 请确认文件路径是否正确", checkFile)
+		}
+
+		// 打开文件
+		file, err := os.Open(checkFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("无法打开校验文件: %v", err)
+		}
+		defer func() { _ = file.Close() }()
+		reader = file
 	}
 
-	// 打开文件
-	file, err := os.Open(checkFile)
-	if err != nil {
-		return nil, "", fmt.Errorf("无法打开校验文件: %v", err)
+	scanner := bufio.NewScanner(reader)
+	if !scanner.Scan() {
+		return nil, "", fmt.Errorf("校验文件为空")
 	}
-	defer func() { _ = file.Close() }()
+	firstLine := scanner.Text()
 
-	scanner := bufio.NewScanner(file)
+	var (
+		headerInfo *types.ChecksumHeader
+		hashMap    types.VirtualHashMap
+		err        error
+	)
 
-	// 解析文件头
-	headerInfo, err := p.parseHeader(scanner)
-	if err != nil {
-		return nil, "", err
-	}
+	if headerPrefixRegex.MatchString(firstLine) {
+		// fck 原生格式: 解析文件头
+		headerInfo, err = p.parseHeader(firstLine)
+		if err != nil {
+			return nil, "", err
+		}
 
-	// 解析文件内容
-	hashMap, err := p.parseContent(scanner, headerInfo, userBaseDir)
+		// 解析文件内容
+		hashMap, err = p.parseContent(scanner, headerInfo, userBaseDir)
+	} else {
+		// 无文件头: 按 GNU coreutils 或 BSD 标签格式解析(开头的注释行和空行会被跳过)
+		headerInfo, hashMap, err = p.parseHeaderlessContent(firstLine, scanner, userBaseDir)
+	}
 	if err != nil {
 		return nil, "", err
 	}
@@ -68,26 +102,21 @@ func (p *hashFileParser) parseFile(checkFile string, userBaseDir string) (types.
 		return nil, "", fmt.Errorf("没有找到有效的校验文件内容")
 	}
 
+	p.header = headerInfo
 	return hashMap, headerInfo.HashType, nil
 }
 
 // parseHeader 解析校验文件头
 //
 // 参数:
-//   - scanner: 文件扫描器
+//   - headerLine: 文件头行
 //
 // 返回值:
 //   - *types.ChecksumHeader: 校验文件头信息
 //   - error: 错误信息
-func (p *hashFileParser) parseHeader(scanner *bufio.Scanner) (*types.ChecksumHeader, error) {
-	if !scanner.Scan() {
-		return nil, fmt.Errorf("校验文件为空")
-	}
-
-	headerLine := scanner.Text()
-
+func (p *hashFileParser) parseHeader(headerLine string) (*types.ChecksumHeader, error) {
 	// 尝试解析新格式: #hashType#timestamp#mode#basePath 或 #hashType#timestamp#mode
-	headerRegex := regexp.MustCompile(`^#(\w+)#([^#]+)(?:#([^#]+)(?:#(.+))?)?$`)
+	headerRegex := regexp.MustCompile(`^#([\w-]+)#([^#]+)(?:#([^#]+)(?:#(.+))?)?$`)
 	matches := headerRegex.FindStringSubmatch(headerLine)
 
 	if matches == nil {
@@ -95,24 +124,29 @@ func (p *hashFileParser) parseHeader(scanner *bufio.Scanner) (*types.ChecksumHea
 	}
 
 	headerInfo := &types.ChecksumHeader{
-		HashType:  matches[1], // hashType
-		Timestamp: matches[2], // timestamp
+		Timestamp: matches[2],              // timestamp
+		Format:    types.ChecksumFormatFck, // fck 原生格式
 	}
+	headerInfo.HashType, headerInfo.Keyed = types.ParseHashType(matches[1]) // hashType(可能带 hmac- 前缀)
 
 	// 检查哈希算法是否支持
 	if headerInfo.HashType == "" {
 		return nil, fmt.Errorf("校验文件头格式错误, 必须指定哈希算法")
 	}
 
-	if ok := hash.IsAlgorithmSupported(headerInfo.HashType); !ok {
+	if ok := digest.IsAlgorithmSupported(headerInfo.HashType); !ok {
 		return nil, fmt.Errorf("不支持的哈希算法: %s", headerInfo.HashType)
 	}
 
-	// 解析模式和基准路径
+	// 解析模式和基准路径(TREE模式下为树选项)
 	if len(matches) > 3 && matches[3] != "" {
 		headerInfo.Mode = matches[3]
 		if len(matches) > 4 && matches[4] != "" {
-			headerInfo.BasePath = matches[4]
+			if headerInfo.IsTreeMode() {
+				headerInfo.Tree = matches[4]
+			} else {
+				headerInfo.BasePath = matches[4]
+			}
 		}
 	} else {
 		// 兼容旧格式，默认为便携模式
@@ -134,13 +168,71 @@ func (p *hashFileParser) parseHeader(scanner *bufio.Scanner) (*types.ChecksumHea
 //   - error: 错误信息
 func (p *hashFileParser) parseContent(scanner *bufio.Scanner, headerInfo *types.ChecksumHeader, userBaseDir string) (types.VirtualHashMap, error) {
 	hashMap := make(types.VirtualHashMap)
-	lineNum := 1 // 从第二行开始计数（第一行是头部）
+	lineNum := 1      // 从第二行开始计数（第一行是头部）
+	lastPath := ""    // 上一条有效记录的文件路径(元数据和分块哈希记录属于该文件)
+	dropping := false // 是否正在丢弃无效的分块哈希记录
+	preamble := true  // 是否仍处于文件头之后的扩展记录中
 
 	for scanner.Scan() {
 		lineNum++
 		line := scanner.Text()
 
-		// 验证并解析行内容
+		// 分块大小、元数据字段和软链接策略记录紧跟在文件头之后
+		if preamble {
+			if chunkSize, ok := types.ParseChunkSizeLine(line); ok {
+				headerInfo.ChunkSize = chunkSize
+				continue
+			}
+			if fields, ok := types.ParseMetadataFieldsLine(line); ok {
+				parsed, err := metadata.ParseFields(fields)
+				if err != nil {
+					return nil, fmt.Errorf("第%d行: %v", lineNum, err)
+				}
+				headerInfo.Metadata = parsed
+				continue
+			}
+			if policy, ok := types.ParseSymlinksLine(line); ok {
+				if !types.IsValidSymlinkPolicy(policy) {
+					return nil, fmt.Errorf("第%d行: 不支持的软链接处理策略: %s", lineNum, policy)
+				}
+				headerInfo.Symlinks = policy
+				continue
+			}
+			preamble = false
+		}
+
+		// 文件状态记录(由 hash --update 写入)仅用于增量更新, 校验时忽略
+		if _, _, ok := types.ParseStatLine(line); ok {
+			continue
+		}
+
+		// 元数据记录紧跟在所属文件的记录之后
+		if encoded, ok := types.ParseMetadataLine(line); ok {
+			if err := p.addMetadata(hashMap, lastPath, encoded, headerInfo); err != nil {
+				p.cl.PrintErrorf("解析错误: 第%d行: %v\n", lineNum, err)
+			}
+			continue
+		}
+
+		// 分块哈希记录紧跟在所属文件的记录(及元数据记录)之后
+		if start, end, chunkHash, ok := types.ParseChunkLine(line); ok {
+			if dropping {
+				continue
+			}
+			if err := p.addChunk(hashMap, lastPath, start, end, chunkHash, headerInfo); err != nil {
+				// 分块记录无效时该文件仅校验整体哈希值, 并丢弃后续的分块记录
+				p.cl.PrintErrorf("解析错误: 第%d行: %v\n", lineNum, err)
+				if entry, ok := hashMap[lastPath]; ok {
+					entry.Chunks, entry.ChunkedSize = nil, 0
+					hashMap[lastPath] = entry
+				}
+				dropping = true
+			}
+			continue
+		}
+
+		// 验证并解析行内容(其他任意行都会结束上一条记录的分块哈希记录)
+		lastPath, dropping = "", false
 		hash, filePath, err := p.validator.validateLine(line, lineNum)
 		if err != nil {
 			p.cl.PrintErrorf("解析错误: %v\n", err)
@@ -163,6 +255,7 @@ func (p *hashFileParser) parseContent(scanner *bufio.Scanner, headerInfo *types.
 			RealPath: resolvedPath,
 			Hash:     hash,
 		}
+		lastPath = filePath
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -172,6 +265,178 @@ func (p *hashFileParser) parseContent(scanner *bufio.Scanner, headerInfo *types.
 	return hashMap, nil
 }
 
+// addMetadata 将元数据记录添加到所属文件的记录中
+//
+// 参数:
+//   - hashMap: 虚拟哈希映射表
+//   - filePath: 所属文件的路径(为空时表示前面没有有效的文件记录)
+//   - encoded: 编码后的元数据
+//   - headerInfo: 文件头信息
+//
+// 返回值:
+//   - error: 元数据记录无效时返回错误
+func (p *hashFileParser) addMetadata(hashMap types.VirtualHashMap, filePath, encoded string, headerInfo *types.ChecksumHeader) error {
+	if len(headerInfo.Metadata) == 0 {
+		return fmt.Errorf("校验文件未记录元数据字段, 无法使用元数据记录")
+	}
+	if filePath == "" {
+		return fmt.Errorf("元数据记录前没有有效的文件记录")
+	}
+	if _, err := metadata.Parse(encoded); err != nil {
+		return fmt.Errorf("文件 %s: %v", filePath, err)
+	}
+
+	entry := hashMap[filePath]
+	entry.Metadata = encoded
+	hashMap[filePath] = entry
+	return nil
+}
+
+// addChunk 将分块哈希记录添加到所属文件的记录中
+//
+// 参数:
+//   - hashMap: 虚拟哈希映射表
+//   - filePath: 所属文件的路径(为空时表示前面没有有效的文件记录)
+//   - start: 分块起始偏移
+//   - end: 分块结束偏移
+//   - chunkHash: 分块哈希值
+//   - headerInfo: 文件头信息
+//
+// 返回值:
+//   - error: 分块记录无效时返回错误
+//
+// 注意:
+//   - 分块必须从偏移0开始连续排列, 除最后一块外大小均为文件头记录的分块大小
+func (p *hashFileParser) addChunk(hashMap types.VirtualHashMap, filePath string, start, end int64, chunkHash string, headerInfo *types.ChecksumHeader) error {
+	chunkSize := headerInfo.ChunkSize
+	if chunkSize <= 0 {
+		return fmt.Errorf("校验文件未记录分块大小, 无法使用分块哈希记录")
+	}
+	if filePath == "" {
+		return fmt.Errorf("分块哈希记录前没有有效的文件记录")
+	}
+
+	entry := hashMap[filePath]
+	if start != entry.ChunkedSize || end-start+1 > chunkSize || entry.ChunkedSize%chunkSize != 0 {
+		return fmt.Errorf("文件 %s 的分块范围 %d-%d 不连续或超出分块大小", filePath, start, end)
+	}
+	if len(chunkHash) != len(entry.Hash) {
+		return fmt.Errorf("文件 %s 分块 %d-%d 的哈希值长度与文件哈希值不一致", filePath, start, end)
+	}
+
+	entry.Chunks = append(entry.Chunks, chunkHash)
+	entry.ChunkedSize = end + 1
+	hashMap[filePath] = entry
+	return nil
+}
+
+// parseHeaderlessContent 解析无文件头的 GNU coreutils 或 BSD 标签格式校验文件
+//
+// 参数:
+//   - firstLine: 文件第一行内容
+//   - scanner: 文件扫描器(已读取第一行)
+//   - userBaseDir: 用户指定基准目录
+//
+// 返回值:
+//   - *types.ChecksumHeader: 推断出的文件头信息(便携模式)
+//   - types.VirtualHashMap: 虚拟哈希映射表
+//   - error: 错误信息
+//
+// 注意:
+//   - BSD 格式根据行内标签确定算法, GNU 格式优先使用用户指定的算法, 否则根据摘要长度推断
+//   - 同一个校验文件中的所有条目必须使用同一种算法
+//   - 开头的注释行和空行会被跳过, 第一行有效内容无法识别时视为格式错误
+func (p *hashFileParser) parseHeaderlessContent(firstLine string, scanner *bufio.Scanner, userBaseDir string) (*types.ChecksumHeader, types.VirtualHashMap, error) {
+	headerInfo := &types.ChecksumHeader{
+		HashType: p.hashType,                 // 用户指定的算法(可能为空)
+		Mode:     types.ChecksumModePortable, // 外部工具生成的校验文件均相对当前目录
+	}
+	hashMap := make(types.VirtualHashMap)
+
+	line, lineNum := firstLine, 1
+	seenContent := false // 是否已读取到注释和空行以外的内容
+	for {
+		format, tag, hash, filePath, err := p.validator.validateHeaderlessLine(line, lineNum)
+		isContent := err != nil || hash != ""
+		if err != nil {
+			// 第一行有效内容无法识别时视为格式错误
+			if !seenContent {
+				return nil, nil, fmt.Errorf("校验文件头格式错误, 且无法识别为GNU或BSD格式: %v", err)
+			}
+			p.cl.PrintErrorf("解析错误: %v\n", err)
+		} else if hash != "" && filePath != "" {
+			if headerInfo.Format == "" {
+				headerInfo.Format = format
+			}
+
+			// 确定并检查算法
+			if err := p.resolveHeaderlessHashType(headerInfo, tag, hash, lineNum); err != nil {
+				p.cl.PrintErrorf("解析错误: %v\n", err)
+			} else if resolvedPath, err := p.resolveFilePath(filePath, headerInfo, userBaseDir); err != nil {
+				p.cl.PrintErrorf("路径解析失败: %v\n", err)
+			} else {
+				hashMap[filePath] = types.VirtualHashEntry{
+					RealPath: resolvedPath,
+					Hash:     strings.ToLower(hash),
+				}
+			}
+		}
+
+		seenContent = seenContent || isContent
+
+		if !scanner.Scan() {
+			break
+		}
+		line = scanner.Text()
+		lineNum++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("读取校验文件时出错: %v", err)
+	}
+
+	return headerInfo, hashMap, nil
+}
+
+// resolveHeaderlessHashType 确定无文件头校验文件中条目的哈希算法
+//
+// 参数:
+//   - headerInfo: 推断中的文件头信息, 首次确定算法时写入 HashType
+//   - tag: BSD 格式的算法标签(GNU 格式为空)
+//   - hash: 十六进制哈希值
+//   - lineNum: 行号
+//
+// 返回值:
+//   - error: 算法无法确定、与之前的条目不一致或摘要长度不匹配时返回错误
+func (p *hashFileParser) resolveHeaderlessHashType(headerInfo *types.ChecksumHeader, tag, hash string, lineNum int) error {
+	hashType := headerInfo.HashType
+
+	if tag != "" {
+		// BSD 格式: 由标签决定算法
+		tagType, ok := digest.FromTag(tag)
+		if !ok {
+			return fmt.Errorf("第%d行不支持的哈希算法: %s", lineNum, tag)
+		}
+		if hashType != "" && hashType != tagType {
+			return fmt.Errorf("第%d行哈希算法 %s 与校验文件中的 %s 不一致", lineNum, tagType, hashType)
+		}
+		hashType = tagType
+	} else if hashType == "" {
+		// GNU 格式: 根据摘要长度推断算法
+		hashType = digest.InferFromHexLen(len(hash))
+		if hashType == "" {
+			return fmt.Errorf("第%d行无法根据哈希值长度推断算法: %s", lineNum, hash)
+		}
+	}
+
+	if len(hash) != digest.HexLen(hashType) {
+		return fmt.Errorf("第%d行哈希值长度与算法 %s 不匹配: %s", lineNum, hashType, hash)
+	}
+
+	headerInfo.HashType = hashType
+	return nil
+}
+
 // resolveFilePath 解析文件路径
 //
 // 参数:
@@ -202,9 +467,9 @@ func (p *hashFileParser) resolveFilePath(filePath string, headerInfo *types.Chec
 		}
 		// 如果没有基准路径，降级为便携模式处理
 		fallthrough
-	case types.ChecksumModePortable, "": // 便携模式或旧格式（默认便携模式）
-		// 直接使用当前目录作为基准目录
-		return filepath.Join(".", filePath), nil
+	case types.ChecksumModePortable, types.ChecksumModeTree, "": // 便携模式、目录树模式或旧格式（默认便携模式）
+		// 使用解析器的基准目录, 未指定时使用当前目录
+		return filepath.Join(cmp.Or(p.baseDir, "."), filePath), nil
 	default:
 		return "", fmt.Errorf("未知的校验文件模式: %s", headerInfo.Mode)
 	}
@@ -1,12 +1,15 @@
 package check
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
 	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
 func TestHashFileParser_ParseFile(t *testing.T) {
@@ -53,6 +56,14 @@ c34652066a18513105ac1ab96fcbef8e root/subdir/test.txt`,
 			expectError: false,
 			expectCount: 1,
 		},
+		{
+			name: "带连字符的哈希算法",
+			fileContent: `#sha3-256#2024-01-01 10:00:00#PORTABLE
+3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532 test.txt`,
+			isRelPath:   false,
+			expectError: false,
+			expectCount: 1,
+		},
 		{
 			name:        "空文件",
 			fileContent: ``,
@@ -142,6 +153,118 @@ c34652066a18513105ac1ab96fcbef8e test.txt`,
 	}
 }
 
+func TestHashFileParser_ParseHeaderless(t *testing.T) {
+	cl := colorlib.New()
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name         string
+		fileContent  string
+		userHashType string
+		expectType   string
+		expectFormat string
+		expectCount  int
+		expectError  bool
+	}{
+		{
+			name: "GNU格式-按长度推断sha256",
+			fileContent: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  empty.txt\n" +
+				"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855 *binary.bin\n",
+			expectType:   "sha256",
+			expectFormat: "gnu",
+			expectCount:  2,
+		},
+		{
+			name:         "GNU格式-用户指定算法",
+			fileContent:  "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262  empty.txt\n",
+			userHashType: "blake3",
+			expectType:   "blake3",
+			expectFormat: "gnu",
+			expectCount:  1,
+		},
+		{
+			name:         "GNU格式-转义文件名",
+			fileContent:  "\\d41d8cd98f00b204e9800998ecf8427e  a\\\\b.txt\n",
+			expectType:   "md5",
+			expectFormat: "gnu",
+			expectCount:  1,
+		},
+		{
+			name: "BSD格式-按标签确定算法",
+			fileContent: "SHA3-256 (empty.txt) = a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a\n" +
+				"SHA3-256 (dir/file name.txt) = a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a\n",
+			expectType:   "sha3-256",
+			expectFormat: "bsd",
+			expectCount:  2,
+		},
+		{
+			name: "BSD格式-算法不一致的行被跳过",
+			fileContent: "MD5 (a.txt) = d41d8cd98f00b204e9800998ecf8427e\n" +
+				"SHA1 (b.txt) = da39a3ee5e6b4b0d3255bfef95601890afd80709\n",
+			expectType:   "md5",
+			expectFormat: "bsd",
+			expectCount:  1,
+		},
+		{
+			name: "GNU格式-开头的注释行",
+			fileContent: "# sha256sum 生成\n" +
+				"#\n" +
+				"\n" +
+				"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  empty.txt\n",
+			expectType:   "sha256",
+			expectFormat: "gnu",
+			expectCount:  1,
+		},
+		{
+			name:        "无法识别的格式",
+			fileContent: "not a checksum line\n",
+			expectError: true,
+		},
+		{
+			name:        "注释后无法识别的格式",
+			fileContent: "# 注释\nnot a checksum line\n",
+			expectError: true,
+		},
+		{
+			name:        "仅包含注释",
+			fileContent: "# 注释\n",
+			expectError: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(tempDir, fmt.Sprintf("headerless_%d.sum", i))
+			if err := os.WriteFile(testFile, []byte(tt.fileContent), 0644); err != nil {
+				t.Fatalf("创建测试文件失败: %v", err)
+			}
+
+			parser := newHashFileParser(cl)
+			parser.hashType = tt.userHashType
+			hashMap, hashType, err := parser.parseFile(testFile, "")
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("期望错误但没有发生错误")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("不期望错误但发生了错误: %v", err)
+			}
+
+			if hashType != tt.expectType {
+				t.Errorf("哈希类型不匹配，期望: %s, 实际: %s", tt.expectType, hashType)
+			}
+
+			if len(hashMap) != tt.expectCount {
+				t.Errorf("哈希映射数量不匹配，期望: %d, 实际: %d", tt.expectCount, len(hashMap))
+			}
+		})
+	}
+}
+
 func TestHashFileParser_FileNotExists(t *testing.T) {
 	cl := colorlib.New()
 	parser := newHashFileParser(cl)
@@ -158,3 +281,188 @@ func TestHashFileParser_FileNotExists(t *testing.T) {
 		t.Errorf("错误消息不匹配，期望: %s, 实际: %s", expectedMsg, err.Error())
 	}
 }
+
+func TestHashFileParser_ParseTreeHeader(t *testing.T) {
+	cl := colorlib.New()
+	parser := newHashFileParser(cl)
+
+	tempDir := t.TempDir()
+	checkFile := filepath.Join(tempDir, "tree.hash")
+	content := `#sha256#2024-01-01 10:00:00#TREE#mode,symlinks
+10701fa70009b180c7ac0495bbde76db72ba18b420abccc481e5521ed58e1f95	"dist"
+08eda90406c21f55cbc98df0e8aadef708eda90406c21f55cbc98df0e8aadef7	"dist/sub"`
+	if err := os.WriteFile(checkFile, []byte(content), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	hashMap, hashType, err := parser.parseFile(checkFile, "")
+	if err != nil {
+		t.Fatalf("解析目录树校验文件失败: %v", err)
+	}
+
+	if hashType != "sha256" {
+		t.Errorf("哈希类型不匹配，期望: sha256, 实际: %s", hashType)
+	}
+	if len(hashMap) != 2 {
+		t.Errorf("条目数量不匹配，期望: 2, 实际: %d", len(hashMap))
+	}
+	if !parser.header.IsTreeMode() {
+		t.Errorf("期望识别为目录树模式, 实际模式: %s", parser.header.Mode)
+	}
+	if parser.header.Tree != "mode,symlinks" || parser.header.BasePath != "" {
+		t.Errorf("树选项解析错误: Tree=%q, BasePath=%q", parser.header.Tree, parser.header.BasePath)
+	}
+	if entry := hashMap["dist/sub"]; entry.RealPath != filepath.Join(".", "dist/sub") {
+		t.Errorf("目录路径解析错误: %s", entry.RealPath)
+	}
+}
+
+func TestHashFileParser_ParseChunks(t *testing.T) {
+	cl := colorlib.New()
+	parser := newHashFileParser(cl)
+
+	tempDir := t.TempDir()
+	checkFile := filepath.Join(tempDir, "chunks.hash")
+	content := `#md5#2024-01-01 10:00:00#PORTABLE
+#chunk-size#1024
+0afc71bd44a128d63f7998c328ac63c7	"big"
+#stat#2100#1704074400000000000
+#chunk#0-1023#549f2c902c1e06049adee51cd03f2b4f
+#chunk#1024-2047#59034913ad25f124b5e580a0d0ac9a6f
+#chunk#2048-2099#b5c9d021b9ec12ef71ebf1954fab1c24
+49ab0036bee131cf1bea45f90a432ad0	"gap"
+#chunk#0-1023#1e626f463c21fffe2f70676dffddf5f8
+#chunk#2048-2999#a85497627a485d59e0f5971df25a6c55
+#chunk#3000-3010#a85497627a485d59e0f5971df25a6c55
+d41d8cd98f00b204e9800998ecf8427e	"empty"`
+	if err := os.WriteFile(checkFile, []byte(content), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	hashMap, _, err := parser.parseFile(checkFile, "")
+	if err != nil {
+		t.Fatalf("解析分块校验文件失败: %v", err)
+	}
+
+	if parser.header.ChunkSize != 1024 {
+		t.Errorf("分块大小解析错误: %d", parser.header.ChunkSize)
+	}
+	if len(hashMap) != 3 {
+		t.Fatalf("条目数量不匹配，期望: 3, 实际: %d", len(hashMap))
+	}
+
+	// 文件状态记录不影响分块记录的归属
+	big := hashMap["big"]
+	if len(big.Chunks) != 3 || big.ChunkedSize != 2100 {
+		t.Errorf("分块记录解析错误: Chunks=%v, ChunkedSize=%d", big.Chunks, big.ChunkedSize)
+	}
+
+	// 分块不连续时该文件仅校验整体哈希值
+	if gap := hashMap["gap"]; gap.Chunks != nil || gap.ChunkedSize != 0 || gap.Hash == "" {
+		t.Errorf("不连续的分块记录应被丢弃: %+v", gap)
+	}
+	if empty := hashMap["empty"]; empty.Chunks != nil {
+		t.Errorf("空文件不应包含分块记录: %v", empty.Chunks)
+	}
+}
+
+func TestHashFileParser_ParseMetadata(t *testing.T) {
+	cl := colorlib.New()
+	parser := newHashFileParser(cl)
+
+	tempDir := t.TempDir()
+	checkFile := filepath.Join(tempDir, "meta.hash")
+	content := `#md5#2024-01-01 10:00:00#PORTABLE
+#chunk-size#1024
+#metadata#mode,owner,link
+0cc175b9c0f1b6a831c399e269772661	"a"
+#meta#gid=0&mode=0644&uid=0
+a5e54d1fd7bb69a228ef0dcd2431367e	"link"
+#meta#gid=0&link=a&mode=0777&uid=0
+92eb5ffee6ae2fec3ad71c777531578f	"b"
+#meta#mode=%zz`
+	if err := os.WriteFile(checkFile, []byte(content), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	hashMap, _, err := parser.parseFile(checkFile, "")
+	if err != nil {
+		t.Fatalf("解析元数据校验文件失败: %v", err)
+	}
+
+	if !reflect.DeepEqual(parser.header.Metadata, []string{"mode", "owner", "link"}) {
+		t.Errorf("元数据字段解析错误: %v", parser.header.Metadata)
+	}
+	if parser.header.ChunkSize != 1024 {
+		t.Errorf("分块大小解析错误: %d", parser.header.ChunkSize)
+	}
+	if len(hashMap) != 3 {
+		t.Fatalf("条目数量不匹配，期望: 3, 实际: %d", len(hashMap))
+	}
+	if got := hashMap["a"].Metadata; got != "gid=0&mode=0644&uid=0" {
+		t.Errorf("a 的元数据解析错误: %q", got)
+	}
+	if got := hashMap["link"].Metadata; got != "gid=0&link=a&mode=0777&uid=0" {
+		t.Errorf("link 的元数据解析错误: %q", got)
+	}
+
+	// 无效的元数据记录被忽略, 该文件仅校验哈希值
+	if got := hashMap["b"].Metadata; got != "" {
+		t.Errorf("无效的元数据记录应被忽略: %q", got)
+	}
+}
+
+func TestHashFileParser_ParseSymlinks(t *testing.T) {
+	cl := colorlib.New()
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		content   string
+		want      string
+		expectErr bool
+	}{
+		{
+			name:    "记录了软链接策略",
+			content: "#md5#2024-01-01 10:00:00#PORTABLE\n#symlinks#link\n0cc175b9c0f1b6a831c399e269772661\t\"a\"\n",
+			want:    types.SymlinksLink,
+		},
+		{
+			name:    "未记录软链接策略",
+			content: "#md5#2024-01-01 10:00:00#PORTABLE\n0cc175b9c0f1b6a831c399e269772661\t\"a\"\n",
+			want:    "",
+		},
+		{
+			name:      "不支持的软链接策略",
+			content:   "#md5#2024-01-01 10:00:00#PORTABLE\n#symlinks#resolve\n0cc175b9c0f1b6a831c399e269772661\t\"a\"\n",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkFile := filepath.Join(tempDir, "symlinks.hash")
+			if err := os.WriteFile(checkFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("创建测试文件失败: %v", err)
+			}
+
+			parser := newHashFileParser(cl)
+			hashMap, _, err := parser.parseFile(checkFile, "")
+			if tt.expectErr {
+				if err == nil {
+					t.Error("期望错误但没有发生错误")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("解析校验文件失败: %v", err)
+			}
+			if parser.header.Symlinks != tt.want {
+				t.Errorf("软链接策略 = %q, 期望 %q", parser.header.Symlinks, tt.want)
+			}
+			if len(hashMap) != 1 {
+				t.Errorf("条目数量不匹配，期望: 1, 实际: %d", len(hashMap))
+			}
+		})
+	}
+}
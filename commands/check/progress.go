@@ -0,0 +1,136 @@
+// Package check 实现了校验进度的显示。
+// 该文件负责 --progress 模式: 在标准错误中显示已校验的文件数和字节数、读取速率及预计剩余时间。
+package check
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gitee.com/MM-Q/fck/commands/internal/archive"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+	"github.com/schollz/progressbar/v3"
+)
+
+// progressInterval 进度条刷新间隔
+const progressInterval = 200 * time.Millisecond
+
+// checkProgress 校验进度
+type checkProgress struct {
+	totalFiles int64        // 需要校验的文件数
+	totalBytes int64        // 需要校验的字节数(校验开始时的文件大小之和)
+	files      atomic.Int64 // 已完成校验的文件数
+	completed  atomic.Int64 // 已完成校验的文件大小之和(包括命中缓存和无法读取的文件)
+	read       atomic.Int64 // 计算哈希时实际读取的字节数
+	done       chan struct{}
+	once       sync.Once
+	wg         sync.WaitGroup
+}
+
+// startCheckProgress 统计需要校验的字节数并启动进度条
+//
+// 参数:
+//   - hashMaps: 所有需要校验的记录
+//
+// 返回:
+//   - *checkProgress: 校验进度
+//
+// 注意:
+//   - 压缩包内的条目按压缩包统计大小, 目录树模式按目录项大小统计, 字节进度仅供参考
+func startCheckProgress(hashMaps ...types.VirtualHashMap) *checkProgress {
+	p := &checkProgress{done: make(chan struct{})}
+
+	archives := make(map[string]struct{})
+	for _, hashMap := range hashMaps {
+		for _, entry := range hashMap {
+			p.totalFiles++
+			realPath := entry.RealPath
+			if archivePath, _, ok := archive.Split(realPath); ok {
+				if _, seen := archives[archivePath]; seen {
+					continue
+				}
+				archives[archivePath] = struct{}{}
+				realPath = archivePath
+			}
+			if info, err := os.Stat(realPath); err == nil {
+				p.totalBytes += info.Size()
+			}
+		}
+	}
+
+	bar := progressbar.NewOptions64(
+		max(p.totalBytes, 1),                               // 进度条总长度(字节)
+		progressbar.OptionSetWriter(os.Stderr),             // 输出到标准错误, 不影响校验结果
+		progressbar.OptionClearOnFinish(),                  // 结束时清除进度条
+		progressbar.OptionSetElapsedTime(true),             // 显示已用时间
+		progressbar.OptionSetPredictTime(true),             // 显示预计剩余时间
+		progressbar.OptionSetRenderBlankState(true),        // 在进度条完成之前显示空白状态
+		progressbar.OptionShowBytes(true),                  // 显示已校验的字节数和读取速率
+		progressbar.OptionShowCount(),                      // 显示已校验/总字节数
+		progressbar.OptionSetTheme(progressbar.ThemeASCII), // ASCII 进度条主题
+		progressbar.OptionFullWidth(),                      // 设置进度条为终端最大宽度
+	)
+
+	// 统计所有协程计算哈希时读取的字节数
+	digest.SetReadCounter(&p.read)
+
+	p.wg.Go(func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.update(bar)
+			case <-p.done:
+				p.update(bar)
+				_ = bar.Finish()
+				_ = bar.Close()
+				return
+			}
+		}
+	})
+
+	return p
+}
+
+// add 记录一个文件完成校验
+//
+// 参数:
+//   - size: 文件大小
+func (p *checkProgress) add(size int64) {
+	if p == nil {
+		return
+	}
+	p.files.Add(1)
+	p.completed.Add(size)
+}
+
+// update 根据当前统计刷新进度条
+//
+// 参数:
+//   - bar: 进度条
+//
+// 注意:
+//   - 已校验的字节数取已完成文件的大小之和与实际读取字节数中的较大值, 大文件校验过程中同样能看到进度
+func (p *checkProgress) update(bar *progressbar.ProgressBar) {
+	done := max(p.completed.Load(), p.read.Load())
+	bar.Describe(fmt.Sprintf("正在校验(%d/%d 个文件)", p.files.Load(), p.totalFiles))
+	bar.ChangeMax64(max(p.totalBytes, done, 1))
+	_ = bar.Set64(done)
+}
+
+// stop 停止并清除进度条, 可重复调用
+func (p *checkProgress) stop() {
+	if p == nil {
+		return
+	}
+	p.once.Do(func() {
+		close(p.done)
+		p.wg.Wait()
+		digest.SetReadCounter(nil)
+	})
+}
@@ -0,0 +1,46 @@
+package check
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+func TestCheckProgress(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		"a.txt":   "aaaa",
+		"b.txt":   "bb",
+		"pkg.zip": "zipdata",
+	})
+
+	hashMap := types.VirtualHashMap{
+		"a.txt":       {RealPath: filepath.Join(tempDir, "a.txt")},
+		"missing.txt": {RealPath: filepath.Join(tempDir, "missing.txt")},
+		// 同一压缩包内的条目只统计一次压缩包大小
+		"pkg.zip!/x": {RealPath: filepath.Join(tempDir, "pkg.zip") + "!/x"},
+		"pkg.zip!/y": {RealPath: filepath.Join(tempDir, "pkg.zip") + "!/y"},
+	}
+	other := types.VirtualHashMap{
+		"b.txt": {RealPath: filepath.Join(tempDir, "b.txt")},
+	}
+
+	progress := startCheckProgress(hashMap, other)
+	if progress.totalFiles != 5 || progress.totalBytes != 4+2+7 {
+		t.Errorf("统计错误: totalFiles=%d totalBytes=%d", progress.totalFiles, progress.totalBytes)
+	}
+
+	progress.add(4)
+	progress.add(2)
+	if progress.files.Load() != 2 || progress.completed.Load() != 6 {
+		t.Errorf("进度错误: files=%d completed=%d", progress.files.Load(), progress.completed.Load())
+	}
+
+	// 停止可以重复调用, nil 进度同样可以调用
+	progress.stop()
+	progress.stop()
+	var none *checkProgress
+	none.add(1)
+	none.stop()
+}
@@ -0,0 +1,413 @@
+// Package check 实现了目录树下多个校验文件的递归校验。
+// 该文件负责 --recursive 模式: 查找根目录下所有匹配名称的校验文件, 每个校验文件按其所在目录解析记录路径,
+// 所有记录共用同一个工作协程池校验, 最后输出每个校验文件的统计和全局统计。
+package check
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/hashcache"
+	"gitee.com/MM-Q/fck/commands/internal/output"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// manifestCheck 递归校验中的单个校验文件
+type manifestCheck struct {
+	path    string               // 校验文件路径
+	checker *fileChecker         // 按该校验文件头配置的校验器
+	hashMap types.VirtualHashMap // 校验文件中的记录
+	summary checkSummary         // 该校验文件的校验结果统计
+}
+
+// manifestJob 共享工作协程池中的单条记录任务
+type manifestJob struct {
+	manifest *manifestCheck         // 所属校验文件
+	entry    types.VirtualHashEntry // 校验记录
+}
+
+// manifestArchiveJob 共享工作协程池中的压缩包任务
+type manifestArchiveJob struct {
+	manifest *manifestCheck // 所属校验文件
+	group    archiveGroup   // 同一压缩包内的记录
+}
+
+// manifestResult 带所属校验文件的校验结果
+type manifestResult struct {
+	manifest *manifestCheck // 所属校验文件
+	result   checkResult    // 校验结果
+}
+
+// manifestSummary 结构化输出中单个校验文件的统计
+type manifestSummary struct {
+	Path string `json:"path"` // 校验文件路径
+	checkSummary
+}
+
+// recursiveSummary 结构化输出中递归校验的汇总信息
+type recursiveSummary struct {
+	checkSummary
+	Manifests []manifestSummary `json:"manifests"` // 每个校验文件的统计
+}
+
+// findManifests 查找根目录下所有文件名匹配的校验文件
+//
+// 参数:
+//   - root: 根目录
+//   - pattern: 校验文件名或通配符(如 *.sha256), 仅匹配文件名
+//
+// 返回:
+//   - []string: 按路径排序的校验文件列表
+//   - error: 通配符无效或根目录无法访问时返回错误
+//
+// 注意:
+//   - 无法访问的子目录输出警告后跳过
+func findManifests(cl *colorlib.ColorLib, root, pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, exitcode.Usagef("无效的校验文件名通配符 %s: %v", pattern, err)
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("无法访问根目录 %s: %v", root, err)
+	}
+	if !info.IsDir() {
+		return nil, exitcode.Usagef("--recursive 需要指定目录: %s", root)
+	}
+
+	var manifests []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			cl.PrintWarnf("跳过无法访问的路径 %s: %v\n", path, err)
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if matched, _ := filepath.Match(pattern, d.Name()); matched {
+			manifests = append(manifests, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("遍历根目录 %s 失败: %v", root, err)
+	}
+
+	sort.Strings(manifests)
+	return manifests, nil
+}
+
+// loadManifest 解析校验文件并创建按其文件头配置的校验器
+//
+// 参数:
+//   - cl: 颜色库
+//   - path: 校验文件路径
+//   - hashType: 用户指定的哈希算法(为空时自动推断)
+//
+// 返回:
+//   - *manifestCheck: 待校验的校验文件
+//   - error: 签名验证或解析失败时返回错误
+//
+// 注意:
+//   - 记录路径按 resolveFilePath 的规则解析, 便携模式和无头信息的校验文件以校验文件所在目录为基准
+//   - HMAC 密钥模式的校验文件不支持递归校验
+func loadManifest(cl *colorlib.ColorLib, path, hashType string) (*manifestCheck, error) {
+	parser := newHashFileParser(cl)
+	parser.hashType = hashType
+	parser.baseDir = filepath.Dir(path)
+
+	// 验证校验文件签名
+	if checkCmdVerify.Get() != "" {
+		content, err := verifyChecksumFile(cl, path, checkCmdVerify.Get())
+		if err != nil {
+			return nil, err
+		}
+		parser.content = content
+	}
+
+	hashMap, hashFunc, err := parser.parseFile(path, "")
+	if err != nil {
+		return nil, fmt.Errorf("解析校验文件失败: %v", err)
+	}
+	if parser.header.Keyed {
+		return nil, fmt.Errorf("HMAC 密钥模式的校验文件不支持递归校验")
+	}
+
+	checker := newFileChecker(cl, hashFunc)
+	if err := configureChecker(checker, path, parser.header, hashFunc); err != nil {
+		return nil, err
+	}
+
+	return &manifestCheck{
+		path:    path,
+		checker: checker,
+		hashMap: hashMap,
+		summary: checkSummary{Total: len(hashMap)},
+	}, nil
+}
+
+// recursiveCheck 递归校验根目录下的所有校验文件
+//
+// 参数:
+//   - ctx: 上下文, 取消后停止校验
+//   - cl: 颜色库
+//   - hashType: 用户指定的哈希算法(为空时自动推断)
+//   - structured: 是否为结构化输出
+//
+// 返回:
+//   - error: 存在未通过的文件时返回 summaryError 生成的错误, 存在无法解析的校验文件时退出码为 exitcode.Partial,
+//     收到中断信号时返回 exitcode.ErrInterrupted
+func recursiveCheck(ctx context.Context, cl *colorlib.ColorLib, hashType string, structured bool) error {
+	// 每个校验文件使用各自的基准目录和文件头, 不支持逐个校验文件的参数
+	conflicts := []struct {
+		name string
+		set  bool
+	}{
+		{"--file", checkCmdFile.Get() != ""},
+		{"--base-dir", checkCmdBaseDir.Get() != ""},
+		{"--archive", checkCmdArchive.Get() != ""},
+		{"--strict", checkCmdStrict.Get()},
+		{"--hmac-key-file", checkCmdHMACKey.Get() != ""},
+		{"--refresh", checkCmdRefresh.Get()},
+		{"--resume", checkCmdResume.Get()},
+	}
+	for _, flag := range conflicts {
+		if flag.set {
+			return exitcode.Usagef("%s 不能与 --recursive 同时使用", flag.name)
+		}
+	}
+
+	root := checkCmd.Arg(0)
+	if root == "" {
+		root = "."
+	}
+	paths, err := findManifests(cl, root, checkCmdName.Get())
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return exitcode.Failuref("目录 %s 下没有找到名称匹配 %s 的校验文件", root, checkCmdName.Get())
+	}
+
+	cl.Bluef("正在校验完整性(共 %d 个校验文件)...\n", len(paths))
+
+	// 逐个解析校验文件, 无法解析的校验文件输出错误后跳过
+	var (
+		manifests  []*manifestCheck
+		algorithms []string
+		invalid    int
+	)
+	for _, path := range paths {
+		manifest, err := loadManifest(cl, path, hashType)
+		if err != nil {
+			cl.PrintErrorf("校验文件 %s: %v\n", path, err)
+			invalid++
+			continue
+		}
+		manifests = append(manifests, manifest)
+		if !slices.Contains(algorithms, manifest.checker.hashType) {
+			algorithms = append(algorithms, manifest.checker.hashType)
+		}
+	}
+
+	// 所有校验文件共用结构化输出写入器、校验报告和哈希缓存
+	var (
+		records *output.Writer
+		report  *checkReport
+		cache   *hashcache.Cache
+	)
+	if structured {
+		if records, err = output.NewWriter(os.Stdout, checkCmdOutput.Get(), checkRecordColumns); err != nil {
+			return err
+		}
+	}
+	if checkCmdReport.Get() != "" {
+		report = newCheckReport(checkCmdReport.Get(), checkCmdReportFormat.Get(), root, strings.Join(algorithms, ","))
+	}
+	if checkCmdCache.Get() {
+		if cache, err = hashcache.OpenDefault(); err != nil {
+			cl.PrintWarnf("哈希缓存不可用, 将直接计算哈希值: %v\n", err)
+		} else {
+			defer func() { _ = cache.Close() }()
+		}
+	}
+	// 所有校验文件共用进度条和快速失败的校验上下文
+	ctx, cancelCause := context.WithCancelCause(ctx)
+	defer cancelCause(nil)
+	var cancel context.CancelFunc
+	if checkCmdFailFast.Get() {
+		cancel = func() { cancelCause(errStopped) }
+	}
+	var progress *checkProgress
+	if checkCmdProgress.Get() {
+		hashMaps := make([]types.VirtualHashMap, 0, len(manifests))
+		for _, manifest := range manifests {
+			hashMaps = append(hashMaps, manifest.hashMap)
+		}
+		progress = startCheckProgress(hashMaps...)
+		defer progress.stop()
+	}
+
+	for _, manifest := range manifests {
+		manifest.checker.records = records
+		manifest.checker.report = report
+		manifest.checker.cache = cache
+		manifest.checker.progress = progress
+		manifest.checker.ctx = ctx
+		manifest.checker.cancel = cancel
+	}
+
+	// 使用一个工作协程池校验所有记录
+	workers := runtime.NumCPU()
+	if checkCmdJobs.Get() > 0 {
+		workers = checkCmdJobs.Get()
+	}
+	global := checkSummary{}
+	for result := range runManifests(manifests, workers) {
+		status, err := result.manifest.checker.handleResult(result.result)
+		if err != nil {
+			return err
+		}
+		result.manifest.summary.add(status)
+		global.add(status)
+	}
+	progress.stop()
+	if isInterrupted(ctx) {
+		return exitcode.ErrInterrupted
+	}
+	for _, manifest := range manifests {
+		global.Total += manifest.summary.Total
+	}
+
+	// 写入校验报告
+	if report != nil {
+		if err := report.write(global); err != nil {
+			return err
+		}
+	}
+
+	// 输出每个校验文件的统计和全局统计
+	printer := newFileChecker(cl, "")
+	printer.cache = cache
+	printer.ctx, printer.cancel = ctx, cancel
+	printer.warnStopped(global)
+	if records != nil {
+		summary := recursiveSummary{checkSummary: global, Manifests: []manifestSummary{}}
+		for _, manifest := range manifests {
+			summary.Manifests = append(summary.Manifests, manifestSummary{Path: manifest.path, checkSummary: manifest.summary})
+		}
+		if err := records.Close(summary); err != nil {
+			return err
+		}
+	} else {
+		for _, manifest := range manifests {
+			s := manifest.summary
+			cl.Bluef("%s: ", manifest.path)
+			printer.printCounts(s.Passed, s.Mismatched, s.Missing, s.Errors, s.Unexpected, s.Total)
+		}
+		printer.printSummary(global.Passed, global.Mismatched, global.Missing, global.Errors, global.Unexpected, global.Total)
+	}
+
+	if invalid > 0 {
+		return exitcode.Partialf("%d 个校验文件无法解析", invalid)
+	}
+	return summaryError(global)
+}
+
+// runManifests 使用共享的工作协程池校验所有校验文件中的记录
+//
+// 参数:
+//   - manifests: 待校验的校验文件
+//   - workers: 工作协程数
+//
+// 返回:
+//   - <-chan manifestResult: 校验结果通道, 全部校验完成后关闭
+//
+// 注意:
+//   - 压缩包内的记录按压缩包分组, 每个压缩包只读取一次
+func runManifests(manifests []*manifestCheck, workers int) <-chan manifestResult {
+	var (
+		files    []manifestJob
+		archives []manifestArchiveJob
+	)
+	for _, manifest := range manifests {
+		groups, entries := splitArchiveEntries(manifest.hashMap)
+		for _, entry := range entries {
+			files = append(files, manifestJob{manifest: manifest, entry: entry})
+		}
+		for _, group := range groups {
+			archives = append(archives, manifestArchiveJob{manifest: manifest, group: group})
+		}
+	}
+
+	jobs := make(chan manifestJob, len(files))
+	archiveJobs := make(chan manifestArchiveJob, len(archives))
+	results := make(chan manifestResult, workers)
+
+	// 启动工作协程
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Go(
+			func() {
+				for job := range jobs {
+					if job.manifest.checker.ctx.Err() != nil {
+						return
+					}
+					result := job.manifest.checker.checkEntry(job.entry)
+					job.manifest.checker.stopOnFailure(result)
+					results <- manifestResult{manifest: job.manifest, result: result}
+				}
+			},
+		)
+	}
+	for i := 0; i < min(workers, len(archives)); i++ {
+		wg.Go(
+			func() {
+				for job := range archiveJobs {
+					if job.manifest.checker.ctx.Err() != nil {
+						return
+					}
+					groupResults := make(chan checkResult)
+					go func() {
+						defer close(groupResults)
+						job.manifest.checker.checkArchive(job.group, groupResults)
+					}()
+					for result := range groupResults {
+						results <- manifestResult{manifest: job.manifest, result: result}
+					}
+				}
+			},
+		)
+	}
+
+	// 发送任务
+	for _, job := range files {
+		jobs <- job
+	}
+	close(jobs)
+	for _, job := range archives {
+		archiveJobs <- job
+	}
+	close(archiveJobs)
+
+	// 等待所有工作完成
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
@@ -0,0 +1,117 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+)
+
+// writeTestFiles 在临时目录中创建测试文件
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("创建目录失败: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+}
+
+func TestFindManifests(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"checksum.hash":       "",
+		"a/checksum.hash":     "",
+		"a/b/checksum.hash":   "",
+		"a/data.txt":          "",
+		"c/release.sha256":    "",
+		"c/checksum.hash.sig": "",
+	})
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{name: "按文件名", pattern: "checksum.hash", want: []string{"a/b/checksum.hash", "a/checksum.hash", "checksum.hash"}},
+		{name: "按通配符", pattern: "*.sha256", want: []string{"c/release.sha256"}},
+		{name: "没有匹配", pattern: "*.md5", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findManifests(colorlib.New(), root, tt.pattern)
+			if err != nil {
+				t.Fatalf("findManifests() 返回错误: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("findManifests() = %v, 期望 %v", got, tt.want)
+			}
+			for i, path := range got {
+				rel, _ := filepath.Rel(root, path)
+				if filepath.ToSlash(rel) != tt.want[i] {
+					t.Errorf("第 %d 个校验文件 = %s, 期望 %s", i, rel, tt.want[i])
+				}
+			}
+		})
+	}
+
+	// 无效的通配符为用法错误
+	if _, err := findManifests(colorlib.New(), root, "["); exitcode.Code(err) != exitcode.Usage {
+		t.Errorf("无效通配符期望返回用法错误, 实际: %v", err)
+	}
+}
+
+func TestRecursiveManifests(t *testing.T) {
+	// 初始化命令标志
+	InitCheckCmd()
+
+	// 两个子目录各有一个便携模式的校验文件, 记录路径相对于校验文件所在目录
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"a/a.txt":         "a",
+		"a/checksum.hash": "#md5#2024-01-01 10:00:00#PORTABLE\n0cc175b9c0f1b6a831c399e269772661\t\"a.txt\"\n", // md5("a")
+		"b/sub/b.txt":     "changed",
+		"b/checksum.hash": "#md5#2024-01-01 10:00:00#PORTABLE\n92eb5ffee6ae2fec3ad71c777531578f\t\"sub/b.txt\"\n", // md5("b")
+	})
+
+	cl := colorlib.New()
+	var manifests []*manifestCheck
+	for _, dir := range []string{"a", "b"} {
+		manifest, err := loadManifest(cl, filepath.Join(root, dir, "checksum.hash"), "")
+		if err != nil {
+			t.Fatalf("loadManifest() 返回错误: %v", err)
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	// 记录路径以校验文件所在目录为基准解析
+	for _, entry := range manifests[1].hashMap {
+		if want := filepath.Join(root, "b", "sub", "b.txt"); entry.RealPath != want {
+			t.Errorf("记录路径 = %s, 期望 %s", entry.RealPath, want)
+		}
+	}
+
+	var global checkSummary
+	for result := range runManifests(manifests, 2) {
+		status := resultStatus(result.result)
+		result.manifest.summary.add(status)
+		global.add(status)
+	}
+
+	if manifests[0].summary.Passed != 1 || manifests[1].summary.Mismatched != 1 {
+		t.Errorf("校验文件统计错误: a=%+v b=%+v", manifests[0].summary, manifests[1].summary)
+	}
+	if global.Passed != 1 || global.Mismatched != 1 {
+		t.Errorf("全局统计错误: %+v", global)
+	}
+	if exitcode.Code(summaryError(global)) != exitcode.Failure {
+		t.Errorf("存在不匹配的文件时期望退出码为 %d", exitcode.Failure)
+	}
+}
@@ -0,0 +1,386 @@
+// Package check 实现了校验文件的刷新。
+// 该文件负责 --refresh 模式: 校验完成后将不匹配文件的新哈希值写回校验文件, 删除不存在的文件的记录,
+// 严格模式下追加未记录的文件, 保留文件头、注释和原有的记录顺序, 并原子地替换校验文件。
+package check
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gitee.com/MM-Q/fck/commands/internal/archive"
+	"gitee.com/MM-Q/fck/commands/internal/common"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/metadata"
+	"gitee.com/MM-Q/fck/commands/internal/signature"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// manifestRefresh 刷新模式下需要写回校验文件的变更
+type manifestRefresh struct {
+	checkFile string                // 校验文件路径
+	content   []byte                // 校验文件的原始内容(验证签名时为已验证的内容)
+	header    *types.ChecksumHeader // 校验文件头信息
+	hashMap   types.VirtualHashMap  // 校验文件中的记录
+	baseDir   string                // 新文件记录路径的基准目录
+	keys      map[string]string     // 真实路径到记录路径的映射
+	updated   map[string]string     // 需要更新的记录(记录路径 -> 校验时计算的哈希值)
+	removed   map[string]struct{}   // 需要删除的记录(记录路径)
+	added     []string              // 需要添加的新文件(真实路径)
+}
+
+// newManifestRefresh 创建校验文件刷新器
+//
+// 参数:
+//   - checkFile: 校验文件路径
+//   - content: 校验文件的原始内容
+//   - header: 校验文件头信息
+//   - hashMap: 校验文件中的记录
+//   - baseDir: 新文件记录路径的基准目录, 与解析记录路径时使用的基准目录一致
+//
+// 返回:
+//   - *manifestRefresh: 校验文件刷新器
+func newManifestRefresh(checkFile string, content []byte, header *types.ChecksumHeader, hashMap types.VirtualHashMap, baseDir string) *manifestRefresh {
+	keys := make(map[string]string, len(hashMap))
+	for key, entry := range hashMap {
+		keys[entry.RealPath] = key
+	}
+
+	return &manifestRefresh{
+		checkFile: checkFile,
+		content:   content,
+		header:    header,
+		hashMap:   hashMap,
+		baseDir:   baseDir,
+		keys:      keys,
+		updated:   make(map[string]string),
+		removed:   make(map[string]struct{}),
+	}
+}
+
+// add 根据一个文件的校验结果记录需要写回的变更
+//
+// 参数:
+//   - result: 校验结果
+//   - status: 校验状态
+//
+// 注意:
+//   - 无法校验的文件保持原有记录不变
+func (r *manifestRefresh) add(result checkResult, status string) {
+	switch status {
+	case statusMismatch:
+		r.updated[r.keys[result.filePath]] = result.actualHash
+	case statusMissing:
+		r.removed[r.keys[result.filePath]] = struct{}{}
+	case statusUnexpected:
+		r.added = append(r.added, result.filePath)
+	}
+}
+
+// empty 是否没有需要写回的变更
+func (r *manifestRefresh) empty() bool {
+	return len(r.updated) == 0 && len(r.removed) == 0 && len(r.added) == 0
+}
+
+// run 确认后将变更写回校验文件
+//
+// 参数:
+//   - c: 校验器(按校验文件头配置, 用于重新计算分块哈希值和元数据)
+//   - yes: 是否跳过确认
+//   - in: 读取确认输入的来源
+//
+// 返回:
+//   - bool: 是否已更新校验文件
+//   - error: 生成或写入校验文件失败时返回错误
+func (r *manifestRefresh) run(c *fileChecker, yes bool, in io.Reader) (bool, error) {
+	if r.empty() {
+		c.cl.PrintOk("校验文件无需更新")
+		return false, nil
+	}
+
+	c.cl.Bluef("将更新校验文件 %s: 更新 %d 条记录, 删除 %d 条记录, 添加 %d 个新文件\n", r.checkFile, len(r.updated), len(r.removed), len(r.added))
+	if !yes && !confirm(c, in, "确认更新校验文件? [y/N]: ") {
+		c.cl.PrintWarn("已取消更新校验文件")
+		return false, nil
+	}
+
+	data, err := r.rewrite(c)
+	if err != nil {
+		return false, err
+	}
+
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(r.checkFile); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := common.WriteFileAtomic(r.checkFile, data, perm); err != nil {
+		return false, fmt.Errorf("写入校验文件 %s 失败: %w", r.checkFile, err)
+	}
+	c.cl.PrintOkf("已更新校验文件 %s\n", r.checkFile)
+
+	// 校验文件内容已变化, 原签名不再有效
+	sigFile := signature.SignatureFileName(r.checkFile)
+	if _, err := os.Stat(sigFile); err == nil {
+		c.cl.PrintWarnf("签名文件 %s 已失效, 请使用 hash --sign 重新生成校验文件或重新签名\n", sigFile)
+	}
+	return true, nil
+}
+
+// confirm 输出提示并读取用户确认
+//
+// 返回:
+//   - bool: 输入 y 或 yes(不区分大小写)时返回 true, 其他输入或读取失败时返回 false
+func confirm(c *fileChecker, in io.Reader, prompt string) bool {
+	c.cl.Yellowf("%s", prompt)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && answer == "" {
+		c.cl.Whitef("\n")
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// rewrite 生成刷新后的校验文件内容
+//
+// 参数:
+//   - c: 校验器
+//
+// 返回:
+//   - []byte: 刷新后的校验文件内容
+//   - error: 重新计算哈希值、分块哈希值或元数据失败时返回错误
+//
+// 注意:
+//   - 除更新和删除的记录(及其后的文件状态、元数据和分块哈希记录)外, 其余各行原样保留
+//   - 更新的记录仅替换哈希值, 保留原有的路径写法
+func (r *manifestRefresh) rewrite(c *fileChecker) ([]byte, error) {
+	validator := newHashLineValidator()
+	native := r.header.Format == types.ChecksumFormatFck
+
+	var b strings.Builder
+	b.Grow(len(r.content))
+
+	skipping := false // 是否正在跳过已删除或已更新记录的文件状态、元数据和分块哈希记录
+	preamble := native
+	for i, piece := range strings.SplitAfter(string(r.content), "\n") {
+		line := strings.TrimSuffix(piece, "\n")
+		lineNum := i + 1
+
+		// 文件头及其后的扩展记录原样保留
+		if native && lineNum == 1 {
+			b.WriteString(piece)
+			continue
+		}
+		if preamble {
+			if isPreambleLine(line) {
+				b.WriteString(piece)
+				continue
+			}
+			preamble = false
+		}
+
+		// 文件状态、元数据和分块哈希记录属于上一条记录
+		if native && isEntryTailLine(line) {
+			if !skipping {
+				b.WriteString(piece)
+			}
+			continue
+		}
+		skipping = false
+
+		// 识别记录所在的行, 其他行(空行、注释和无效行)原样保留
+		var hash, key string
+		if native {
+			hash, key, _ = validator.validateLine(line, lineNum)
+		} else {
+			_, _, hash, key, _ = validator.validateHeaderlessLine(line, lineNum)
+		}
+
+		if _, ok := r.removed[key]; ok && hash != "" {
+			skipping = true
+			continue
+		}
+		actualHash, ok := r.updated[key]
+		if !ok || hash == "" {
+			b.WriteString(piece)
+			continue
+		}
+
+		// 更新哈希值, 并重新生成元数据和分块哈希记录
+		newHash, tail, err := r.refreshEntry(c, r.hashMap[key].RealPath, actualHash)
+		if err != nil {
+			return nil, fmt.Errorf("刷新文件 %s 的记录失败: %w", key, err)
+		}
+		b.WriteString(replaceDigest(line, hash, newHash))
+		b.WriteString(piece[len(line):])
+		if native {
+			b.WriteString(tail)
+			skipping = true
+		}
+	}
+
+	// 追加未记录的新文件
+	if len(r.added) > 0 {
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString("\n")
+		}
+		sort.Strings(r.added)
+		for _, realPath := range r.added {
+			newHash, tail, err := r.refreshEntry(c, realPath, "")
+			if err != nil {
+				return nil, fmt.Errorf("计算新文件 %s 的哈希值失败: %w", realPath, err)
+			}
+			b.WriteString(types.FormatChecksumLine(r.header.Format, digest.Tag(r.header.HashType), newHash, r.entryPath(realPath)))
+			if native {
+				b.WriteString(tail)
+			}
+		}
+	}
+
+	return []byte(b.String()), nil
+}
+
+// refreshEntry 按校验文件的选项获取文件的哈希值, 并生成紧跟在记录之后的元数据和分块哈希记录
+//
+// 参数:
+//   - c: 校验器
+//   - realPath: 文件的真实路径
+//   - actualHash: 校验时计算的哈希值(为空时重新计算)
+//
+// 返回:
+//   - string: 文件的哈希值
+//   - string: 元数据和分块哈希记录(未记录元数据和分块哈希值时为空)
+//   - error: 计算失败时返回错误
+//
+// 注意:
+//   - 软链接的处理方式与校验时一致, 压缩包内的条目直接使用校验时计算的哈希值
+func (r *manifestRefresh) refreshEntry(c *fileChecker, realPath, actualHash string) (string, string, error) {
+	if _, _, ok := archive.Split(realPath); ok {
+		return actualHash, "", nil
+	}
+
+	info, err := os.Lstat(realPath)
+	if err != nil {
+		return "", "", err
+	}
+	linked := info.Mode()&fs.ModeSymlink != 0 && (c.symlinks == types.SymlinksLink || c.metadata != nil)
+
+	hash := actualHash
+	var (
+		chunks []string
+		size   int64
+	)
+	switch {
+	case linked && hash == "":
+		sums, err := digest.ChecksumLink(realPath, []string{c.hashType})
+		if err != nil {
+			return "", "", err
+		}
+		hash = sums[0]
+	case !linked && c.chunkSize > 0 && !info.IsDir():
+		// 分块哈希值需要重新读取文件
+		sums, chunkSums, n, err := digest.ChecksumChunks(realPath, []string{c.hashType}, c.chunkSize)
+		if err != nil {
+			return "", "", err
+		}
+		hash, chunks, size = sums[0], chunkSums[0], n
+	case hash == "":
+		if hash, err = c.checksum(realPath); err != nil {
+			return "", "", err
+		}
+	}
+
+	var tail strings.Builder
+	if c.metadata != nil {
+		attrs, err := metadata.Collect(realPath, c.metadata)
+		if err != nil {
+			return "", "", err
+		}
+		tail.WriteString(types.FormatMetadataLine(attrs.Encode()))
+	}
+	for index, chunk := range chunks {
+		start := int64(index) * c.chunkSize
+		end := min(start+c.chunkSize, size) - 1
+		tail.WriteString(types.FormatChunkLine(start, end, chunk))
+	}
+
+	return hash, tail.String(), nil
+}
+
+// entryPath 获取新文件写入校验文件的记录路径
+//
+// 参数:
+//   - realPath: 文件的真实路径
+//
+// 返回:
+//   - string: 相对于基准目录的路径, 无法计算相对路径时返回真实路径
+func (r *manifestRefresh) entryPath(realPath string) string {
+	rel, err := filepath.Rel(absPath(r.baseDir), absPath(realPath))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return realPath
+	}
+	return rel
+}
+
+// isPreambleLine 是否为紧跟在文件头之后的扩展记录(分块大小、元数据字段或软链接策略)
+func isPreambleLine(line string) bool {
+	if _, ok := types.ParseChunkSizeLine(line); ok {
+		return true
+	}
+	if _, ok := types.ParseMetadataFieldsLine(line); ok {
+		return true
+	}
+	_, ok := types.ParseSymlinksLine(line)
+	return ok
+}
+
+// isEntryTailLine 是否为紧跟在文件记录之后的文件状态、元数据或分块哈希记录
+//
+// 注意:
+//   - 更新的记录不重新生成文件状态记录, 下次 hash --update 时该文件会重新计算
+func isEntryTailLine(line string) bool {
+	if _, _, ok := types.ParseStatLine(line); ok {
+		return true
+	}
+	if _, ok := types.ParseMetadataLine(line); ok {
+		return true
+	}
+	_, _, _, ok := types.ParseChunkLine(line)
+	return ok
+}
+
+// replaceDigest 替换记录行中的哈希值, 保留行内其他内容
+//
+// 参数:
+//   - line: 记录行
+//   - oldHash: 原哈希值
+//   - newHash: 新哈希值
+//
+// 返回:
+//   - string: 替换后的记录行
+//
+// 注意:
+//   - BSD 格式的哈希值位于行尾, 其他格式位于行首; 原哈希值可能为大写
+func replaceDigest(line, oldHash, newHash string) string {
+	index := strings.Index
+	if types.BSDLineRegex.MatchString(strings.TrimRight(line, "\r")) {
+		index = strings.LastIndex
+	}
+
+	for _, hash := range []string{oldHash, strings.ToLower(oldHash), strings.ToUpper(oldHash)} {
+		if i := index(line, hash); i >= 0 {
+			return line[:i] + newHash + line[i+len(hash):]
+		}
+	}
+	return line
+}
@@ -0,0 +1,114 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+)
+
+func TestReplaceDigest(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		oldHash string
+		want    string
+	}{
+		{name: "fck格式", line: "0cc175b9c0f1b6a831c399e269772661\t\"a.txt\"", oldHash: "0cc175b9c0f1b6a831c399e269772661", want: "ffff\t\"a.txt\""},
+		{name: "GNU格式大写", line: "0CC175B9C0F1B6A831C399E269772661  a.txt", oldHash: "0CC175B9C0F1B6A831C399E269772661", want: "ffff  a.txt"},
+		{name: "GNU格式转义", line: `\0cc175b9c0f1b6a831c399e269772661  a\\b`, oldHash: "0cc175b9c0f1b6a831c399e269772661", want: `\ffff  a\\b`},
+		{name: "BSD格式路径包含哈希值", line: "MD5 (0cc175b9c0f1b6a831c399e269772661) = 0cc175b9c0f1b6a831c399e269772661", oldHash: "0cc175b9c0f1b6a831c399e269772661", want: "MD5 (0cc175b9c0f1b6a831c399e269772661) = ffff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replaceDigest(tt.line, tt.oldHash, "ffff"); got != tt.want {
+				t.Errorf("replaceDigest() = %q, 期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	checker := newFileChecker(colorlib.New(), "md5")
+	for input, want := range map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "\n": false, "": false} {
+		if got := confirm(checker, strings.NewReader(input), "确认? "); got != want {
+			t.Errorf("confirm(%q) = %v, 期望 %v", input, got, want)
+		}
+	}
+}
+
+func TestManifestRefresh(t *testing.T) {
+	// 初始化命令标志
+	InitCheckCmd()
+
+	tempDir := t.TempDir()
+	writeTestFiles(t, tempDir, map[string]string{
+		"a.txt":     "changed",
+		"sub/b.txt": "b",
+		"new.txt":   "a",
+	})
+
+	checkFile := filepath.Join(tempDir, "checksum.hash")
+	content := "#md5#2024-01-01 10:00:00#PORTABLE\n" +
+		"# 注释行\n" +
+		"0cc175b9c0f1b6a831c399e269772661\t\"a.txt\"\n" + // md5("a")
+		"92eb5ffee6ae2fec3ad71c777531578f\t\"missing.txt\"\n" + // md5("b")
+		"92eb5ffee6ae2fec3ad71c777531578f\t\"sub/b.txt\"\n" // md5("b")
+	writeTestFiles(t, tempDir, map[string]string{"checksum.hash": content})
+
+	cl := colorlib.New()
+	parser := newHashFileParser(cl)
+	parser.baseDir = tempDir
+	hashMap, hashType, err := parser.parseFile(checkFile, "")
+	if err != nil {
+		t.Fatalf("解析校验文件失败: %v", err)
+	}
+
+	checker := newFileChecker(cl, hashType)
+	checker.strictDir = tempDir
+	checker.strictSkip = []string{checkFile}
+	checker.refresh = newManifestRefresh(checkFile, []byte(content), parser.header, hashMap, tempDir)
+
+	if err := checker.checkFiles(hashMap); exitcode.Code(err) != exitcode.Failure {
+		t.Fatalf("checkFiles() 期望返回校验失败错误, 实际: %v", err)
+	}
+
+	// 未确认时不修改校验文件
+	if refreshed, err := checker.refresh.run(checker, false, strings.NewReader("n\n")); err != nil || refreshed {
+		t.Fatalf("run() = %v, %v, 期望未更新", refreshed, err)
+	}
+	if data, _ := os.ReadFile(checkFile); string(data) != content {
+		t.Fatalf("取消后校验文件被修改:\n%s", data)
+	}
+
+	if refreshed, err := checker.refresh.run(checker, true, nil); err != nil || !refreshed {
+		t.Fatalf("run() = %v, %v, 期望已更新", refreshed, err)
+	}
+
+	// 保留文件头、注释和记录顺序, 更新不匹配的记录, 删除不存在的记录, 追加新文件
+	want := "#md5#2024-01-01 10:00:00#PORTABLE\n" +
+		"# 注释行\n" +
+		"8977dfac2f8e04cb96e66882235f5aba\t\"a.txt\"\n" + // md5("changed")
+		"92eb5ffee6ae2fec3ad71c777531578f\t\"sub/b.txt\"\n" +
+		"0cc175b9c0f1b6a831c399e269772661\t\"new.txt\"\n"
+	data, err := os.ReadFile(checkFile)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+	if string(data) != want {
+		t.Errorf("刷新后的校验文件:\n%s\n期望:\n%s", data, want)
+	}
+
+	// 刷新后的校验文件全部通过
+	hashMap, _, err = parser.parseFile(checkFile, "")
+	if err != nil {
+		t.Fatalf("解析刷新后的校验文件失败: %v", err)
+	}
+	if err := newFileChecker(cl, hashType).checkFiles(hashMap); err != nil {
+		t.Errorf("刷新后的校验文件校验失败: %v", err)
+	}
+}
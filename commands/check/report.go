@@ -0,0 +1,331 @@
+// Package check 实现了校验报告的生成。
+// 该文件负责 --report 模式: 记录每个文件的校验状态、期望与实际哈希值、文件大小和耗时, 连同汇总信息写入 JSON、JUnit XML 或独立的 HTML 报告文件。
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+
+	"gitee.com/MM-Q/fck/commands/internal/common"
+)
+
+// 报告格式
+const (
+	reportFormatJSON  = "json"  // JSON 对象
+	reportFormatJUnit = "junit" // JUnit XML, 每个文件为一个测试用例
+	reportFormatHTML  = "html"  // 独立的 HTML 页面
+)
+
+// supportedReportFormats 支持的报告格式
+var supportedReportFormats = []string{reportFormatJSON, reportFormatJUnit, reportFormatHTML}
+
+// reportFile 报告中单个文件的校验记录
+type reportFile struct {
+	checkRecord
+	Size     int64   `json:"size"`     // 文件大小(字节)
+	Duration float64 `json:"duration"` // 校验耗时(秒)
+}
+
+// checkReport 校验报告
+type checkReport struct {
+	path      string       // 报告文件路径
+	format    string       // 报告格式
+	checkFile string       // 校验文件路径
+	hashType  string       // 哈希算法
+	started   time.Time    // 开始校验的时间
+	files     []reportFile // 逐文件校验记录
+}
+
+// newCheckReport 创建校验报告
+//
+// 参数:
+//   - path: 报告文件路径
+//   - format: 报告格式(json/junit/html)
+//   - checkFile: 校验文件路径
+//   - hashType: 哈希算法
+//
+// 返回:
+//   - *checkReport: 校验报告
+func newCheckReport(path, format, checkFile, hashType string) *checkReport {
+	return &checkReport{
+		path:      path,
+		format:    format,
+		checkFile: checkFile,
+		hashType:  hashType,
+		started:   time.Now(),
+	}
+}
+
+// add 添加一个文件的校验结果
+//
+// 参数:
+//   - result: 校验结果
+//   - status: 校验状态
+func (r *checkReport) add(result checkResult, status string) {
+	r.files = append(r.files, reportFile{
+		checkRecord: newCheckRecord(result, status),
+		Size:        result.size,
+		Duration:    result.duration.Seconds(),
+	})
+}
+
+// write 按报告格式生成报告并写入文件
+//
+// 参数:
+//   - summary: 校验结果汇总
+//
+// 返回:
+//   - error: 生成或写入报告失败时返回错误
+//
+// 注意:
+//   - 文件按路径排序, 报告内容与并发校验的完成顺序无关
+func (r *checkReport) write(summary checkSummary) error {
+	sort.Slice(r.files, func(i, j int) bool { return r.files[i].Path < r.files[j].Path })
+	elapsed := time.Since(r.started)
+
+	var (
+		data []byte
+		err  error
+	)
+	switch r.format {
+	case reportFormatJUnit:
+		data, err = r.junitReport(summary, elapsed)
+	case reportFormatHTML:
+		data, err = r.htmlReport(summary, elapsed)
+	default:
+		data, err = r.jsonReport(summary, elapsed)
+	}
+	if err != nil {
+		return fmt.Errorf("生成校验报告失败: %w", err)
+	}
+
+	if err := common.WriteFileAtomic(r.path, data, 0644); err != nil {
+		return fmt.Errorf("写入校验报告 %s 失败: %w", r.path, err)
+	}
+	return nil
+}
+
+// jsonReport 生成 JSON 格式的报告
+func (r *checkReport) jsonReport(summary checkSummary, elapsed time.Duration) ([]byte, error) {
+	report := struct {
+		ChecksumFile string       `json:"checksum_file"` // 校验文件路径
+		Algorithm    string       `json:"algorithm"`     // 哈希算法
+		Timestamp    string       `json:"timestamp"`     // 开始校验的时间
+		Duration     float64      `json:"duration"`      // 总耗时(秒)
+		Summary      checkSummary `json:"summary"`       // 汇总信息
+		Files        []reportFile `json:"files"`         // 逐文件校验记录
+	}{
+		ChecksumFile: r.checkFile,
+		Algorithm:    r.hashType,
+		Timestamp:    r.started.Format(time.RFC3339),
+		Duration:     elapsed.Seconds(),
+		Summary:      summary,
+		Files:        r.files,
+	}
+	if report.Files == nil {
+		report.Files = []reportFile{}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// junitTestSuites JUnit XML 根元素
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite JUnit XML 测试套件(对应一个校验文件)
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+// junitProperty JUnit XML 测试套件属性
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// junitTestCase JUnit XML 测试用例(对应一个文件)
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitMessage JUnit XML 失败或错误信息
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitReport 生成 JUnit XML 格式的报告
+//
+// 注意:
+//   - 哈希不匹配、文件不存在和未记录的文件为 failure, 无法校验的文件为 error
+func (r *checkReport) junitReport(summary checkSummary, elapsed time.Duration) ([]byte, error) {
+	suite := junitTestSuite{
+		Name:      r.checkFile,
+		Tests:     len(r.files),
+		Failures:  summary.Mismatched + summary.Missing + summary.Unexpected,
+		Errors:    summary.Errors,
+		Time:      formatSeconds(elapsed.Seconds()),
+		Timestamp: r.started.Format("2006-01-02T15:04:05"),
+		Properties: []junitProperty{
+			{Name: "algorithm", Value: r.hashType},
+			{Name: "total", Value: fmt.Sprint(summary.Total)},
+		},
+	}
+
+	for _, file := range r.files {
+		tc := junitTestCase{
+			Name:      file.Path,
+			ClassName: "fck.check",
+			Time:      formatSeconds(file.Duration),
+			SystemOut: fmt.Sprintf("size: %d\nexpected: %s\nactual: %s\n", file.Size, file.Expected, file.Actual),
+		}
+		message := reportMessage(file)
+		switch file.Status {
+		case statusError:
+			tc.Error = &junitMessage{Message: message, Type: file.Status, Text: message}
+		case statusMismatch, statusMissing, statusUnexpected:
+			tc.Failure = &junitMessage{Message: message, Type: file.Status, Text: message}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	suites := junitTestSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// reportHTML HTML 报告模板
+var reportHTML = template.Must(template.New("report").Funcs(template.FuncMap{
+	"seconds": formatSeconds,
+	"message": reportMessage,
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>fck 校验报告 - {{.ChecksumFile}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "Microsoft YaHei", sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; font-size: 13px; }
+th { background: #f4f4f4; }
+td.hash { font-family: monospace; word-break: break-all; }
+td.num { text-align: right; }
+.ok { color: #1a7f37; } .mismatch, .unexpected, .error { color: #cf222e; } .missing { color: #9a6700; }
+</style>
+</head>
+<body>
+<h1>fck 校验报告</h1>
+<table>
+<tr><th>校验文件</th><td>{{.ChecksumFile}}</td></tr>
+<tr><th>哈希算法</th><td>{{.Algorithm}}</td></tr>
+<tr><th>开始时间</th><td>{{.Timestamp}}</td></tr>
+<tr><th>总耗时</th><td>{{seconds .Duration}} 秒</td></tr>
+</table>
+<h2>汇总</h2>
+<table>
+<tr><th>通过</th><th>校验失败</th><th>文件不存在</th><th>错误</th><th>未记录的文件</th><th>总计</th></tr>
+<tr><td class="num ok">{{.Summary.Passed}}</td><td class="num mismatch">{{.Summary.Mismatched}}</td><td class="num missing">{{.Summary.Missing}}</td><td class="num error">{{.Summary.Errors}}</td><td class="num unexpected">{{.Summary.Unexpected}}</td><td class="num">{{.Summary.Total}}</td></tr>
+</table>
+<h2>文件</h2>
+<table>
+<tr><th>路径</th><th>状态</th><th>期望的哈希值</th><th>实际的哈希值</th><th>大小(字节)</th><th>耗时(秒)</th><th>说明</th></tr>
+{{range .Files}}<tr><td>{{.Path}}</td><td class="{{.Status}}">{{.Status}}</td><td class="hash">{{.Expected}}</td><td class="hash">{{.Actual}}</td><td class="num">{{.Size}}</td><td class="num">{{seconds .Duration}}</td><td>{{message .}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// htmlReport 生成独立的 HTML 报告(不依赖外部资源)
+func (r *checkReport) htmlReport(summary checkSummary, elapsed time.Duration) ([]byte, error) {
+	var buf bytes.Buffer
+	err := reportHTML.Execute(&buf, map[string]any{
+		"ChecksumFile": r.checkFile,
+		"Algorithm":    r.hashType,
+		"Timestamp":    r.started.Format("2006-01-02 15:04:05"),
+		"Duration":     elapsed.Seconds(),
+		"Summary":      summary,
+		"Files":        r.files,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// reportMessage 生成校验记录的说明文字
+//
+// 参数:
+//   - file: 校验记录
+//
+// 返回:
+//   - string: 校验通过时为空
+func reportMessage(file reportFile) string {
+	switch file.Status {
+	case statusOK:
+		return ""
+	case statusUnexpected:
+		return "校验文件中没有该文件的记录"
+	case statusMismatch:
+		switch {
+		case len(file.Drift) > 0 && file.Actual == file.Expected:
+			return "元数据不一致: " + strings.Join(file.Drift, ", ")
+		case len(file.Drift) > 0:
+			return "哈希不匹配, 元数据不一致: " + strings.Join(file.Drift, ", ")
+		case len(file.Ranges) > 0:
+			return "哈希不匹配, 不一致的字节范围: " + strings.Join(file.Ranges, ", ")
+		default:
+			return "哈希不匹配, 文件可能已经被篡改"
+		}
+	default:
+		return file.Error
+	}
+}
+
+// formatSeconds 将秒数格式化为保留三位小数的字符串
+func formatSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
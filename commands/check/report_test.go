@@ -0,0 +1,118 @@
+package check
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestReport 创建包含各种校验状态的测试报告
+func newTestReport(t *testing.T, format string) (*checkReport, checkSummary) {
+	t.Helper()
+
+	report := newCheckReport(filepath.Join(t.TempDir(), "report."+format), format, "checksum.hash", "md5")
+	report.add(checkResult{filePath: "b.txt", expectedHash: "h2", actualHash: "x", size: 20, duration: 2 * time.Millisecond}, statusMismatch)
+	report.add(checkResult{filePath: "a.txt", expectedHash: "h1", actualHash: "h1", size: 10, duration: time.Millisecond}, statusOK)
+	report.add(checkResult{filePath: "c<d>.txt", expectedHash: "h3", err: errors.New("权限不足")}, statusError)
+	report.add(checkResult{filePath: "extra.txt", unexpected: true, size: 5}, statusUnexpected)
+
+	return report, checkSummary{Passed: 1, Mismatched: 1, Errors: 1, Unexpected: 1, Total: 3}
+}
+
+func TestCheckReport_JSON(t *testing.T) {
+	report, summary := newTestReport(t, reportFormatJSON)
+	if err := report.write(summary); err != nil {
+		t.Fatalf("write() 返回错误: %v", err)
+	}
+
+	data, err := os.ReadFile(report.path)
+	if err != nil {
+		t.Fatalf("读取报告失败: %v", err)
+	}
+	var got struct {
+		ChecksumFile string       `json:"checksum_file"`
+		Algorithm    string       `json:"algorithm"`
+		Summary      checkSummary `json:"summary"`
+		Files        []reportFile `json:"files"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("解析JSON报告失败: %v\n%s", err, data)
+	}
+
+	if got.ChecksumFile != "checksum.hash" || got.Algorithm != "md5" || got.Summary != summary {
+		t.Errorf("报告头信息错误: %+v", got)
+	}
+	if len(got.Files) != 4 {
+		t.Fatalf("期望 4 条记录, 实际 %d 条", len(got.Files))
+	}
+	// 按路径排序
+	first := got.Files[0]
+	if first.Path != "a.txt" || first.Status != statusOK || first.Size != 10 || first.Duration != 0.001 {
+		t.Errorf("第一条记录错误: %+v", first)
+	}
+}
+
+func TestCheckReport_JUnit(t *testing.T) {
+	report, summary := newTestReport(t, reportFormatJUnit)
+	if err := report.write(summary); err != nil {
+		t.Fatalf("write() 返回错误: %v", err)
+	}
+
+	data, err := os.ReadFile(report.path)
+	if err != nil {
+		t.Fatalf("读取报告失败: %v", err)
+	}
+	var got junitTestSuites
+	if err := xml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("解析JUnit报告失败: %v\n%s", err, data)
+	}
+
+	if got.Tests != 4 || got.Failures != 2 || got.Errors != 1 || len(got.Suites) != 1 {
+		t.Fatalf("测试统计错误: tests=%d failures=%d errors=%d", got.Tests, got.Failures, got.Errors)
+	}
+	for _, tc := range got.Suites[0].Cases {
+		switch tc.Name {
+		case "a.txt":
+			if tc.Failure != nil || tc.Error != nil {
+				t.Errorf("%s 不应失败", tc.Name)
+			}
+		case "b.txt", "extra.txt":
+			if tc.Failure == nil {
+				t.Errorf("%s 应为 failure", tc.Name)
+			}
+		case "c<d>.txt":
+			if tc.Error == nil || tc.Error.Message != "权限不足" {
+				t.Errorf("%s 应为 error: %+v", tc.Name, tc.Error)
+			}
+		default:
+			t.Errorf("意外的测试用例: %s", tc.Name)
+		}
+	}
+}
+
+func TestCheckReport_HTML(t *testing.T) {
+	report, summary := newTestReport(t, reportFormatHTML)
+	if err := report.write(summary); err != nil {
+		t.Fatalf("write() 返回错误: %v", err)
+	}
+
+	data, err := os.ReadFile(report.path)
+	if err != nil {
+		t.Fatalf("读取报告失败: %v", err)
+	}
+	html := string(data)
+
+	for _, want := range []string{"<!DOCTYPE html>", "checksum.hash", "a.txt", "c&lt;d&gt;.txt", "哈希不匹配", "校验文件中没有该文件的记录"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML报告缺少 %q", want)
+		}
+	}
+	if strings.Contains(html, "c<d>.txt") {
+		t.Error("HTML报告中的路径未转义")
+	}
+}
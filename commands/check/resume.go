@@ -0,0 +1,265 @@
+// Package check 实现了断点续校验。
+// 该文件记录分块校验中从文件开头起连续通过校验的分块, 校验中断后使用 --resume 再次校验时跳过这些分块,
+// 从上次中断处继续读取文件。
+package check
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/MM-Q/fck/commands/internal/common"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// resumeSuffix 续校验状态文件相对于校验文件的后缀
+const resumeSuffix = ".resume"
+
+// resumeHeaderPrefix 续校验状态文件头的前缀(后接分块大小)
+const resumeHeaderPrefix = "#resume#"
+
+// resumeSaveInterval 校验过程中保存续校验状态的间隔, 进程被强制结束时最多丢失该间隔内的进度
+const resumeSaveInterval = 10 * time.Second
+
+// resumeFileName 获取校验文件对应的续校验状态文件路径
+//
+// 参数:
+//   - checkFile: 校验文件路径
+//
+// 返回:
+//   - string: 续校验状态文件路径(<校验文件>.resume)
+func resumeFileName(checkFile string) string {
+	return checkFile + resumeSuffix
+}
+
+// resumeEntry 单个文件的续校验进度
+type resumeEntry struct {
+	verified int    // 从文件开头起连续通过校验的分块数
+	size     int64  // 校验时的文件大小
+	modTime  int64  // 校验时的修改时间(Unix纳秒)
+	hash     string // 校验文件中记录的整体哈希值(校验文件重新生成后进度失效)
+}
+
+// resumeState 续校验状态
+type resumeState struct {
+	path      string                 // 状态文件路径
+	chunkSize int64                  // 分块大小
+	entries   map[string]resumeEntry // 文件绝对路径 -> 校验进度
+	mu        sync.Mutex             // 保护 entries 和 saved
+	saved     time.Time              // 上次保存状态的时间
+}
+
+// loadResumeState 读取续校验状态
+//
+// 参数:
+//   - path: 状态文件路径
+//   - chunkSize: 校验文件记录的分块大小
+//
+// 返回:
+//   - *resumeState: 续校验状态(状态文件不存在或分块大小不一致时没有任何进度)
+//   - error: 读取失败时返回错误
+//
+// 注意:
+//   - 无法解析的行直接忽略, 对应的文件从头开始校验
+func loadResumeState(path string, chunkSize int64) (*resumeState, error) {
+	s := &resumeState{
+		path:      path,
+		chunkSize: chunkSize,
+		entries:   make(map[string]resumeEntry),
+		saved:     time.Now(),
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取续校验状态文件失败: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	// 分块大小不一致时校验文件已重新生成, 原有进度全部失效
+	if !scanner.Scan() || scanner.Text() != resumeHeaderPrefix+strconv.FormatInt(chunkSize, 10) {
+		return s, nil
+	}
+
+	for scanner.Scan() {
+		// 记录格式: <已校验分块数> <文件大小> <修改时间> <整体哈希值> <路径>
+		fields := strings.SplitN(scanner.Text(), " ", 5)
+		if len(fields) != 5 {
+			continue
+		}
+		verified, err1 := strconv.Atoi(fields[0])
+		size, err2 := strconv.ParseInt(fields[1], 10, 64)
+		modTime, err3 := strconv.ParseInt(fields[2], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil || verified < 0 {
+			continue
+		}
+		s.entries[fields[4]] = resumeEntry{verified: verified, size: size, modTime: modTime, hash: fields[3]}
+	}
+
+	return s, nil
+}
+
+// start 获取文件可以跳过的分块数
+//
+// 参数:
+//   - filePath: 文件路径
+//   - hash: 校验文件中记录的整体哈希值
+//   - info: 校验前的文件状态
+//   - chunks: 校验文件中记录的分块数
+//
+// 返回:
+//   - int: 可以跳过的分块数, 文件大小、修改时间或记录的哈希值发生变化时为0
+func (s *resumeState) start(filePath, hash string, info os.FileInfo, chunks int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[absPath(filePath)]
+	if !ok || entry.hash != hash || entry.size != info.Size() || entry.modTime != info.ModTime().UnixNano() {
+		return 0
+	}
+	return min(entry.verified, chunks)
+}
+
+// record 记录文件从开头起连续通过校验的分块数, 距上次保存超过 resumeSaveInterval 时保存状态
+//
+// 参数:
+//   - filePath: 文件路径
+//   - hash: 校验文件中记录的整体哈希值
+//   - info: 校验前的文件状态(校验期间文件被修改时下次续校验会从头开始)
+//   - verified: 已通过校验的分块数
+//
+// 返回:
+//   - error: 定期保存状态失败时返回错误
+func (s *resumeState) record(filePath, hash string, info os.FileInfo, verified int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[absPath(filePath)] = resumeEntry{
+		verified: verified,
+		size:     info.Size(),
+		modTime:  info.ModTime().UnixNano(),
+		hash:     hash,
+	}
+
+	if time.Since(s.saved) < resumeSaveInterval {
+		return nil
+	}
+	return s.saveLocked()
+}
+
+// save 原子地写入续校验状态文件
+//
+// 返回:
+//   - error: 写入失败时返回错误
+func (s *resumeState) save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saveLocked()
+}
+
+// saveLocked 原子地写入续校验状态文件(调用方需持有锁)
+func (s *resumeState) saveLocked() error {
+	s.saved = time.Now()
+
+	paths := make([]string, 0, len(s.entries))
+	for path := range s.entries {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	var b strings.Builder
+	b.WriteString(resumeHeaderPrefix + strconv.FormatInt(s.chunkSize, 10) + "\n")
+	for _, path := range paths {
+		e := s.entries[path]
+		fmt.Fprintf(&b, "%d %d %d %s %s\n", e.verified, e.size, e.modTime, e.hash, path)
+	}
+
+	if err := common.WriteFileAtomic(s.path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("保存续校验状态失败: %w", err)
+	}
+	return nil
+}
+
+// remove 删除续校验状态文件(全部记录校验完成后不再需要)
+//
+// 返回:
+//   - error: 删除失败时返回错误
+func (s *resumeState) remove() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("删除续校验状态文件失败: %w", err)
+	}
+	return nil
+}
+
+// resumeChunks 逐块校验记录了分块哈希值的文件, 跳过上次已通过校验的分块
+//
+// 参数:
+//   - entry: 校验文件中的记录(包含分块哈希值)
+//   - info: 校验前的文件状态
+//   - result: 校验结果
+//
+// 注意:
+//   - 不计算整体哈希值: 全部分块一致且文件大小不变时视为通过, 否则报告不一致的字节范围, 实际哈希值为空
+func (c *fileChecker) resumeChunks(entry types.VirtualHashEntry, info os.FileInfo, result *checkResult) {
+	first := c.resume.start(entry.RealPath, entry.Hash, info, len(entry.Chunks))
+
+	// 跳过的分块已通过校验, 与记录的分块哈希值相同
+	actual := slices.Clone(entry.Chunks[:first])
+	mismatched := false
+	var saveErr error
+	size, err := digest.ChecksumChunksFrom(entry.RealPath, c.hashType, c.chunkSize, first, func(index int, sum string) {
+		actual = append(actual, sum)
+		if mismatched || index >= len(entry.Chunks) || entry.Chunks[index] != sum {
+			mismatched = true
+			return
+		}
+		if err := c.resume.record(entry.RealPath, entry.Hash, info, index+1); err != nil && saveErr == nil {
+			saveErr = err
+		}
+	})
+	if err != nil {
+		result.err = fmt.Errorf("计算文件哈希失败: %v", err)
+		return
+	}
+	if saveErr != nil {
+		c.cl.PrintWarnf("%v\n", saveErr)
+	}
+
+	result.ranges = mismatchedRanges(entry.Chunks, actual, c.chunkSize, max(entry.ChunkedSize, size))
+	if len(result.ranges) == 0 {
+		result.actualHash = result.expectedHash
+	}
+}
+
+// finishResume 校验结束后保存或删除续校验状态
+//
+// 注意:
+//   - 校验被中断或因 --fail-fast 提前停止时保存进度, 全部记录校验完成后删除状态文件
+//   - 状态文件写入失败只输出错误, 不影响校验结果的退出码
+func (c *fileChecker) finishResume() {
+	if c.ctx.Err() == nil {
+		if err := c.resume.remove(); err != nil {
+			c.cl.PrintErrorf("%v\n", err)
+		}
+		return
+	}
+
+	if err := c.resume.save(); err != nil {
+		c.cl.PrintErrorf("%v\n", err)
+		return
+	}
+	c.cl.PrintInfof("校验进度已保存到 %s, 再次使用 --resume 校验时跳过已通过校验的分块\n", c.resume.path)
+}
@@ -0,0 +1,203 @@
+package check
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// newResumeEntry 创建记录了分块哈希值的测试文件并返回对应的校验记录
+func newResumeEntry(t *testing.T, path string, content string, chunkSize int64) types.VirtualHashEntry {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	sums, chunks, size, err := digest.ChecksumChunks(path, []string{"md5"}, chunkSize)
+	if err != nil {
+		t.Fatalf("计算分块哈希失败: %v", err)
+	}
+	return types.VirtualHashEntry{RealPath: path, Hash: sums[0], Chunks: chunks[0], ChunkedSize: size}
+}
+
+// TestResumeState 测试续校验状态的保存和读取
+func TestResumeState(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "a b.txt")
+	if err := os.WriteFile(testFile, []byte("0123456789"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	info, err := os.Stat(testFile)
+	if err != nil {
+		t.Fatalf("获取文件信息失败: %v", err)
+	}
+
+	statePath := resumeFileName(filepath.Join(tempDir, types.OutputFileName))
+	state, err := loadResumeState(statePath, 4)
+	if err != nil {
+		t.Fatalf("loadResumeState() 返回错误: %v", err)
+	}
+	if err := state.record(testFile, "h", info, 2); err != nil {
+		t.Fatalf("record() 返回错误: %v", err)
+	}
+	if err := state.save(); err != nil {
+		t.Fatalf("save() 返回错误: %v", err)
+	}
+
+	// 重新读取后恢复进度, 路径中的空格保持不变
+	loaded, err := loadResumeState(statePath, 4)
+	if err != nil {
+		t.Fatalf("loadResumeState() 返回错误: %v", err)
+	}
+	if got := loaded.start(testFile, "h", info, 3); got != 2 {
+		t.Errorf("start() = %d, 期望 2", got)
+	}
+	if got := loaded.start(testFile, "h", info, 1); got != 1 {
+		t.Errorf("分块数少于进度时 start() = %d, 期望 1", got)
+	}
+
+	// 校验文件记录的哈希值变化时进度失效
+	if got := loaded.start(testFile, "other", info, 3); got != 0 {
+		t.Errorf("哈希值变化后 start() = %d, 期望 0", got)
+	}
+
+	// 文件被修改后进度失效
+	if err := os.Chtimes(testFile, time.Now(), info.ModTime().Add(time.Second)); err != nil {
+		t.Fatalf("修改文件时间失败: %v", err)
+	}
+	modified, _ := os.Stat(testFile)
+	if got := loaded.start(testFile, "h", modified, 3); got != 0 {
+		t.Errorf("文件修改后 start() = %d, 期望 0", got)
+	}
+
+	// 分块大小不一致时没有任何进度
+	other, err := loadResumeState(statePath, 8)
+	if err != nil {
+		t.Fatalf("loadResumeState() 返回错误: %v", err)
+	}
+	if got := other.start(testFile, "h", info, 3); got != 0 {
+		t.Errorf("分块大小变化后 start() = %d, 期望 0", got)
+	}
+
+	// 删除状态文件
+	if err := loaded.remove(); err != nil {
+		t.Fatalf("remove() 返回错误: %v", err)
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Errorf("remove() 后状态文件仍然存在: %v", err)
+	}
+}
+
+// TestFileChecker_ResumeChunks 测试续校验跳过已通过校验的分块
+func TestFileChecker_ResumeChunks(t *testing.T) {
+	// 初始化命令标志
+	InitCheckCmd()
+
+	const chunkSize = 4
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "image.bin")
+	entry := newResumeEntry(t, testFile, "0123456789", chunkSize)
+	info, err := os.Stat(testFile)
+	if err != nil {
+		t.Fatalf("获取文件信息失败: %v", err)
+	}
+
+	state, err := loadResumeState(resumeFileName(filepath.Join(tempDir, types.OutputFileName)), chunkSize)
+	if err != nil {
+		t.Fatalf("loadResumeState() 返回错误: %v", err)
+	}
+	checker := newFileChecker(colorlib.New(), "md5")
+	checker.chunkSize = chunkSize
+	checker.resume = state
+
+	var read atomic.Int64
+	digest.SetReadCounter(&read)
+	defer digest.SetReadCounter(nil)
+
+	// 没有进度时读取全部分块并记录进度
+	result := checker.checkEntry(entry)
+	if resultStatus(result) != statusOK {
+		t.Fatalf("期望校验通过, 实际: %+v", result)
+	}
+	if read.Load() != 10 {
+		t.Errorf("期望读取 10 字节, 实际读取 %d 字节", read.Load())
+	}
+	if got := state.start(testFile, entry.Hash, info, len(entry.Chunks)); got != 3 {
+		t.Errorf("校验通过后记录的分块数 = %d, 期望 3", got)
+	}
+
+	// 只有前两个分块通过校验时从第三个分块继续读取
+	read.Store(0)
+	if err := state.record(testFile, entry.Hash, info, 2); err != nil {
+		t.Fatalf("record() 返回错误: %v", err)
+	}
+	result = checker.checkEntry(entry)
+	if resultStatus(result) != statusOK {
+		t.Fatalf("期望校验通过, 实际: %+v", result)
+	}
+	if read.Load() != 2 {
+		t.Errorf("期望只读取最后 2 字节, 实际读取 %d 字节", read.Load())
+	}
+
+	// 未校验的分块被修改时报告不一致的字节范围, 且不记录该分块
+	if err := state.record(testFile, entry.Hash, info, 2); err != nil {
+		t.Fatalf("record() 返回错误: %v", err)
+	}
+	if err := os.WriteFile(testFile, []byte("01234567xx"), 0644); err != nil {
+		t.Fatalf("修改测试文件失败: %v", err)
+	}
+	if err := os.Chtimes(testFile, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("恢复文件时间失败: %v", err)
+	}
+	result = checker.checkEntry(entry)
+	if resultStatus(result) != statusMismatch || formatRanges(result.ranges) != "8-9" {
+		t.Errorf("期望报告字节范围 8-9 不一致, 实际: %+v", result)
+	}
+	if got := state.start(testFile, entry.Hash, info, len(entry.Chunks)); got != 2 {
+		t.Errorf("不一致的分块不应记录为已通过, 记录的分块数 = %d", got)
+	}
+}
+
+// TestFileChecker_FinishResume 测试校验结束后保存或删除续校验状态
+func TestFileChecker_FinishResume(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "a.txt")
+	entry := newResumeEntry(t, testFile, "0123456789", 4)
+	info, err := os.Stat(testFile)
+	if err != nil {
+		t.Fatalf("获取文件信息失败: %v", err)
+	}
+
+	statePath := resumeFileName(filepath.Join(tempDir, types.OutputFileName))
+	state, err := loadResumeState(statePath, 4)
+	if err != nil {
+		t.Fatalf("loadResumeState() 返回错误: %v", err)
+	}
+	if err := state.record(testFile, entry.Hash, info, 1); err != nil {
+		t.Fatalf("record() 返回错误: %v", err)
+	}
+
+	// 校验被中断时保存进度
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	checker := newFileChecker(colorlib.New(), "md5")
+	checker.ctx = ctx
+	checker.resume = state
+	checker.finishResume()
+	if _, err := os.Stat(statePath); err != nil {
+		t.Fatalf("中断后期望保存状态文件: %v", err)
+	}
+
+	// 全部记录校验完成后删除状态文件
+	checker.ctx = context.Background()
+	checker.finishResume()
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Errorf("校验完成后状态文件仍然存在: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Package check 实现了严格模式下未记录文件的检查。
+// 该文件负责 --strict 模式: 遍历校验文件对应的基准目录, 将磁盘上存在但校验文件中没有记录的文件报告为 unexpected。
+package check
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	"gitee.com/MM-Q/fck/commands/internal/archive"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// strictBaseDir 获取严格模式下需要遍历的基准目录
+//
+// 参数:
+//   - header: 校验文件头信息
+//   - userBaseDir: 用户指定的基准目录
+//
+// 返回:
+//   - string: 基准目录, 与解析记录路径时使用的基准目录一致
+func strictBaseDir(header *types.ChecksumHeader, userBaseDir string) string {
+	if userBaseDir != "" {
+		return userBaseDir
+	}
+	if header.Mode == types.ChecksumModeLocal && header.BasePath != "" {
+		return header.BasePath
+	}
+	return "."
+}
+
+// findUnexpected 遍历基准目录, 发送校验文件中没有记录的文件
+//
+// 参数:
+//   - hashMap: 虚拟哈希映射表
+//   - results: 校验结果通道
+//
+// 注意:
+//   - 校验文件本身及其签名文件(strictSkip)不会被报告
+//   - 压缩包内的记录视为记录了所在的压缩包
+//   - 校验文件记录的软链接处理策略为 skip 时不报告未记录的软链接, 不进入软链接指向的目录
+//   - 无法访问的目录报告为错误
+func (c *fileChecker) findUnexpected(hashMap types.VirtualHashMap, results chan<- checkResult) {
+	// 按绝对路径比较, 兼容记录中的绝对路径和相对路径
+	known := make(map[string]struct{}, len(hashMap)+len(c.strictSkip))
+	for _, entry := range hashMap {
+		realPath := entry.RealPath
+		if archivePath, _, ok := archive.Split(realPath); ok {
+			realPath = archivePath
+		}
+		known[absPath(realPath)] = struct{}{}
+	}
+	for _, skipPath := range c.strictSkip {
+		known[absPath(skipPath)] = struct{}{}
+	}
+
+	// 未记录软链接处理策略的旧校验文件与 checkEntry 一致, 按 follow 策略处理
+	reportLinks := c.symlinks != types.SymlinksSkip
+	_ = filepath.WalkDir(c.strictDir, func(path string, d fs.DirEntry, err error) error {
+		if c.ctx.Err() != nil {
+			return filepath.SkipAll
+		}
+		if err != nil {
+			result := checkResult{filePath: path, err: err}
+			c.stopOnFailure(result)
+			results <- result
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 && !reportLinks {
+			return nil
+		}
+
+		if _, ok := known[absPath(path)]; !ok {
+			result := checkResult{filePath: path, unexpected: true}
+			if info, err := d.Info(); err == nil {
+				result.size = info.Size()
+			}
+			c.stopOnFailure(result)
+			results <- result
+		}
+		return nil
+	})
+}
+
+// absPath 获取规范化的绝对路径, 失败时返回清理后的原路径
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
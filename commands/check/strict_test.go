@@ -0,0 +1,147 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/output"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+func TestStrictBaseDir(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      types.ChecksumHeader
+		userBaseDir string
+		want        string
+	}{
+		{name: "便携模式", header: types.ChecksumHeader{Mode: types.ChecksumModePortable}, want: "."},
+		{name: "LOCAL模式", header: types.ChecksumHeader{Mode: types.ChecksumModeLocal, BasePath: "/data"}, want: "/data"},
+		{name: "LOCAL模式无基准路径", header: types.ChecksumHeader{Mode: types.ChecksumModeLocal}, want: "."},
+		{name: "用户指定基准目录", header: types.ChecksumHeader{Mode: types.ChecksumModeLocal, BasePath: "/data"}, userBaseDir: "release", want: "release"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strictBaseDir(&tt.header, tt.userBaseDir); got != tt.want {
+				t.Errorf("strictBaseDir() = %s, 期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileChecker_FindUnexpected(t *testing.T) {
+	// 初始化命令标志
+	InitCheckCmd()
+
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"a.txt":         "a",
+		"sub/b.txt":     "b",
+		"sub/extra.txt": "extra",
+		".hidden":       "hidden",
+		"checksum.hash": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("创建目录失败: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+	if err := os.Symlink("a.txt", filepath.Join(tempDir, "link")); err != nil {
+		t.Skipf("当前平台不支持创建软链接: %v", err)
+	}
+
+	hashMap := types.VirtualHashMap{
+		"a.txt":     {RealPath: filepath.Join(tempDir, "a.txt"), Hash: "0cc175b9c0f1b6a831c399e269772661"},     // md5("a")
+		"sub/b.txt": {RealPath: filepath.Join(tempDir, "sub/b.txt"), Hash: "92eb5ffee6ae2fec3ad71c777531578f"}, // md5("b")
+	}
+
+	tests := []struct {
+		name     string
+		symlinks string
+		want     map[string]string
+	}{
+		{
+			name:     "skip策略不报告软链接",
+			symlinks: types.SymlinksSkip,
+			want: map[string]string{
+				"a.txt":         statusOK,
+				"sub/b.txt":     statusOK,
+				"sub/extra.txt": statusUnexpected,
+				".hidden":       statusUnexpected,
+			},
+		},
+		{
+			name:     "未记录策略时按follow报告软链接",
+			symlinks: "",
+			want: map[string]string{
+				"a.txt":         statusOK,
+				"sub/b.txt":     statusOK,
+				"sub/extra.txt": statusUnexpected,
+				".hidden":       statusUnexpected,
+				"link":          statusUnexpected,
+			},
+		},
+		{
+			name:     "link策略报告软链接",
+			symlinks: types.SymlinksLink,
+			want: map[string]string{
+				"a.txt":         statusOK,
+				"sub/b.txt":     statusOK,
+				"sub/extra.txt": statusUnexpected,
+				".hidden":       statusUnexpected,
+				"link":          statusUnexpected,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			records, err := output.NewWriter(&buf, output.FormatJSON, checkRecordColumns)
+			if err != nil {
+				t.Fatalf("创建写入器失败: %v", err)
+			}
+			checker := newFileChecker(colorlib.New(), "md5")
+			checker.records = records
+			checker.symlinks = tt.symlinks
+			checker.strictDir = tempDir
+			checker.strictSkip = []string{filepath.Join(tempDir, "checksum.hash")}
+
+			// 未记录的文件按校验失败处理
+			if err := checker.checkFiles(hashMap); exitcode.Code(err) != exitcode.Failure {
+				t.Fatalf("checkFiles() 期望返回校验失败错误, 实际: %v", err)
+			}
+
+			var report struct {
+				Files   []checkRecord `json:"files"`
+				Summary checkSummary  `json:"summary"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+				t.Fatalf("解析JSON输出失败: %v\n%s", err, buf.String())
+			}
+
+			if len(report.Files) != len(tt.want) {
+				t.Fatalf("期望 %d 条记录, 实际 %d 条: %+v", len(tt.want), len(report.Files), report.Files)
+			}
+			for _, r := range report.Files {
+				rel, _ := filepath.Rel(tempDir, r.Path)
+				if tt.want[filepath.ToSlash(rel)] != r.Status {
+					t.Errorf("%s 状态 = %s, 期望 %s", rel, r.Status, tt.want[filepath.ToSlash(rel)])
+				}
+			}
+			if report.Summary.Unexpected != len(tt.want)-2 || report.Summary.Total != 2 {
+				t.Errorf("汇总信息错误: %+v", report.Summary)
+			}
+		})
+	}
+}
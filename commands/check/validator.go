@@ -5,22 +5,40 @@ package check
 import (
 	"fmt"
 	"path/filepath"
-	"regexp"
 	"strings"
+
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
 // hashLineValidator 校验文件行验证器
 type hashLineValidator struct {
-	hashRegex *regexp.Regexp
+	digestLens map[int]struct{} // 所有受支持算法的十六进制摘要长度
 }
 
 // newHashLineValidator 创建新的行验证器
 func newHashLineValidator() *hashLineValidator {
-	// 匹配常见哈希格式：32位(MD5)、40位(SHA1)、64位(SHA256)、128位(SHA512)
-	hashRegex := regexp.MustCompile(`^[a-fA-F0-9]{32}$|^[a-fA-F0-9]{40}$|^[a-fA-F0-9]{64}$|^[a-fA-F0-9]{128}$`)
 	return &hashLineValidator{
-		hashRegex: hashRegex,
+		digestLens: digest.HexLens(),
+	}
+}
+
+// isValidDigest 检查摘要是否为合法的十六进制字符串且长度属于某个受支持的算法
+func (v *hashLineValidator) isValidDigest(hash string) bool {
+	// 检查长度
+	if _, ok := v.digestLens[len(hash)]; !ok {
+		return false
+	}
+
+	// 检查字符
+	for i := 0; i < len(hash); i++ {
+		c := hash[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
 	}
+
+	return true
 }
 
 // validateLine 验证校验文件中的单行内容
@@ -39,7 +57,7 @@ func (v *hashLineValidator) validateLine(line string, lineNum int) (hash, filePa
 	filePath = strings.Join(parts[1:], " ")
 
 	// 验证哈希值格式
-	if !v.hashRegex.MatchString(hash) {
+	if !v.isValidDigest(hash) {
 		return "", "", fmt.Errorf("第%d行哈希值格式无效: %s", lineNum, hash)
 	}
 
@@ -54,6 +72,53 @@ func (v *hashLineValidator) validateLine(line string, lineNum int) (hash, filePa
 	return hash, filePath, nil
 }
 
+// validateHeaderlessLine 验证无文件头校验文件(GNU/BSD 格式)中的单行内容
+//
+// 参数:
+//   - line: 行内容
+//   - lineNum: 行号
+//
+// 返回值:
+//   - format: 识别出的格式(gnu/bsd), 空行和注释行为空
+//   - tag: BSD 格式的算法标签, GNU 格式为空
+//   - hash: 哈希值
+//   - filePath: 文件路径
+//   - err: 错误信息
+func (v *hashLineValidator) validateHeaderlessLine(line string, lineNum int) (format, tag, hash, filePath string, err error) {
+	// 跳过空行和注释行
+	line = strings.TrimRight(line, "\r")
+	if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+		return "", "", "", "", nil
+	}
+
+	var escaped bool
+	if m := types.BSDLineRegex.FindStringSubmatch(line); m != nil {
+		format, escaped, tag, filePath, hash = types.ChecksumFormatBSD, m[1] != "", m[2], m[3], m[4]
+	} else if m := types.GNULineRegex.FindStringSubmatch(line); m != nil {
+		format, escaped, hash, filePath = types.ChecksumFormatGNU, m[1] != "", m[2], m[3]
+	} else {
+		return "", "", "", "", fmt.Errorf("第%d行格式错误: 无法识别为GNU或BSD格式", lineNum)
+	}
+
+	// 验证哈希值格式
+	if !v.isValidDigest(hash) {
+		return "", "", "", "", fmt.Errorf("第%d行哈希值格式无效: %s", lineNum, hash)
+	}
+
+	// 还原转义的文件名并清理路径
+	if escaped {
+		filePath = types.UnescapeChecksumPath(filePath)
+	}
+	filePath = filepath.Clean(filePath)
+
+	// 验证文件路径安全性
+	if err := v.validateFilePath(filePath, lineNum); err != nil {
+		return "", "", "", "", err
+	}
+
+	return format, tag, hash, filePath, nil
+}
+
 // cleanFilePath 清理文件路径
 func (v *hashLineValidator) cleanFilePath(filePath string) string {
 	// 去除引号
@@ -40,6 +40,22 @@ func TestHashLineValidator_ValidateLine(t *testing.T) {
 			expectPath:  "file.txt",
 			expectError: false,
 		},
+		{
+			name:        "有效的CRC32C哈希行",
+			line:        "364b3fb7 file.txt",
+			lineNum:     3,
+			expectHash:  "364b3fb7",
+			expectPath:  "file.txt",
+			expectError: false,
+		},
+		{
+			name:        "有效的XXHash64哈希行",
+			line:        "44bc2cf5ad770999 file.txt",
+			lineNum:     3,
+			expectHash:  "44bc2cf5ad770999",
+			expectPath:  "file.txt",
+			expectError: false,
+		},
 		{
 			name:        "带引号的文件路径",
 			line:        `c34652066a18513105ac1ab96fcbef8e "test file.txt"`,
@@ -93,6 +109,13 @@ func TestHashLineValidator_ValidateLine(t *testing.T) {
 			expectError: true,
 			errorMsg:    "第10行哈希值格式无效: abc123",
 		},
+		{
+			name:        "格式错误-不受支持的哈希长度",
+			line:        "c34652066a18513105ac1ab96fcbef8e00 test.txt",
+			lineNum:     11,
+			expectError: true,
+			errorMsg:    "第11行哈希值格式无效: c34652066a18513105ac1ab96fcbef8e00",
+		},
 		{
 			name:        "安全错误-路径过长",
 			line:        "c34652066a18513105ac1ab96fcbef8e " + generateLongPath(5000),
@@ -252,6 +275,86 @@ func TestHashLineValidator_ValidateFilePath(t *testing.T) {
 	}
 }
 
+func TestHashLineValidator_ValidateHeaderlessLine(t *testing.T) {
+	validator := newHashLineValidator()
+
+	tests := []struct {
+		name         string
+		line         string
+		expectFormat string
+		expectTag    string
+		expectHash   string
+		expectPath   string
+		expectError  bool
+	}{
+		{
+			name:         "GNU文本模式",
+			line:         "d41d8cd98f00b204e9800998ecf8427e  file name.txt",
+			expectFormat: "gnu",
+			expectHash:   "d41d8cd98f00b204e9800998ecf8427e",
+			expectPath:   "file name.txt",
+		},
+		{
+			name:         "GNU二进制模式",
+			line:         "d41d8cd98f00b204e9800998ecf8427e *file.bin",
+			expectFormat: "gnu",
+			expectHash:   "d41d8cd98f00b204e9800998ecf8427e",
+			expectPath:   "file.bin",
+		},
+		{
+			name:         "GNU转义文件名",
+			line:         `\d41d8cd98f00b204e9800998ecf8427e  a\\b.txt`,
+			expectFormat: "gnu",
+			expectHash:   "d41d8cd98f00b204e9800998ecf8427e",
+			expectPath:   `a\b.txt`,
+		},
+		{
+			name:         "BSD标签格式",
+			line:         "SHA1 (dir/file (1).txt) = da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			expectFormat: "bsd",
+			expectTag:    "SHA1",
+			expectHash:   "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			expectPath:   "dir/file (1).txt",
+		},
+		{
+			name: "空行跳过",
+			line: "",
+		},
+		{
+			name:        "无效格式",
+			line:        "d41d8cd98f00b204e9800998ecf8427e\tfile.txt",
+			expectError: true,
+		},
+		{
+			name:        "路径遍历",
+			line:        "d41d8cd98f00b204e9800998ecf8427e  ../etc/passwd",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, tag, hash, path, err := validator.validateHeaderlessLine(tt.line, 1)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("期望错误但没有发生错误")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("不期望错误但发生了错误: %v", err)
+			}
+
+			if format != tt.expectFormat || tag != tt.expectTag || hash != tt.expectHash || path != tt.expectPath {
+				t.Errorf("解析结果不匹配，期望: (%s, %s, %s, %s), 实际: (%s, %s, %s, %s)",
+					tt.expectFormat, tt.expectTag, tt.expectHash, tt.expectPath, format, tag, hash, path)
+			}
+		})
+	}
+}
+
 // 辅助函数
 func generateLongPath(length int) string {
 	path := ""
@@ -0,0 +1,45 @@
+// Package check 实现了校验文件的签名验证。
+// 该文件负责在 --verify-key 模式下使用公钥验证校验文件的分离式签名, 未签名或签名不匹配的校验文件将被拒绝。
+package check
+
+import (
+	"fmt"
+	"os"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/signature"
+)
+
+// verifyChecksumFile 使用公钥验证校验文件的签名
+//
+// 参数:
+//   - cl: 颜色库对象
+//   - checkFile: 校验文件路径
+//   - keyPath: 公钥文件路径
+//
+// 返回:
+//   - []byte: 通过验证的校验文件内容
+//   - error: 校验文件未签名、签名不匹配或读取失败时返回错误
+//
+// 注意:
+//   - 签名文件为校验文件路径加 .sig 后缀
+//   - 后续应解析返回的内容而不是重新读取文件, 确保校验的内容与验证的内容一致
+func verifyChecksumFile(cl *colorlib.ColorLib, checkFile, keyPath string) ([]byte, error) {
+	pub, err := signature.LoadPublicKey(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取验证公钥失败: %w", err)
+	}
+
+	content, err := os.ReadFile(checkFile)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取校验文件: %v", err)
+	}
+
+	sigPath := signature.SignatureFileName(checkFile)
+	if err := signature.Verify(content, sigPath, pub); err != nil {
+		return nil, fmt.Errorf("拒绝使用校验文件 %s: %w", checkFile, err)
+	}
+
+	cl.PrintOkf("校验文件签名验证通过 (密钥 %s)\n", signature.KeyID(pub))
+	return content, nil
+}
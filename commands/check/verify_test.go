@@ -0,0 +1,150 @@
+package check
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/signature"
+)
+
+// writeTestKeyPair 生成测试密钥对, 返回私钥和公钥文件路径
+func writeTestKeyPair(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+
+	pub, priv, err := signature.GenerateKey()
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	privPath := filepath.Join(dir, name)
+	pubPath := privPath + signature.PublicKeyExt
+	if err := signature.WritePrivateKey(privPath, priv); err != nil {
+		t.Fatalf("写入私钥失败: %v", err)
+	}
+	if err := signature.WritePublicKey(pubPath, pub); err != nil {
+		t.Fatalf("写入公钥失败: %v", err)
+	}
+	return privPath, pubPath
+}
+
+// TestVerifyChecksumFile 测试校验文件签名验证
+func TestVerifyChecksumFile(t *testing.T) {
+	cl := colorlib.New()
+	tempDir := t.TempDir()
+
+	privPath, pubPath := writeTestKeyPair(t, tempDir, "key")
+	_, otherPubPath := writeTestKeyPair(t, tempDir, "other")
+	priv, err := signature.LoadPrivateKey(privPath)
+	if err != nil {
+		t.Fatalf("读取私钥失败: %v", err)
+	}
+
+	const content = "#md5#2024-01-01 10:00:00#PORTABLE\n554a4a6903bc4c5ecaadd2ff5df6c536\t\"test.txt\"\n"
+
+	tests := []struct {
+		name    string
+		prepare func(checkFile string)
+		keyPath string
+		wantErr error
+	}{
+		{
+			name:    "签名有效",
+			prepare: func(checkFile string) {},
+			keyPath: pubPath,
+		},
+		{
+			name: "校验文件被篡改",
+			prepare: func(checkFile string) {
+				_ = os.WriteFile(checkFile, []byte(content+"00000000000000000000000000000000\t\"evil.txt\"\n"), 0644)
+			},
+			keyPath: pubPath,
+			wantErr: signature.ErrInvalid,
+		},
+		{
+			name: "缺少签名文件",
+			prepare: func(checkFile string) {
+				_ = os.Remove(signature.SignatureFileName(checkFile))
+			},
+			keyPath: pubPath,
+			wantErr: signature.ErrUnsigned,
+		},
+		{
+			name:    "公钥不匹配",
+			prepare: func(checkFile string) {},
+			keyPath: otherPubPath,
+			wantErr: signature.ErrInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkFile := filepath.Join(tempDir, "checksum.hash")
+			if err := os.WriteFile(checkFile, []byte(content), 0644); err != nil {
+				t.Fatalf("创建校验文件失败: %v", err)
+			}
+			if _, err := signature.SignFile(checkFile, priv); err != nil {
+				t.Fatalf("签名失败: %v", err)
+			}
+			tt.prepare(checkFile)
+
+			data, err := verifyChecksumFile(cl, checkFile, tt.keyPath)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("不期望错误但发生了错误: %v", err)
+				}
+				if string(data) != content {
+					t.Errorf("返回的内容不正确: %q", data)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("期望错误 %v, 实际为 %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// 公钥文件无效
+	if _, err := verifyChecksumFile(cl, filepath.Join(tempDir, "checksum.hash"), privPath); err == nil {
+		t.Error("使用私钥文件作为公钥时应返回错误")
+	}
+}
+
+// TestCheckCmdMain_VerifyKey 测试 --verify-key 校验已签名的校验文件
+func TestCheckCmdMain_VerifyKey(t *testing.T) {
+	cl := colorlib.New()
+	tempDir := t.TempDir()
+
+	testFile := filepath.Join(tempDir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("test content for main"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	checkFile := filepath.Join(tempDir, "valid.hash")
+	if err := os.WriteFile(checkFile, []byte("554a4a6903bc4c5ecaadd2ff5df6c536  "+testFile+"\n"), 0644); err != nil {
+		t.Fatalf("创建校验文件失败: %v", err)
+	}
+
+	privPath, pubPath := writeTestKeyPair(t, tempDir, "key")
+	priv, err := signature.LoadPrivateKey(privPath)
+	if err != nil {
+		t.Fatalf("读取私钥失败: %v", err)
+	}
+
+	checkCmd = InitCheckCmd()
+	_ = checkCmdFile.Set(checkFile)
+	_ = checkCmdVerify.Set(pubPath)
+
+	// 未签名时拒绝校验
+	if err := CheckCmdMain(context.Background(), cl); err == nil {
+		t.Fatal("未签名的校验文件应返回错误")
+	}
+
+	if _, err := signature.SignFile(checkFile, priv); err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	if err := CheckCmdMain(context.Background(), cl); err != nil {
+		t.Errorf("签名有效时不应返回错误: %v", err)
+	}
+}
@@ -4,14 +4,21 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"runtime/debug"
+	"syscall"
 
 	"gitee.com/MM-Q/colorlib"
 	"gitee.com/MM-Q/fck/commands/check"
+	"gitee.com/MM-Q/fck/commands/dupes"
 	"gitee.com/MM-Q/fck/commands/find"
 	"gitee.com/MM-Q/fck/commands/hash"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/keygen"
 	"gitee.com/MM-Q/fck/commands/list"
 	"gitee.com/MM-Q/fck/commands/pack"
 	"gitee.com/MM-Q/fck/commands/preview"
@@ -22,6 +29,10 @@ import (
 )
 
 // Run 运行命令行工具
+//
+// 注意:
+//   - 进程退出码遵循 exitcode 包定义的方案: 0 成功, 1 校验不匹配或未找到, 2 部分失败, 3 用法错误, 130 被中断
+//   - 收到中断信号(Ctrl+C)或 SIGTERM 后取消子命令的上下文, 子命令停止处理并清理临时文件后以 130 退出; 再次收到信号时立即退出
 func Run() {
 	defer func() {
 		if err := recover(); err != nil {
@@ -64,16 +75,21 @@ func Run() {
 	// 获取watchCmd子命令
 	watchCmd := watch.InitWatchCmd()
 
+	// 获取dupesCmd子命令
+	dupesCmd := dupes.InitDupesCmd()
+
+	// 获取keygenCmd子命令
+	keygenCmd := keygen.InitKeygenCmd()
+
 	// 添加子命令到全局根命令
-	if addCmdErr := qflag.Root.AddSubCmd(sizeCmd, listCmd, checkCmd, hashCmd, findCmd, packCmd, unpackCmd, previewCmd, watchCmd); addCmdErr != nil {
+	if addCmdErr := qflag.Root.AddSubCmd(sizeCmd, listCmd, checkCmd, hashCmd, findCmd, packCmd, unpackCmd, previewCmd, watchCmd, dupesCmd, keygenCmd); addCmdErr != nil {
 		fmt.Printf("err: %v\n", addCmdErr)
 		os.Exit(1)
 	}
 
 	// 解析参数
 	if parseErr := qflag.Parse(); parseErr != nil {
-		fmt.Printf("err: %v\n", parseErr)
-		os.Exit(1)
+		exit(exitcode.New(exitcode.Usage, parseErr))
 	}
 
 	// 获取子命令名字
@@ -86,77 +102,88 @@ func Run() {
 		os.Exit(0)
 	}
 
+	// 收到中断信号时取消上下文, 之后恢复默认的信号处理
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	// 正在计算哈希的文件在中断后立即停止读取
+	digest.SetReadContext(ctx)
+
 	// 执行子命令
+	var err error
 	switch subCmdName {
 	case hashCmd.LongName(), hashCmd.ShortName(): // hash 子命令
 		// 执行 hash 子命令
-		if err := hash.HashCmdMain(cmdCL); err != nil {
-			fmt.Printf("err: %v\n", err)
-			os.Exit(1)
-		}
+		err = hash.HashCmdMain(ctx, cmdCL)
 
 	case sizeCmd.LongName(), sizeCmd.ShortName(): // size 子命令
 		// 执行 size 子命令
-		if err := size.SizeCmdMain(cmdCL); err != nil {
-			fmt.Printf("err: %v\n", err)
-			os.Exit(1)
-		}
+		err = size.SizeCmdMain(cmdCL)
 
 	case checkCmd.LongName(), checkCmd.ShortName(): // check 子命令
 		// 执行 check 子命令
-		if err := check.CheckCmdMain(cmdCL); err != nil {
-			fmt.Printf("err: %v\n", err)
-			os.Exit(1)
-		}
+		err = check.CheckCmdMain(ctx, cmdCL)
 
 	case findCmd.LongName(), findCmd.ShortName(): // find 子命令
 		// 执行 find 子命令
-		if err := find.FindCmdMain(cmdCL); err != nil {
-			fmt.Printf("err: %v\n", err)
-			os.Exit(1)
-		}
+		err = find.FindCmdMain(ctx, cmdCL)
 
 	case listCmd.LongName(), listCmd.ShortName(): // list 子命令
 		// 执行 list 子命令
-		if err := list.ListCmdMain(cmdCL); err != nil {
-			fmt.Printf("err: %v\n", err)
-			os.Exit(1)
-		}
+		err = list.ListCmdMain(cmdCL)
 
 	case packCmd.LongName(), packCmd.ShortName(): // pack 子命令
 		// 执行 pack 子命令
-		if err := pack.PackCmdMain(); err != nil {
-			fmt.Printf("err: %v\n", err)
-			os.Exit(1)
-		}
+		err = pack.PackCmdMain()
 
 	case unpackCmd.LongName(), unpackCmd.ShortName(): // unpack 子命令
 		// 执行 unpack 子命令
-		if err := unpack.UnpackCmdMain(); err != nil {
-			fmt.Printf("err: %v\n", err)
-			os.Exit(1)
-		}
+		err = unpack.UnpackCmdMain()
 
 	case previewCmd.LongName(), previewCmd.ShortName(): // preview 子命令
 		// 执行 preview 子命令
-		if err := preview.PreviewCmdMain(); err != nil {
-			fmt.Printf("err: %v\n", err)
-			os.Exit(1)
-		}
+		err = preview.PreviewCmdMain()
 
 	case watchCmd.LongName(), watchCmd.ShortName(): // watch 子命令
 		// 执行 watch 子命令
-		if err := watch.WatchCmdMain(); err != nil {
-			fmt.Printf("err: %v\n", err)
-			os.Exit(1)
-		}
+		err = watch.WatchCmdMain(ctx)
+
+	case dupesCmd.LongName(), dupesCmd.ShortName(): // dupes 子命令
+		// 执行 dupes 子命令
+		err = dupes.DupesCmdMain(ctx, cmdCL)
+
+	case keygenCmd.LongName(), keygenCmd.ShortName(): // keygen 子命令
+		// 执行 keygen 子命令
+		err = keygen.KeygenCmdMain(cmdCL)
 
 	default:
 		// 如果是未知的子命令, 则打印帮助信息并退出
 		fmt.Printf("err: 未知的子命令 %s\n", subCmdName)
 		qflag.Root.PrintHelp()
-		os.Exit(1)
+		os.Exit(exitcode.Usage)
+	}
+
+	// 被中断的子命令只处理了部分文件, 统一以 130 退出
+	if ctx.Err() != nil {
+		err = exitcode.ErrInterrupted
 	}
 
-	os.Exit(0)
+	exit(err)
+}
+
+// exit 输出错误信息并按退出码方案退出进程
+//
+// 参数:
+//   - err: 子命令返回的错误, 为nil时以 0 退出
+//
+// 注意:
+//   - 错误信息为空(如校验不匹配等已输出过结果的情况)时不再重复输出
+func exit(err error) {
+	if err != nil && err.Error() != "" {
+		fmt.Printf("err: %v\n", err)
+	}
+	os.Exit(exitcode.Code(err))
 }
@@ -0,0 +1,205 @@
+// Package dupes 实现了对重复文件的处理操作。
+// 该文件负责从每组重复文件中选出保留文件, 并对其余文件执行删除、替换为硬链接或替换为软链接等操作。
+package dupes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// compareBlockSize 逐字节比较文件内容时每次读取的字节数
+const compareBlockSize = 64 * 1024
+
+// plannedAction 对单个重复文件计划执行的操作
+type plannedAction struct {
+	Action string `json:"action"` // 操作类型
+	Path   string `json:"path"`   // 被处理的重复文件
+	Keep   string `json:"keep"`   // 保留的文件
+	Error  string `json:"error,omitempty"`
+}
+
+// selectKeep 从重复文件组中选出保留文件
+//
+// 参数:
+//   - files: 组内文件(按路径排序)
+//   - keep: 保留策略
+//
+// 返回:
+//   - int: 保留文件在 files 中的索引
+//
+// 注意:
+//   - 修改时间相同时保留路径排序靠前的文件
+func selectKeep(files []fileEntry, keep string) int {
+	idx := 0
+	for i := 1; i < len(files); i++ {
+		switch keep {
+		case keepOldest:
+			if files[i].ModTime.Before(files[idx].ModTime) {
+				idx = i
+			}
+		case keepNewest:
+			if files[i].ModTime.After(files[idx].ModTime) {
+				idx = i
+			}
+		}
+	}
+	return idx
+}
+
+// planActions 为所有重复文件组生成操作计划
+//
+// 参数:
+//   - groups: 重复文件组
+//   - action: 操作类型
+//   - keep: 保留策略
+//
+// 返回:
+//   - []plannedAction: 操作计划(action 为 list 时返回 nil)
+func planActions(groups []DupeGroup, action, keep string) []plannedAction {
+	if action == actionList {
+		return nil
+	}
+
+	var plans []plannedAction
+	for _, group := range groups {
+		keepIdx := selectKeep(group.Files, keep)
+		for i, file := range group.Files {
+			if i == keepIdx {
+				continue
+			}
+			plans = append(plans, plannedAction{Action: action, Path: file.Path, Keep: group.Files[keepIdx].Path})
+		}
+	}
+	return plans
+}
+
+// applyAction 执行单个操作
+//
+// 参数:
+//   - plan: 操作计划
+//
+// 返回:
+//   - error: 错误信息
+//
+// 注意:
+//   - 执行删除或链接前逐字节比较文件与保留文件, 内容不一致(哈希碰撞或扫描后被修改)时跳过该文件
+func applyAction(plan plannedAction) error {
+	same, err := sameContent(plan.Path, plan.Keep)
+	if err != nil {
+		return fmt.Errorf("比较文件 %s 与 %s 失败: %w", plan.Path, plan.Keep, err)
+	}
+	if !same {
+		return fmt.Errorf("文件 %s 与保留文件 %s 内容不一致, 已跳过", plan.Path, plan.Keep)
+	}
+
+	switch plan.Action {
+	case actionDelete:
+		if err := os.Remove(plan.Path); err != nil {
+			return fmt.Errorf("删除文件 %s 失败: %w", plan.Path, err)
+		}
+		return nil
+
+	case actionHardlink:
+		return replaceWithLink(plan.Path, func(tmp string) error {
+			return os.Link(plan.Keep, tmp)
+		})
+
+	case actionSymlink:
+		target, err := filepath.Abs(plan.Keep)
+		if err != nil {
+			return fmt.Errorf("获取绝对路径失败: %w", err)
+		}
+		return replaceWithLink(plan.Path, func(tmp string) error {
+			return os.Symlink(target, tmp)
+		})
+
+	default:
+		return fmt.Errorf("不支持的操作: %s", plan.Action)
+	}
+}
+
+// sameContent 逐字节比较两个文件的内容是否相同
+//
+// 参数:
+//   - a: 文件路径
+//   - b: 文件路径
+//
+// 返回:
+//   - bool: 内容是否相同
+//   - error: 错误信息
+func sameContent(a, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = fa.Close() }()
+
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = fb.Close() }()
+
+	infoA, err := fa.Stat()
+	if err != nil {
+		return false, err
+	}
+	infoB, err := fb.Stat()
+	if err != nil {
+		return false, err
+	}
+	if infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+
+	bufA := make([]byte, compareBlockSize)
+	bufB := make([]byte, compareBlockSize)
+	for {
+		na, errA := io.ReadFull(fa, bufA)
+		nb, errB := io.ReadFull(fb, bufB)
+		if na != nb || !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false, nil
+		}
+
+		doneA := errA == io.EOF || errA == io.ErrUnexpectedEOF
+		doneB := errB == io.EOF || errB == io.ErrUnexpectedEOF
+		if errA != nil && !doneA {
+			return false, errA
+		}
+		if errB != nil && !doneB {
+			return false, errB
+		}
+		if doneA || doneB {
+			return doneA == doneB, nil
+		}
+	}
+}
+
+// replaceWithLink 使用链接替换文件
+//
+// 参数:
+//   - path: 被替换的文件路径
+//   - link: 在指定临时路径创建链接的函数
+//
+// 返回:
+//   - error: 错误信息
+//
+// 注意:
+//   - 先在同一目录下创建临时链接再重命名覆盖原文件, 失败时原文件保持不变
+func replaceWithLink(path string, link func(tmp string) error) error {
+	tmp := filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.fck-dupes-%d", filepath.Base(path), os.Getpid()))
+
+	if err := link(tmp); err != nil {
+		return fmt.Errorf("创建链接 %s 失败: %w", path, err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("替换文件 %s 失败: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package dupes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestSelectKeep 测试保留文件选择策略
+func TestSelectKeep(t *testing.T) {
+	now := time.Now()
+	files := []fileEntry{
+		{Path: "a", ModTime: now},
+		{Path: "b", ModTime: now.Add(-time.Hour)},
+		{Path: "c", ModTime: now.Add(time.Hour)},
+	}
+
+	tests := []struct {
+		keep string
+		want int
+	}{
+		{keepFirst, 0},
+		{keepOldest, 1},
+		{keepNewest, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keep, func(t *testing.T) {
+			if got := selectKeep(files, tt.keep); got != tt.want {
+				t.Errorf("selectKeep(%s) = %d, 期望 %d", tt.keep, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPlanActions 测试操作计划生成
+func TestPlanActions(t *testing.T) {
+	groups := []DupeGroup{
+		{Files: []fileEntry{{Path: "a"}, {Path: "b"}, {Path: "c"}}},
+		{Files: []fileEntry{{Path: "d"}, {Path: "e"}}},
+	}
+
+	if plans := planActions(groups, actionList, keepFirst); plans != nil {
+		t.Errorf("list 操作不应生成计划, 实际: %+v", plans)
+	}
+
+	plans := planActions(groups, actionDelete, keepFirst)
+	if len(plans) != 3 {
+		t.Fatalf("期望 3 个操作, 实际 %d 个", len(plans))
+	}
+	for _, plan := range plans {
+		if plan.Path == "a" || plan.Path == "d" {
+			t.Errorf("保留文件不应出现在操作计划中: %s", plan.Path)
+		}
+	}
+}
+
+// TestApplyAction 测试删除、硬链接和软链接操作
+func TestApplyAction(t *testing.T) {
+	for _, action := range []string{actionDelete, actionHardlink, actionSymlink} {
+		t.Run(action, func(t *testing.T) {
+			tempDir := t.TempDir()
+			keep := filepath.Join(tempDir, "keep.txt")
+			dupe := filepath.Join(tempDir, "dupe.txt")
+			writeFile(t, keep, []byte("same"))
+			writeFile(t, dupe, []byte("same"))
+
+			if err := applyAction(plannedAction{Action: action, Path: dupe, Keep: keep}); err != nil {
+				t.Fatalf("applyAction() 返回错误: %v", err)
+			}
+
+			info, err := os.Lstat(dupe)
+			switch action {
+			case actionDelete:
+				if !os.IsNotExist(err) {
+					t.Errorf("重复文件应已删除")
+				}
+			case actionHardlink:
+				keepInfo, _ := os.Stat(keep)
+				if err != nil || !os.SameFile(info, keepInfo) {
+					t.Errorf("重复文件应为保留文件的硬链接")
+				}
+			case actionSymlink:
+				if err != nil || info.Mode()&os.ModeSymlink == 0 {
+					t.Fatalf("重复文件应为软链接")
+				}
+				if target, _ := os.Readlink(dupe); !filepath.IsAbs(target) {
+					t.Errorf("软链接应指向绝对路径, 实际: %s", target)
+				}
+			}
+
+			// 临时链接不应残留
+			entries, _ := os.ReadDir(tempDir)
+			for _, entry := range entries {
+				if entry.Name() != "keep.txt" && entry.Name() != "dupe.txt" {
+					t.Errorf("残留临时文件: %s", entry.Name())
+				}
+			}
+		})
+	}
+}
+
+// TestApplyActionContentMismatch 测试内容不一致时跳过操作
+func TestApplyActionContentMismatch(t *testing.T) {
+	for _, action := range []string{actionDelete, actionHardlink, actionSymlink} {
+		t.Run(action, func(t *testing.T) {
+			tempDir := t.TempDir()
+			keep := filepath.Join(tempDir, "keep.txt")
+			dupe := filepath.Join(tempDir, "dupe.txt")
+			writeFile(t, keep, []byte("same"))
+			writeFile(t, dupe, []byte("diff"))
+
+			// 哈希碰撞或扫描后被修改时, 大小相同但内容不同的文件不应被处理
+			if err := applyAction(plannedAction{Action: action, Path: dupe, Keep: keep}); err == nil {
+				t.Fatalf("applyAction() 期望返回内容不一致错误")
+			}
+
+			info, err := os.Lstat(dupe)
+			if err != nil || !info.Mode().IsRegular() {
+				t.Fatalf("重复文件应保持不变")
+			}
+			if data, _ := os.ReadFile(dupe); string(data) != "diff" {
+				t.Errorf("重复文件内容被修改: %q", data)
+			}
+		})
+	}
+}
+
+// TestSameContent 测试逐字节比较文件内容
+func TestSameContent(t *testing.T) {
+	tempDir := t.TempDir()
+	large := bytes.Repeat([]byte("a"), compareBlockSize+10)
+	changed := bytes.Clone(large)
+	changed[len(changed)-1] = 'b'
+
+	files := map[string][]byte{"a": large, "b": bytes.Clone(large), "c": changed, "d": []byte("short")}
+	for name, data := range files {
+		writeFile(t, filepath.Join(tempDir, name), data)
+	}
+
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"a", "b", true},
+		{"a", "c", false},
+		{"a", "d", false},
+	}
+	for _, tt := range tests {
+		got, err := sameContent(filepath.Join(tempDir, tt.a), filepath.Join(tempDir, tt.b))
+		if err != nil || got != tt.want {
+			t.Errorf("sameContent(%s, %s) = %v, %v, 期望 %v", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+}
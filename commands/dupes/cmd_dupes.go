@@ -0,0 +1,219 @@
+// Package dupes 实现了重复文件查找命令的主要逻辑。
+// 该文件包含 dupes 子命令的入口函数，负责参数验证、重复文件查找、结果输出以及删除/链接操作的执行。
+package dupes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/common"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+)
+
+// dupesReport JSON 格式的重复文件报告
+type dupesReport struct {
+	Algorithm   string          `json:"algorithm"`         // 完整比较使用的哈希算法
+	Groups      []DupeGroup     `json:"groups"`            // 重复文件组
+	TotalFiles  int             `json:"total_files"`       // 重复文件总数(含保留文件)
+	WastedBytes int64           `json:"wasted_bytes"`      // 可释放的总字节数
+	Actions     []plannedAction `json:"actions,omitempty"` // 操作计划及执行结果
+	DryRun      bool            `json:"dry_run"`           // 是否为预览模式
+	Errors      []string        `json:"errors,omitempty"`  // 扫描过程中的错误
+}
+
+// DupesCmdMain 是 dupes 子命令的主函数
+//
+// 参数:
+//   - ctx: 上下文, 取消后停止查找且不再执行剩余的操作
+//   - cl: 颜色库对象
+//
+// 返回:
+//   - error: 错误信息
+func DupesCmdMain(ctx context.Context, cl *colorlib.ColorLib) error {
+	cl.SetColor(dupesCmdColor.Get())
+
+	// 未指定路径时扫描当前目录
+	targetPaths := dupesCmd.Args()
+	if len(targetPaths) == 0 {
+		targetPaths = []string{"."}
+	}
+	for i, targetPath := range targetPaths {
+		targetPaths[i] = filepath.Clean(targetPath)
+	}
+
+	if dupesCmdMinSize.Get() < 0 {
+		return exitcode.Usagef("--min-size 不能为负数: %d", dupesCmdMinSize.Get())
+	}
+
+	f := newFinder(dupesCmdType.Get(), dupesCmdHidden.Get(), dupesCmdMinSize.Get())
+	f.ctx = ctx
+	groups, err := f.find(targetPaths)
+	if err != nil {
+		return err
+	}
+
+	// 生成并执行操作计划
+	plans := planActions(groups, dupesCmdAction.Get(), dupesCmdKeep.Get())
+	if !dupesCmdDryRun.Get() {
+		for i := range plans {
+			// 收到中断信号时不再执行剩余操作, 仅输出已执行的操作
+			if ctx.Err() != nil {
+				plans = plans[:i]
+				break
+			}
+			if err := applyAction(plans[i]); err != nil {
+				plans[i].Error = err.Error()
+			}
+		}
+	}
+
+	if dupesCmdJSON.Get() {
+		if err := printJSONReport(groups, plans, f.errors); err != nil {
+			return err
+		}
+		return resultError(ctx, plans, f.errors)
+	}
+
+	for _, err := range f.errors {
+		cl.PrintWarnf("%v\n", err)
+	}
+	printGroups(cl, groups)
+	printActions(cl, plans)
+	return resultError(ctx, plans, f.errors)
+}
+
+// resultError 生成 dupes 命令的返回错误
+//
+// 参数:
+//   - ctx: 上下文
+//   - plans: 操作计划及执行结果
+//   - scanErrors: 扫描过程中的错误
+//
+// 返回:
+//   - error: 执行操作期间收到中断信号时返回 exitcode.ErrInterrupted, 否则返回 partialError 生成的错误
+func resultError(ctx context.Context, plans []plannedAction, scanErrors []error) error {
+	if ctx.Err() != nil {
+		return exitcode.ErrInterrupted
+	}
+	return partialError(plans, scanErrors)
+}
+
+// partialError 根据扫描错误和操作执行结果生成部分失败错误
+//
+// 参数:
+//   - plans: 操作计划及执行结果
+//   - scanErrors: 扫描过程中的错误
+//
+// 返回:
+//   - error: 存在扫描错误或执行失败的操作时返回退出码为 exitcode.Partial 的错误(错误信息已输出, 不再携带), 否则返回nil
+func partialError(plans []plannedAction, scanErrors []error) error {
+	if len(scanErrors) > 0 {
+		return exitcode.New(exitcode.Partial, nil)
+	}
+	for _, plan := range plans {
+		if plan.Error != "" {
+			return exitcode.New(exitcode.Partial, nil)
+		}
+	}
+	return nil
+}
+
+// printGroups 输出重复文件组及汇总信息
+//
+// 参数:
+//   - cl: 颜色库对象
+//   - groups: 重复文件组
+func printGroups(cl *colorlib.ColorLib, groups []DupeGroup) {
+	if len(groups) == 0 {
+		cl.PrintOk("未发现重复文件")
+		return
+	}
+
+	var totalFiles int
+	var totalWasted int64
+	for _, group := range groups {
+		fmt.Printf("%s %s\n", cl.Sblue(group.Hash), cl.Syellowf("(%d 个文件, 每个 %s, 可释放 %s)", len(group.Files), common.FormatSize(group.Size, 1), common.FormatSize(group.WastedBytes, 1)))
+		for _, file := range group.Files {
+			fmt.Printf("    %s\n", file.Path)
+		}
+		fmt.Println()
+
+		totalFiles += len(group.Files)
+		totalWasted += group.WastedBytes
+	}
+
+	cl.PrintInfof("共发现 %d 组重复文件, 涉及 %d 个文件, 可释放 %s\n", len(groups), totalFiles, common.FormatSize(totalWasted, 1))
+}
+
+// printActions 输出操作计划或执行结果
+//
+// 参数:
+//   - cl: 颜色库对象
+//   - plans: 操作计划
+func printActions(cl *colorlib.ColorLib, plans []plannedAction) {
+	if len(plans) == 0 {
+		return
+	}
+
+	var failed int
+	for _, plan := range plans {
+		switch {
+		case dupesCmdDryRun.Get():
+			fmt.Printf("[预览] %s %s (保留 %s)\n", plan.Action, plan.Path, plan.Keep)
+		case plan.Error != "":
+			failed++
+			cl.PrintErrorf("%s\n", plan.Error)
+		default:
+			fmt.Printf("%s %s (保留 %s)\n", plan.Action, plan.Path, plan.Keep)
+		}
+	}
+
+	if dupesCmdDryRun.Get() {
+		cl.PrintInfof("预览模式: 共 %d 个文件将被处理, 未修改任何文件\n", len(plans))
+		return
+	}
+	if failed > 0 {
+		cl.PrintWarnf("已处理 %d 个文件, 失败 %d 个\n", len(plans)-failed, failed)
+		return
+	}
+	cl.PrintOkf("已处理 %d 个文件\n", len(plans))
+}
+
+// printJSONReport 以JSON格式输出重复文件报告
+//
+// 参数:
+//   - groups: 重复文件组
+//   - plans: 操作计划及执行结果
+//   - scanErrors: 扫描过程中的错误
+//
+// 返回:
+//   - error: 错误信息
+func printJSONReport(groups []DupeGroup, plans []plannedAction, scanErrors []error) error {
+	report := dupesReport{
+		Algorithm: dupesCmdType.Get(),
+		Groups:    groups,
+		Actions:   plans,
+		DryRun:    dupesCmdDryRun.Get(),
+	}
+	if report.Groups == nil {
+		report.Groups = []DupeGroup{}
+	}
+	for _, group := range groups {
+		report.TotalFiles += len(group.Files)
+		report.WastedBytes += group.WastedBytes
+	}
+	for _, err := range scanErrors {
+		report.Errors = append(report.Errors, err.Error())
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("输出JSON报告失败: %w", err)
+	}
+	return nil
+}
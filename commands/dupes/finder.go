@@ -0,0 +1,299 @@
+// Package dupes 实现了重复文件的分组查找功能。
+// 该文件依次按文件大小、首尾部分哈希和完整哈希对候选文件分组, 每一轮只对上一轮仍有重复的文件进行计算,
+// 尽量减少需要完整读取的文件数量。
+package dupes
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	"gitee.com/MM-Q/fck/commands/internal/common"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+)
+
+// partialBlockSize 部分哈希读取的首部和尾部字节数
+const partialBlockSize = 4096
+
+// fileEntry 候选文件
+type fileEntry struct {
+	Path    string    `json:"path"`     // 文件路径
+	Size    int64     `json:"size"`     // 文件大小
+	ModTime time.Time `json:"mod_time"` // 修改时间
+}
+
+// DupeGroup 重复文件组
+type DupeGroup struct {
+	Hash        string      `json:"hash"`         // 完整哈希值
+	Size        int64       `json:"size"`         // 单个文件大小
+	Files       []fileEntry `json:"files"`        // 组内文件(按路径排序)
+	WastedBytes int64       `json:"wasted_bytes"` // 可释放的字节数(大小 * (文件数-1))
+}
+
+// finder 重复文件查找器
+type finder struct {
+	hashType string // 完整比较使用的哈希算法
+	hidden   bool   // 是否包含隐藏文件/目录
+	minSize  int64  // 参与比较的最小文件大小
+	workers  int    // 并发计算哈希的协程数
+	errors   []error
+	ctx      context.Context // 查找上下文, 取消后停止扫描和计算
+}
+
+// newFinder 创建重复文件查找器
+//
+// 参数:
+//   - hashType: 完整比较使用的哈希算法
+//   - hidden: 是否包含隐藏文件/目录
+//   - minSize: 参与比较的最小文件大小(小于1时按1处理, 空文件不参与比较)
+//
+// 返回:
+//   - *finder: 重复文件查找器
+func newFinder(hashType string, hidden bool, minSize int64) *finder {
+	if minSize < 1 {
+		minSize = 1
+	}
+	return &finder{
+		hashType: hashType,
+		hidden:   hidden,
+		minSize:  minSize,
+		workers:  runtime.NumCPU(),
+		ctx:      context.Background(),
+	}
+}
+
+// find 在指定路径中查找重复文件
+//
+// 参数:
+//   - paths: 要扫描的文件或目录列表
+//
+// 返回:
+//   - []DupeGroup: 重复文件组(按可释放字节数降序排列)
+//   - error: 错误信息, 上下文取消时返回 exitcode.ErrInterrupted
+func (f *finder) find(paths []string) ([]DupeGroup, error) {
+	files, err := f.collect(paths)
+	if err != nil {
+		return nil, err
+	}
+	if f.ctx.Err() != nil {
+		return nil, exitcode.ErrInterrupted
+	}
+
+	// 第一轮: 按文件大小分组
+	bySize := make(map[int64][]fileEntry)
+	for _, file := range files {
+		bySize[file.Size] = append(bySize[file.Size], file)
+	}
+
+	var groups []DupeGroup
+	for size, candidates := range bySize {
+		if len(candidates) < 2 {
+			continue
+		}
+
+		// 第二轮: 按首尾部分哈希分组
+		for _, partial := range f.groupByHash(candidates, partialHash) {
+			// 第三轮: 按完整哈希分组
+			for hashValue, full := range f.groupByHash(partial, f.fullHash) {
+				sort.Slice(full, func(i, j int) bool { return full[i].Path < full[j].Path })
+				groups = append(groups, DupeGroup{
+					Hash:        hashValue,
+					Size:        size,
+					Files:       full,
+					WastedBytes: size * int64(len(full)-1),
+				})
+			}
+		}
+	}
+
+	// 中断时部分文件未参与比较, 分组结果不完整
+	if f.ctx.Err() != nil {
+		return nil, exitcode.ErrInterrupted
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].WastedBytes != groups[j].WastedBytes {
+			return groups[i].WastedBytes > groups[j].WastedBytes
+		}
+		return groups[i].Files[0].Path < groups[j].Files[0].Path
+	})
+
+	return groups, nil
+}
+
+// collect 收集候选文件
+//
+// 参数:
+//   - paths: 要扫描的文件或目录列表
+//
+// 返回:
+//   - []fileEntry: 候选文件列表(已排除软链接、非普通文件、过小的文件以及重复的硬链接)
+//   - error: 错误信息
+func (f *finder) collect(paths []string) ([]fileEntry, error) {
+	var files []fileEntry
+	seenIDs := make(map[[2]uint64]struct{}) // 已收集的设备号+inode
+	seenPaths := make(map[string]struct{})  // 已收集的路径(避免重叠的扫描路径重复计入)
+
+	for _, root := range paths {
+		if _, err := os.Lstat(root); err != nil {
+			return nil, fmt.Errorf("无法访问路径 %s: %w", root, err)
+		}
+
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if f.ctx.Err() != nil {
+				return filepath.SkipAll
+			}
+			if err != nil {
+				f.errors = append(f.errors, fmt.Errorf("访问 %s 失败: %w", path, err))
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if !f.hidden && path != root && common.IsHidden(path) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			// 仅比较普通文件
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				f.errors = append(f.errors, fmt.Errorf("获取文件信息 %s 失败: %w", path, err))
+				return nil
+			}
+			if info.Size() < f.minSize {
+				return nil
+			}
+
+			clean := filepath.Clean(path)
+			if _, ok := seenPaths[clean]; ok {
+				return nil
+			}
+			seenPaths[clean] = struct{}{}
+
+			// 互为硬链接的文件只保留一个
+			if dev, ino, ok := common.GetFileID(info); ok {
+				id := [2]uint64{dev, ino}
+				if _, ok := seenIDs[id]; ok {
+					return nil
+				}
+				seenIDs[id] = struct{}{}
+			}
+
+			files = append(files, fileEntry{Path: clean, Size: info.Size(), ModTime: info.ModTime()})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("遍历目录 %s 失败: %w", root, err)
+		}
+	}
+
+	return files, nil
+}
+
+// groupByHash 并发计算文件哈希并按哈希值分组
+//
+// 参数:
+//   - files: 待分组的文件
+//   - hashFunc: 哈希计算函数
+//
+// 返回:
+//   - map[string][]fileEntry: 哈希值 -> 文件列表(仅包含两个及以上文件的组)
+//
+// 注意:
+//   - 计算失败的文件记录错误后从候选中排除
+func (f *finder) groupByHash(files []fileEntry, hashFunc func(string) (string, error)) map[string][]fileEntry {
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.Path
+	}
+
+	hashes, errs := digest.ChecksumFiles(paths, f.workers, hashFunc)
+
+	groups := make(map[string][]fileEntry)
+	for i, file := range files {
+		if errs[i] != nil {
+			f.errors = append(f.errors, fmt.Errorf("计算 %s 哈希失败: %w", file.Path, errs[i]))
+			continue
+		}
+		groups[hashes[i]] = append(groups[hashes[i]], file)
+	}
+
+	for hashValue, group := range groups {
+		if len(group) < 2 {
+			delete(groups, hashValue)
+		}
+	}
+
+	return groups
+}
+
+// fullHash 计算文件完整哈希
+func (f *finder) fullHash(path string) (string, error) {
+	return digest.Checksum(path, f.hashType)
+}
+
+// partialHash 计算文件首部和尾部各 partialBlockSize 字节的哈希
+//
+// 参数:
+//   - path: 文件路径
+//
+// 返回:
+//   - string: 十六进制哈希值
+//   - error: 错误信息
+//
+// 注意:
+//   - 仅用于快速排除内容不同的文件, 结果相同的文件仍需完整比较
+func partialHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	h, err := digest.New(digest.XXHash64)
+	if err != nil {
+		return "", err
+	}
+
+	// 首部
+	if _, err := io.CopyN(h, file, partialBlockSize); err != nil && err != io.EOF {
+		return "", err
+	}
+
+	// 尾部(与首部不重叠时才读取)
+	if info.Size() > 2*partialBlockSize {
+		if _, err := file.Seek(-partialBlockSize, io.SeekEnd); err != nil {
+			return "", err
+		}
+		if _, err := io.CopyN(h, file, partialBlockSize); err != nil && err != io.EOF {
+			return "", err
+		}
+	} else if info.Size() > partialBlockSize {
+		if _, err := io.Copy(h, file); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
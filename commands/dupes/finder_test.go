@@ -0,0 +1,120 @@
+package dupes
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile 创建测试文件
+func writeFile(t *testing.T, path string, content []byte) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+}
+
+// TestFinder_Find 测试重复文件分组
+func TestFinder_Find(t *testing.T) {
+	tempDir := t.TempDir()
+
+	big := bytes.Repeat([]byte("x"), 3*partialBlockSize)
+	bigOther := bytes.Clone(big)
+	bigOther[len(bigOther)/2] = 'y' // 首尾相同, 仅中间不同
+
+	writeFile(t, filepath.Join(tempDir, "a.txt"), []byte("hello"))
+	writeFile(t, filepath.Join(tempDir, "sub", "b.txt"), []byte("hello"))
+	writeFile(t, filepath.Join(tempDir, "c.txt"), []byte("world")) // 大小相同内容不同
+	writeFile(t, filepath.Join(tempDir, "big1.bin"), big)
+	writeFile(t, filepath.Join(tempDir, "big2.bin"), big)
+	writeFile(t, filepath.Join(tempDir, "big3.bin"), bigOther)
+	writeFile(t, filepath.Join(tempDir, ".hidden", "d.txt"), []byte("hello"))
+	writeFile(t, filepath.Join(tempDir, "empty1"), nil)
+	writeFile(t, filepath.Join(tempDir, "empty2"), nil)
+
+	// 硬链接不计入重复
+	if err := os.Link(filepath.Join(tempDir, "a.txt"), filepath.Join(tempDir, "a.link")); err != nil {
+		t.Skipf("当前文件系统不支持硬链接: %v", err)
+	}
+
+	groups, err := newFinder("sha256", false, 1).find([]string{tempDir})
+	if err != nil {
+		t.Fatalf("find() 返回错误: %v", err)
+	}
+
+	if len(groups) != 2 {
+		t.Fatalf("期望 2 组重复文件, 实际 %d 组: %+v", len(groups), groups)
+	}
+
+	// 按可释放字节数降序排列
+	if groups[0].Size != int64(len(big)) || len(groups[0].Files) != 2 {
+		t.Errorf("第一组应为两个大文件, 实际: %+v", groups[0].Files)
+	}
+	if groups[0].WastedBytes != int64(len(big)) {
+		t.Errorf("可释放字节数错误: 期望 %d, 实际 %d", len(big), groups[0].WastedBytes)
+	}
+	if groups[1].Size != 5 || len(groups[1].Files) != 2 {
+		t.Errorf("第二组应为 a.txt 和 sub/b.txt, 实际: %+v", groups[1].Files)
+	}
+}
+
+// TestFinder_FindHidden 测试包含隐藏文件和最小文件大小
+func TestFinder_FindHidden(t *testing.T) {
+	tempDir := t.TempDir()
+
+	writeFile(t, filepath.Join(tempDir, "a.txt"), []byte("hello"))
+	writeFile(t, filepath.Join(tempDir, ".hidden", "b.txt"), []byte("hello"))
+	writeFile(t, filepath.Join(tempDir, "c.txt"), []byte("hi"))
+	writeFile(t, filepath.Join(tempDir, "d.txt"), []byte("hi"))
+
+	groups, err := newFinder("md5", true, 3).find([]string{tempDir})
+	if err != nil {
+		t.Fatalf("find() 返回错误: %v", err)
+	}
+
+	if len(groups) != 1 {
+		t.Fatalf("期望 1 组重复文件, 实际 %d 组", len(groups))
+	}
+	if len(groups[0].Files) != 2 {
+		t.Errorf("期望组内 2 个文件, 实际 %d 个", len(groups[0].Files))
+	}
+}
+
+// TestFinder_FindInvalidPath 测试不存在的路径
+func TestFinder_FindInvalidPath(t *testing.T) {
+	if _, err := newFinder("sha256", false, 1).find([]string{filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("不存在的路径应返回错误")
+	}
+}
+
+// TestPartialHash 测试首尾部分哈希
+func TestPartialHash(t *testing.T) {
+	tempDir := t.TempDir()
+
+	content := bytes.Repeat([]byte("a"), 3*partialBlockSize)
+	tailDiff := bytes.Clone(content)
+	tailDiff[len(tailDiff)-1] = 'b'
+
+	pathA := filepath.Join(tempDir, "a")
+	pathB := filepath.Join(tempDir, "b")
+	writeFile(t, pathA, content)
+	writeFile(t, pathB, tailDiff)
+
+	hashA, err := partialHash(pathA)
+	if err != nil {
+		t.Fatalf("partialHash() 返回错误: %v", err)
+	}
+	hashB, err := partialHash(pathB)
+	if err != nil {
+		t.Fatalf("partialHash() 返回错误: %v", err)
+	}
+
+	if hashA == hashB {
+		t.Error("尾部不同的文件部分哈希应不同")
+	}
+}
@@ -0,0 +1,77 @@
+// Package dupes 定义了重复文件查找命令的标志和参数配置。
+// 该文件负责初始化 dupes 子命令的命令行参数解析和帮助信息设置。
+package dupes
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/qflag"
+)
+
+// 重复文件处理动作
+const (
+	actionList     = "list"     // 仅列出
+	actionDelete   = "delete"   // 删除重复文件
+	actionHardlink = "hardlink" // 替换为硬链接
+	actionSymlink  = "symlink"  // 替换为软链接
+)
+
+// 保留文件的选择策略
+const (
+	keepFirst  = "first"  // 保留路径排序最靠前的文件
+	keepOldest = "oldest" // 保留修改时间最早的文件
+	keepNewest = "newest" // 保留修改时间最晚的文件
+)
+
+var (
+	// fck dupes 子命令
+	dupesCmd        *qflag.Cmd
+	dupesCmdType    *qflag.EnumFlag  // type 标志
+	dupesCmdHidden  *qflag.BoolFlag  // hidden 标志
+	dupesCmdMinSize *qflag.Int64Flag // min-size 标志
+	dupesCmdAction  *qflag.EnumFlag  // action 标志
+	dupesCmdKeep    *qflag.EnumFlag  // keep 标志
+	dupesCmdDryRun  *qflag.BoolFlag  // dry-run 标志
+	dupesCmdJSON    *qflag.BoolFlag  // json 标志
+	dupesCmdColor   *qflag.BoolFlag  // color 标志
+)
+
+func InitDupesCmd() *qflag.Cmd {
+	// fck dupes 子命令
+	dupesCmd = qflag.NewCmd("dupes", "d", flag.ContinueOnError)
+
+	dupesCmdCfg := qflag.CmdConfig{
+		UseChinese: true,
+		Desc:       "重复文件查找工具, 递归扫描指定目录, 依次按文件大小、首尾部分哈希和完整哈希分组找出内容相同的文件",
+		Notes: []string{
+			"默认仅列出重复文件, 删除或替换为链接前建议先使用 --dry-run 预览",
+			"已经互为硬链接的文件视为同一个文件, 不计入重复",
+			"删除或替换为链接前会与保留文件逐字节比较, 内容不一致(哈希碰撞或扫描后被修改)的文件将被跳过",
+			"软链接和空文件不参与比较",
+		},
+		UsageSyntax: fmt.Sprintf("%s dupes [options] <path>...\n", qflag.Root.LongName()),
+	}
+
+	dupesCmd.ApplyConfig(dupesCmdCfg)
+
+	dupesCmdType = dupesCmd.Enum("type", "t", digest.SHA256, fmt.Sprintf("指定完整比较使用的哈希算法，支持 %s", strings.Join(digest.SupportedAlgorithms, "、")), digest.SupportedAlgorithms)
+	dupesCmdHidden = dupesCmd.Bool("hidden", "H", false, "包含隐藏文件/目录，默认跳过")
+	dupesCmdMinSize = dupesCmd.Int64("min-size", "m", 1, "参与比较的最小文件大小(字节)")
+	dupesCmdAction = dupesCmd.Enum("action", "a", actionList, "指定对重复文件执行的操作，支持以下选项：\n"+
+		"\t\t\t\t[list] - 仅列出重复文件\n"+
+		"\t\t\t\t[delete] - 删除除保留文件外的重复文件\n"+
+		"\t\t\t\t[hardlink] - 将重复文件替换为指向保留文件的硬链接\n"+
+		"\t\t\t\t[symlink] - 将重复文件替换为指向保留文件的软链接", []string{actionList, actionDelete, actionHardlink, actionSymlink})
+	dupesCmdKeep = dupesCmd.Enum("keep", "k", keepFirst, "指定每组中保留的文件，支持以下选项：\n"+
+		"\t\t\t\t[first] - 路径排序最靠前的文件\n"+
+		"\t\t\t\t[oldest] - 修改时间最早的文件\n"+
+		"\t\t\t\t[newest] - 修改时间最晚的文件", []string{keepFirst, keepOldest, keepNewest})
+	dupesCmdDryRun = dupesCmd.Bool("dry-run", "n", false, "仅显示将要执行的操作, 不修改任何文件")
+	dupesCmdJSON = dupesCmd.Bool("json", "j", false, "以JSON格式输出重复文件报告")
+	dupesCmdColor = dupesCmd.Bool("color", "c", false, "启用颜色输出")
+
+	return dupesCmd
+}
@@ -3,6 +3,7 @@
 package find
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"regexp"
@@ -11,11 +12,19 @@ import (
 
 	"gitee.com/MM-Q/colorlib"
 	"gitee.com/MM-Q/fck/commands/internal/common"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
 	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
 // FindCmdMain 是 find 子命令的主函数 - 重构后作为协调器
-func FindCmdMain(cl *colorlib.ColorLib) error {
+//
+// 参数:
+//   - ctx: 上下文, 收到中断信号时取消
+//   - cl: 颜色库
+//
+// 注意:
+//   - 没有找到任何匹配项时返回退出码为 exitcode.Failure 的错误(不携带错误信息)
+func FindCmdMain(ctx context.Context, cl *colorlib.ColorLib) error {
 	// 获取第一个参数作为查找路径
 	findPath := findCmd.Arg(0)
 	if findPath == "" {
@@ -48,7 +57,7 @@ func FindCmdMain(cl *colorlib.ColorLib) error {
 	searcher := NewFileSearcher(config, matcher, operator)
 
 	// 单线程搜索
-	if err := searcher.Search(findPath); err != nil {
+	if err := searcher.Search(ctx, findPath); err != nil {
 		return err
 	}
 
@@ -57,6 +66,9 @@ func FindCmdMain(cl *colorlib.ColorLib) error {
 		fmt.Println(config.MatchCount.Load())
 	}
 
+	if config.MatchCount.Load() == 0 {
+		return exitcode.New(exitcode.Failure, nil)
+	}
 	return nil
 }
 
@@ -42,7 +42,7 @@ var (
 
 func InitFindCmd() *qflag.Cmd {
 	// fck find 子命令
-	findCmd = qflag.NewCmd("find", "f", flag.ExitOnError)
+	findCmd = qflag.NewCmd("find", "f", flag.ContinueOnError)
 
 	findCmdCfg := qflag.CmdConfig{
 		UseChinese:  true,
@@ -3,6 +3,7 @@
 package find
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"strings"
 
 	"gitee.com/MM-Q/fck/commands/internal/common"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
 	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
@@ -19,6 +21,7 @@ type FileSearcher struct {
 	config   *types.FindConfig // 查找配置
 	matcher  *PatternMatcher   // 模式匹配器
 	operator *FileOperator     // 文件操作器
+	failed   int               // 操作执行失败的匹配项数量
 }
 
 // NewFileSearcher 创建新的文件搜索器
@@ -33,16 +36,23 @@ func NewFileSearcher(config *types.FindConfig, matcher *PatternMatcher, operator
 // Search 执行文件搜索
 //
 // 参数:
+//   - ctx: 上下文, 取消后停止遍历
 //   - findPath: 查找路径
 //
 // 返回:
-//   - error: 搜索错误（如果有）
-func (s *FileSearcher) Search(findPath string) error {
+//   - error: 搜索错误（如果有）, 部分匹配项的操作执行失败时返回退出码为 exitcode.Partial 的错误,
+//     上下文取消时返回 exitcode.ErrInterrupted
+func (s *FileSearcher) Search(ctx context.Context, findPath string) error {
 	// 获取静默模式标志，避免在遍历过程中频繁获取
 	quietMode := findCmdQuiet.Get()
 
 	// 使用 filepath.WalkDir 遍历目录
 	walkDirErr := filepath.WalkDir(findPath, func(path string, entry os.DirEntry, err error) error {
+		// 收到中断信号时停止遍历
+		if ctx.Err() != nil {
+			return filepath.SkipAll
+		}
+
 		// 检查遍历过程中是否遇到错误
 		if err != nil {
 			// 忽略不存在的报错
@@ -92,7 +102,13 @@ func (s *FileSearcher) Search(findPath string) error {
 	if walkDirErr != nil {
 		return fmt.Errorf("遍历目录时出错: %v", walkDirErr)
 	}
+	if ctx.Err() != nil {
+		return exitcode.ErrInterrupted
+	}
 
+	if s.failed > 0 {
+		return exitcode.Partialf("%d 个匹配项的操作执行失败", s.failed)
+	}
 	return nil
 }
 
@@ -389,14 +405,19 @@ func (s *FileSearcher) matchType(entry os.DirEntry, path, entryExt string, cache
 //   - path: 文件或目录的完整路径
 //
 // 返回:
-//   - error: 如果发生错误，则返回错误信息；否则返回nil
+//   - error: 目录已被删除或移动时返回 filepath.SkipDir, 否则返回nil
+//
+// 注意:
+//   - 操作失败时输出错误信息并记录失败数量, 继续处理其余匹配项
 func (s *FileSearcher) executeAction(entry os.DirEntry, path string) error {
 	// 如果启用了count标志, 则不执行任何操作
 	if !findCmdCount.Get() {
 		// 如果启用了delete标志, 删除匹配的文件或目录
 		if findCmdDelete.Get() {
+			s.config.MatchCount.Add(1)
 			if err := s.operator.Delete(path); err != nil {
-				return err
+				s.actionFailed(err)
+				return nil
 			}
 			// 如果是目录, 跳过整个目录
 			if entry.IsDir() {
@@ -407,8 +428,10 @@ func (s *FileSearcher) executeAction(entry os.DirEntry, path string) error {
 
 		// 如果启用了-mv标志, 将匹配的文件或目录移动到指定位置
 		if findCmdMove.Get() != "" {
+			s.config.MatchCount.Add(1)
 			if err := s.operator.Move(path, findCmdMove.Get()); err != nil {
-				return err
+				s.actionFailed(err)
+				return nil
 			}
 			// 如果是目录, 跳过整个目录
 			if entry.IsDir() {
@@ -419,8 +442,9 @@ func (s *FileSearcher) executeAction(entry os.DirEntry, path string) error {
 
 		// 如果启用了-exec标志, 执行指定的命令
 		if findCmdExec.Get() != "" {
+			s.config.MatchCount.Add(1)
 			if err := s.operator.Execute(findCmdExec.Get(), path); err != nil {
-				return fmt.Errorf("执行-exec命令时发生了错误: %v", err)
+				s.actionFailed(fmt.Errorf("执行-exec命令时发生了错误: %v", err))
 			}
 			return nil
 		}
@@ -431,6 +455,15 @@ func (s *FileSearcher) executeAction(entry os.DirEntry, path string) error {
 	return nil
 }
 
+// actionFailed 输出操作失败的错误信息并记录失败数量
+//
+// 参数:
+//   - err: 操作返回的错误
+func (s *FileSearcher) actionFailed(err error) {
+	s.failed++
+	s.config.Cl.PrintErrorf("%v\n", err)
+}
+
 // outputResult 输出搜索结果
 //
 // 参数:
@@ -1,6 +1,7 @@
 package find
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,6 +9,7 @@ import (
 	"testing"
 
 	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
 	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
@@ -110,7 +112,7 @@ func TestFileSearcher_Search(t *testing.T) {
 			operator := NewFileOperator(config.Cl)
 			searcher := NewFileSearcher(config, matcher, operator)
 
-			err := searcher.Search(tempDir)
+			err := searcher.Search(context.Background(), tempDir)
 
 			if tt.expectError && err == nil {
 				t.Errorf("期望错误但没有返回错误")
@@ -130,6 +132,39 @@ func TestFileSearcher_Search(t *testing.T) {
 	}
 }
 
+func TestFileSearcher_SearchActionFailed(t *testing.T) {
+	initTestFlags() // 确保标志变量已初始化
+	tempDir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+
+	// 使用不存在的命令, 使每个匹配项的操作都执行失败
+	_ = findCmdExec.Set("fck-test-no-such-command {}")
+	defer func() { _ = findCmdExec.Set("") }()
+
+	config := &types.FindConfig{
+		Cl:         colorlib.NewColorLib(),
+		MatchCount: &atomic.Int64{},
+	}
+	config.FindExtSliceMap.Store(".txt", true)
+	searcher := NewFileSearcher(config, NewPatternMatcher(100), NewFileOperator(config.Cl))
+
+	// 操作失败不中断搜索, 全部匹配项处理完后返回部分失败错误
+	err := searcher.Search(context.Background(), tempDir)
+	if exitcode.Code(err) != exitcode.Partial {
+		t.Fatalf("期望返回部分失败错误, 实际: %v", err)
+	}
+	if searcher.failed != 2 {
+		t.Errorf("失败数量错误, 期望 2, 得到 %d", searcher.failed)
+	}
+	if count := config.MatchCount.Load(); count != 2 {
+		t.Errorf("匹配数量错误, 期望 2, 得到 %d", count)
+	}
+}
+
 func TestFileSearcher_NeedFileInfo(t *testing.T) {
 	initTestFlags() // 确保标志变量已初始化
 	config := &types.FindConfig{
@@ -182,7 +217,7 @@ func BenchmarkFileSearcher_Search(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		config.MatchCount.Store(0) // 重置计数器
-		err := searcher.Search(tempDir)
+		err := searcher.Search(context.Background(), tempDir)
 		if err != nil {
 			b.Fatalf("搜索失败: %v", err)
 		}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
 	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
@@ -29,7 +30,7 @@ func (v *ConfigValidator) ValidateArgs(findPath string) error {
 
 	// 验证标志参数
 	if err := v.ValidateFlags(); err != nil {
-		return err
+		return exitcode.New(exitcode.Usage, err)
 	}
 
 	return nil
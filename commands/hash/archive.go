@@ -0,0 +1,170 @@
+// Package hash 实现了压缩包内文件的哈希计算。
+// 该文件负责 --archive 模式: 不解压压缩包, 按包内顺序流式读取每个文件条目并计算哈希值, 记录路径为 <压缩包>!/<条目路径>。
+package hash
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/archive"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+)
+
+// archiveSource 待计算的压缩包
+type archiveSource struct {
+	path  string // 压缩包路径(用于读取)
+	label string // 输出记录中的压缩包路径(便携模式写入文件时为相对路径)
+}
+
+// NewArchiveHashTaskManager 创建压缩包哈希任务管理器
+//
+// 参数:
+//   - archives: 压缩包列表
+//   - hashType: 哈希类型
+//
+// 返回值:
+//   - *HashTaskManager: 哈希任务管理器
+func NewArchiveHashTaskManager(archives []archiveSource, hashType string) *HashTaskManager {
+	m := newHashTaskManager(hashType, 1)
+	m.archives = archives
+	return m
+}
+
+// archiveCmdMain 计算压缩包内所有文件条目的哈希值
+//
+// 参数:
+//   - cl: 颜色库对象
+//   - targetPaths: 压缩包路径列表
+//
+// 返回:
+//   - error: 参数无效或签名失败时返回错误, 压缩包读取失败时输出错误信息并返回部分失败错误
+func archiveCmdMain(cl *colorlib.ColorLib, targetPaths []string) error {
+	if len(targetPaths) == 0 {
+		return exitcode.Usagef("--archive 需要指定至少一个压缩包路径")
+	}
+	if hashCmdTree.Get() || hashCmdUpdate.Get() || hashCmdChunkBytes > 0 {
+		return exitcode.Usagef("--archive 不能与 --tree、--update 或 --chunk-size 同时使用")
+	}
+	if hashCmdFilter != nil {
+		return exitcode.Usagef("--include、--exclude 和 --ignore-file 不能与 --archive 同时使用")
+	}
+
+	// 便携模式写入文件时, 压缩包路径转换为相对路径
+	relative := hashCmdWrite.Get() && !hashCmdLocal.Get()
+	var basePath string
+	if relative {
+		var err error
+		if basePath, err = relativeBasePath(); err != nil {
+			return fmt.Errorf("转换相对路径失败: %w", err)
+		}
+	}
+
+	archives := make([]archiveSource, 0, len(targetPaths))
+	for _, targetPath := range targetPaths {
+		targetPath = filepath.Clean(targetPath)
+		if !archive.IsSupported(targetPath) {
+			return exitcode.Usagef("不支持的压缩包格式: %s", targetPath)
+		}
+
+		label := targetPath
+		if relative {
+			var err error
+			if label, err = relativePath(basePath, targetPath); err != nil {
+				return fmt.Errorf("转换相对路径失败: %w", err)
+			}
+		}
+		archives = append(archives, archiveSource{path: targetPath, label: label})
+	}
+
+	if hashCmdWrite.Get() {
+		cl.PrintOk("正在将哈希值写入文件，请稍候...")
+	}
+
+	// 执行哈希任务
+	manager := NewArchiveHashTaskManager(archives, hashCmdType.Get())
+	errors := manager.Run()
+	if hashCmdCtx.Err() != nil {
+		return exitcode.ErrInterrupted
+	}
+	if len(errors) > 0 {
+		printUniqueErrors(cl, errors)
+		if hashCmdWrite.Get() && manager.walkErr != nil {
+			cl.PrintErrorf("存在无法读取的压缩包, 未写入校验文件 %s\n", strings.Join(outputFileNames(hashCmdType.Get()), ", "))
+		}
+		return failedError(len(errors))
+	}
+
+	count := manager.processedCount.Load()
+	if count == 0 {
+		cl.PrintWarnf("压缩包中没有找到任何文件\n")
+		return nil
+	}
+	if !hashCmdWrite.Get() {
+		return nil
+	}
+
+	names := outputFileNames(hashCmdType.Get())
+	cl.PrintOkf("已将哈希值写入文件 %s, 共处理 %d 个文件\n", strings.Join(names, ", "), count)
+	return signChecksumFiles(cl, names)
+}
+
+// processArchives 依次读取所有压缩包, 计算每个文件条目的哈希值并发送结果
+//
+// 注意:
+//   - 压缩包无法读取或已损坏时记录错误并继续处理下一个压缩包, 写入文件时放弃写入并保留原校验文件
+//   - 条目数据经过 --bwlimit 限速器读取
+func (m *HashTaskManager) processArchives() {
+	index := 0
+	for _, src := range m.archives {
+		err := archive.Walk(src.path, func(name string, r io.Reader) error {
+			// 按顺序输出时, 等待重排窗口有空位后再计算
+			if m.ordered {
+				select {
+				case m.window <- struct{}{}:
+				case <-m.ctx.Done():
+					return context.Cause(m.ctx)
+				}
+			}
+
+			result := HashResult{
+				Index:    index,                         // 条目序号
+				FilePath: archive.Join(src.label, name), // 条目路径
+			}
+			result.HashValues, result.Size, result.Error = digest.HashReaderMulti(digest.LimitReader(r), m.hashTypes)
+			if result.Error == nil {
+				result.HashValue = result.HashValues[0]
+			} else {
+				result.Error = fmt.Errorf("计算 %s 的哈希值失败: %w", result.FilePath, result.Error)
+				m.setWalkErr(result.Error)
+			}
+
+			index++
+			m.discoveredCount.Add(1)
+			m.sendResult(result)
+			return nil
+		})
+		if err != nil {
+			err = fmt.Errorf("读取压缩包 %s 失败: %w", src.path, err)
+			m.setWalkErr(err)
+			m.addError(err)
+		}
+	}
+}
+
+// setWalkErr 记录第一个压缩包读取错误
+//
+// 参数:
+//   - err: 错误信息
+//
+// 注意:
+//   - 仅由压缩包读取协程调用, 写入协程在计算任务全部完成后据此放弃写入
+func (m *HashTaskManager) setWalkErr(err error) {
+	if m.walkErr == nil {
+		m.walkErr = err
+	}
+}
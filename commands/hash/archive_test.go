@@ -0,0 +1,182 @@
+package hash
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// writeTestZip 创建包含指定文件的 zip 压缩包
+func writeTestZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("创建压缩包失败: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	zw := zip.NewWriter(f)
+	if _, err := zw.Create("dir/"); err != nil {
+		t.Fatalf("写入目录条目失败: %v", err)
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("写入条目失败: %v", err)
+		}
+		_, _ = w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("关闭压缩包失败: %v", err)
+	}
+}
+
+// writeTestTgz 创建包含指定文件的 tar.gz 压缩包
+func writeTestTgz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("创建压缩包失败: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("写入条目失败: %v", err)
+		}
+		_, _ = tw.Write([]byte(content))
+	}
+	_ = tw.WriteHeader(&tar.Header{Name: "link", Linkname: "a.txt", Typeflag: tar.TypeSymlink})
+	if err := tw.Close(); err != nil {
+		t.Fatalf("关闭 tar 失败: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("关闭 gzip 失败: %v", err)
+	}
+}
+
+// TestHashCmdMainArchive 测试 --archive 计算压缩包内文件的哈希值
+func TestHashCmdMainArchive(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	writeTestZip(t, "d.zip", map[string]string{"dir/a.txt": "a"})
+	writeTestTgz(t, "d.tgz", map[string]string{"./b.txt": "b"})
+	if err := os.WriteFile("plain.txt", []byte("x"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	// 无效的参数组合
+	tests := []struct {
+		name string
+		args []string
+		set  func()
+	}{
+		{name: "未指定压缩包", args: nil},
+		{name: "不支持的格式", args: []string{"plain.txt"}},
+		{name: "目录树模式", args: []string{"d.zip"}, set: func() { _ = hashCmdTree.Set("true") }},
+		{name: "路径过滤", args: []string{"d.zip"}, set: func() { _ = hashCmdExclude.Set("*.txt") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashCmd = InitHashCmd()
+			_ = hashCmd.Parse(tt.args)
+			_ = hashCmdArchive.Set("true")
+			if tt.set != nil {
+				tt.set()
+			}
+			if err := HashCmdMain(context.Background(), cl); err == nil {
+				t.Error("期望错误但没有发生错误")
+			}
+		})
+	}
+
+	hashCmd = InitHashCmd()
+	_ = hashCmd.Parse([]string{"d.zip", "d.tgz"})
+	_ = hashCmdArchive.Set("true")
+	_ = hashCmdWrite.Set("true")
+	if err := HashCmdMain(context.Background(), cl); err != nil {
+		t.Fatalf("HashCmdMain() 返回错误: %v", err)
+	}
+
+	content, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+
+	// 目录和软链接条目被跳过, 条目路径去掉开头的 ./
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	expected := []string{
+		types.FormatChecksumLine(types.ChecksumFormatFck, "", "0cc175b9c0f1b6a831c399e269772661", "d.zip!/dir/a.txt"),
+		types.FormatChecksumLine(types.ChecksumFormatFck, "", "92eb5ffee6ae2fec3ad71c777531578f", "d.tgz!/b.txt"),
+	}
+	if len(lines) != 3 || lines[1]+"\n" != expected[0] || lines[2]+"\n" != expected[1] {
+		t.Errorf("校验文件内容不正确:\n%s", content)
+	}
+}
+
+// TestHashCmdMainArchiveCorrupt 测试压缩包损坏时放弃写入校验文件
+func TestHashCmdMainArchiveCorrupt(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	writeTestZip(t, "d.zip", map[string]string{"dir/a.txt": "a"})
+	writeTestTgz(t, "full.tgz", map[string]string{"b.txt": strings.Repeat("b", 4096)})
+
+	// 截断 tar.gz, 模拟下载不完整的压缩包
+	data, err := os.ReadFile("full.tgz")
+	if err != nil {
+		t.Fatalf("读取压缩包失败: %v", err)
+	}
+	if err := os.WriteFile("bad.tgz", data[:len(data)/2], 0644); err != nil {
+		t.Fatalf("创建截断的压缩包失败: %v", err)
+	}
+
+	old := "old manifest\n"
+	if err := os.WriteFile(types.OutputFileName, []byte(old), 0644); err != nil {
+		t.Fatalf("创建校验文件失败: %v", err)
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	hashCmd = InitHashCmd()
+	_ = hashCmd.Parse([]string{"d.zip", "bad.tgz"})
+	_ = hashCmdArchive.Set("true")
+	_ = hashCmdWrite.Set("true")
+	if err := HashCmdMain(context.Background(), cl); err == nil {
+		t.Fatal("期望压缩包损坏时返回错误")
+	}
+
+	// 校验文件保持不变
+	content, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+	if string(content) != old {
+		t.Errorf("压缩包损坏时不应覆盖校验文件:\n%s", content)
+	}
+}
@@ -3,38 +3,423 @@
 package hash
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/common"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/hashcache"
+	"gitee.com/MM-Q/fck/commands/internal/metadata"
+	"gitee.com/MM-Q/fck/commands/internal/output"
+	"gitee.com/MM-Q/fck/commands/internal/pathfilter"
+	"gitee.com/MM-Q/fck/commands/internal/ratelimit"
 	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
+// hashCmdCtx 本次运行的上下文(收到中断信号时取消)
+var hashCmdCtx = context.Background()
+
+// hashCmdCache 本次运行使用的哈希缓存(未启用或打开失败时为nil)
+var hashCmdCache *hashcache.Cache
+
+// hashCmdFilter 本次运行使用的路径过滤器(未指定过滤条件时为nil)
+var hashCmdFilter *pathfilter.Filter
+
+// hashCmdWorkers 本次运行指定的并发数(0表示自动)
+var hashCmdWorkers int
+
+// hashCmdChunkBytes 本次运行指定的分块大小(0表示不分块)
+var hashCmdChunkBytes int64
+
+// hashCmdMetaFields 本次运行记录的元数据字段(为nil时不记录元数据)
+var hashCmdMetaFields []string
+
+// hashCmdLinkPolicy 本次运行的软链接处理策略(fck 格式的校验文件始终记录该策略, 为空时跳过软链接)
+var hashCmdLinkPolicy string
+
+// hashCmdRecorder 本次运行使用的结构化输出记录器(文本输出时为nil)
+var hashCmdRecorder *hashRecorder
+
 // HashCmdMain 是 hash 子命令的主函数
-func HashCmdMain(cl *colorlib.ColorLib) error {
-	// 验证输入参数
+//
+// 参数:
+//   - ctx: 上下文, 取消后停止计算, 放弃写入校验文件并返回 exitcode.ErrInterrupted
+//   - cl: 颜色库对象
+//
+// 返回:
+//   - error: 错误信息
+func HashCmdMain(ctx context.Context, cl *colorlib.ColorLib) error {
+	hashCmdCtx = ctx
+	defer func() { hashCmdCtx = context.Background() }()
+
+	// 标准输入和文本没有可写入校验文件的路径
 	targetPaths := hashCmd.Args()
+	if hashCmdString.IsSet() || slices.Contains(targetPaths, stdinPath) {
+		if hashCmdWrite.Get() || hashCmdTree.Get() {
+			return exitcode.Usagef("标准输入(-)和 --string 不能与 -w 或 --tree 同时使用")
+		}
+	}
+
+	// 增量更新需要写入校验文件
+	if hashCmdUpdate.Get() && !hashCmdWrite.Get() {
+		return exitcode.Usagef("--update 需要与 -w 一起使用")
+	}
+	if hashCmdUpdate.Get() && hashCmdFormat.Get() != types.ChecksumFormatFck {
+		return exitcode.Usagef("--update 仅支持 fck 格式的校验文件, GNU/BSD 格式无法记录文件大小和修改时间")
+	}
+
+	// 检查并发数和读取带宽限制
+	if hashCmdJobs.Get() < 0 {
+		return exitcode.Usagef("--jobs 不能为负数: %d", hashCmdJobs.Get())
+	}
+	hashCmdWorkers = hashCmdJobs.Get()
+	defer func() { hashCmdWorkers = 0 }()
+
+	if hashCmdBwLimit.Get() != "" {
+		rate, err := ratelimit.ParseRate(hashCmdBwLimit.Get())
+		if err != nil {
+			return exitcode.New(exitcode.Usage, err)
+		}
+		digest.SetReadLimiter(ratelimit.New(rate))
+		defer digest.SetReadLimiter(nil)
+	}
+
+	// 检查分块大小
+	if hashCmdChunkSize.Get() != "" {
+		chunkSize, err := parseChunkSize()
+		if err != nil {
+			return err
+		}
+		hashCmdChunkBytes = chunkSize
+		defer func() { hashCmdChunkBytes = 0 }()
+	}
+
+	// 检查元数据选项
+	if hashCmdMetadata.Get() || hashCmdXattrs.Get() {
+		fields, err := metadataFields()
+		if err != nil {
+			return err
+		}
+		hashCmdMetaFields = fields
+		defer func() { hashCmdMetaFields = nil }()
+	}
+
+	// 检查软链接处理策略
+	policy, err := symlinkPolicy()
+	if err != nil {
+		return err
+	}
+	hashCmdLinkPolicy = policy
+	defer func() { hashCmdLinkPolicy = "" }()
+
+	// 读取 HMAC 密钥
+	if hashCmdHMACKey.Get() != "" {
+		if err := setupHMAC(); err != nil {
+			return err
+		}
+		defer digest.SetHMACKey(nil)
+	}
+
+	// 读取签名私钥
+	signKey, err := loadSignKey()
+	if err != nil {
+		return err
+	}
+	hashCmdSignKey = signKey
+	defer func() { hashCmdSignKey = nil }()
+
+	// 创建路径过滤器
+	filter, err := newPathFilter()
+	if err != nil {
+		return err
+	}
+	if filter != nil && hashCmdTree.Get() {
+		return exitcode.Usagef("--include、--exclude 和 --ignore-file 不能与 --tree 同时使用")
+	}
+	hashCmdFilter = filter
+	defer func() { hashCmdFilter = nil }()
+
+	// 结构化输出: 标准输出仅保留记录, 提示信息改为输出到标准错误
+	if output.IsStructured(hashCmdOutput.Get()) {
+		if hashCmdWrite.Get() || hashCmdTree.Get() {
+			return exitcode.Usagef("--output %s 不能与 -w 或 --tree 同时使用", hashCmdOutput.Get())
+		}
+
+		recorder, err := newHashRecorder(os.Stdout, hashCmdOutput.Get())
+		if err != nil {
+			return err
+		}
+		hashCmdRecorder = recorder
+		defer func() {
+			if err := hashCmdRecorder.close(); err != nil {
+				cl.PrintErrorf("输出汇总信息失败: %v\n", err)
+			}
+			hashCmdRecorder = nil
+		}()
+
+		cl = colorlib.NewColorLibWithWriter(os.Stderr)
+	}
+
+	// 打开哈希缓存
+	hashCmdCache = openHashCache(cl)
+	if hashCmdCache != nil {
+		defer func() {
+			_ = hashCmdCache.Close()
+			hashCmdCache = nil
+		}()
+	}
+
+	// 计算文本的哈希值, 未指定路径时不再处理当前目录
+	var failed int // 处理失败的文件或路径数量
+	if hashCmdString.IsSet() {
+		failed += processString(cl, hashCmdString.Get(), hashCmdType.Get())
+		if len(targetPaths) == 0 {
+			return failedError(failed)
+		}
+	}
+
+	// 仅清理缓存时不执行哈希计算
+	if hashCmdPrune.Get() && len(targetPaths) == 0 {
+		return nil
+	}
+
+	// 压缩包模式
+	if hashCmdArchive.Get() {
+		return archiveCmdMain(cl, targetPaths)
+	}
+
+	// 目录 Merkle 树模式
+	if hashCmdTree.Get() {
+		return treeCmdMain(cl, targetPaths)
+	}
+
+	// 验证输入参数
 	if len(targetPaths) == 0 {
 		targetPaths = []string{"*"} // 默认处理当前目录
 	}
 
-	// 显示写入文件提示
 	if hashCmdWrite.Get() {
-		cl.PrintOk("正在将哈希值写入文件，请稍候...")
+		// 所有目标路径写入同一组校验文件
+		if err := writeChecksumFiles(cl, targetPaths, hashCmdType.Get()); err != nil {
+			return err
+		}
+	} else {
+		// 遍历所有目标路径
+		for _, targetPath := range targetPaths {
+			// 标准输入
+			if targetPath == stdinPath {
+				failed += processStdin(cl, hashCmdType.Get())
+				continue
+			}
+
+			n, err := processSinglePath(cl, filepath.Clean(targetPath), hashCmdType.Get())
+			if hashCmdCtx.Err() != nil {
+				return exitcode.ErrInterrupted
+			}
+			failed += n
+			if err != nil {
+				// 记录错误但继续处理其他路径
+				failed++
+				cl.PrintErrorf("处理路径 %s 时发生错误: %v\n", targetPath, err)
+				if hashCmdRecorder != nil {
+					_ = hashCmdRecorder.add(HashResult{FilePath: targetPath, Error: err}, []string{hashCmdType.Get()})
+				}
+			}
+		}
+	}
+
+	// 显示缓存命中统计
+	if hashCmdStats.Get() && hashCmdCache != nil {
+		hits, misses := hashCmdCache.Stats()
+		cl.PrintInfof("缓存统计: 命中 %d 个, 未命中 %d 个\n", hits, misses)
+	}
+
+	return failedError(failed)
+}
+
+// failedError 根据处理失败的数量生成部分失败错误
+//
+// 参数:
+//   - failed: 处理失败的文件或路径数量
+//
+// 返回:
+//   - error: 全部成功时返回nil, 否则返回退出码为 exitcode.Partial 的错误
+func failedError(failed int) error {
+	if failed == 0 {
+		return nil
+	}
+	return exitcode.Partialf("共 %d 个文件或路径处理失败", failed)
+}
+
+// parseChunkSize 解析并检查 --chunk-size 标志
+//
+// 返回:
+//   - int64: 分块大小(字节)
+//   - error: 格式无效或与其他标志冲突时返回错误
+func parseChunkSize() (int64, error) {
+	if !hashCmdWrite.Get() {
+		return 0, exitcode.Usagef("--chunk-size 需要与 -w 一起使用")
+	}
+	if hashCmdTree.Get() || hashCmdUpdate.Get() {
+		return 0, exitcode.Usagef("--chunk-size 不能与 --tree 或 --update 同时使用")
+	}
+	if hashCmdFormat.Get() != types.ChecksumFormatFck {
+		return 0, exitcode.Usagef("--chunk-size 仅支持 fck 格式的校验文件")
+	}
+
+	chunkSize, err := common.ParseSize(hashCmdChunkSize.Get())
+	if err != nil {
+		return 0, exitcode.Usagef("无效的分块大小: %s (示例: 64M、1G)", hashCmdChunkSize.Get())
+	}
+	return chunkSize, nil
+}
+
+// metadataFields 检查 --with-metadata 相关标志并返回记录的元数据字段
+//
+// 返回:
+//   - []string: 元数据字段列表
+//   - error: 与其他标志冲突时返回错误
+func metadataFields() ([]string, error) {
+	if !hashCmdMetadata.Get() {
+		return nil, exitcode.Usagef("--xattrs 需要与 --with-metadata 一起使用")
+	}
+	if !hashCmdWrite.Get() {
+		return nil, exitcode.Usagef("--with-metadata 需要与 -w 一起使用")
+	}
+	if hashCmdTree.Get() || hashCmdUpdate.Get() || hashCmdArchive.Get() {
+		return nil, exitcode.Usagef("--with-metadata 不能与 --tree、--update 或 --archive 同时使用")
+	}
+	if hashCmdFormat.Get() != types.ChecksumFormatFck {
+		return nil, exitcode.Usagef("--with-metadata 仅支持 fck 格式的校验文件")
+	}
+
+	fields := slices.Clone(metadata.DefaultFields)
+	if hashCmdXattrs.Get() {
+		fields = append(fields, metadata.FieldXattr)
+	}
+	return fields, nil
+}
+
+// symlinkPolicy 检查 --symlinks 标志并返回本次运行的软链接处理策略
+//
+// 返回:
+//   - string: 软链接处理策略(--tree 和 --archive 模式不使用软链接策略, 返回空字符串)
+//   - error: 与其他标志冲突时返回错误
+//
+// 注意:
+//   - 记录元数据时默认使用 link 策略, 元数据描述的是软链接本身, 因此不能跟随软链接
+//   - GNU/BSD 格式的校验文件无法记录软链接策略, 写入这两种格式时不能指定 --symlinks
+func symlinkPolicy() (string, error) {
+	if hashCmdSymlinks.IsSet() {
+		if hashCmdTree.Get() || hashCmdArchive.Get() {
+			return "", exitcode.Usagef("--symlinks 不能与 --tree 或 --archive 同时使用(--tree 模式请使用 --tree-symlinks)")
+		}
+		if hashCmdWrite.Get() && hashCmdFormat.Get() != types.ChecksumFormatFck {
+			return "", exitcode.Usagef("--symlinks 仅支持 fck 格式的校验文件, GNU/BSD 格式无法记录软链接策略")
+		}
+	}
+	if hashCmdTree.Get() || hashCmdArchive.Get() {
+		return "", nil
 	}
 
-	// 遍历所有目标路径
-	for _, targetPath := range targetPaths {
-		if err := processSinglePath(cl, filepath.Clean(targetPath), hashCmdType.Get()); err != nil {
-			// 记录错误但继续处理其他路径
-			cl.PrintErrorf("处理路径 %s 时发生错误: %v\n", targetPath, err)
+	policy := hashCmdSymlinks.Get()
+	if hashCmdMetaFields != nil {
+		if policy == types.SymlinksFollow {
+			return "", exitcode.Usagef("--with-metadata 记录软链接本身的元数据, 不能与 --symlinks follow 同时使用")
+		}
+		if !hashCmdSymlinks.IsSet() {
+			policy = types.SymlinksLink
 		}
 	}
+	return policy, nil
+}
 
+// setupHMAC 读取 HMAC 密钥并启用 HMAC 模式
+//
+// 返回:
+//   - error: 密钥无法读取、算法不支持 HMAC 或校验文件格式无法记录 HMAC 模式时返回错误
+func setupHMAC() error {
+	hashTypes, err := digest.ParseAlgorithms(hashCmdType.Get())
+	if err != nil {
+		return err
+	}
+	for _, hashType := range hashTypes {
+		if !digest.IsHMACSupported(hashType) {
+			return exitcode.Usagef("--hmac-key-file 不支持非加密哈希算法: %s", hashType)
+		}
+	}
+
+	// 只有 fck 格式的文件头能记录 HMAC 模式
+	if (hashCmdWrite.Get() || hashCmdTree.Get()) && hashCmdFormat.Get() != types.ChecksumFormatFck {
+		return exitcode.Usagef("--hmac-key-file 写入校验文件时仅支持 fck 格式")
+	}
+
+	key, err := digest.LoadHMACKey(hashCmdHMACKey.Get())
+	if err != nil {
+		return err
+	}
+	digest.SetHMACKey(key)
 	return nil
 }
 
+// openHashCache 根据缓存标志打开哈希缓存, 并执行清空或清理操作
+//
+// 参数:
+//   - cl: 颜色库对象
+//
+// 返回:
+//   - *hashcache.Cache: 哈希缓存, 未启用或打开失败时返回nil
+//
+// 注意:
+//   - 缓存不可用(如被其他进程占用)时仅输出警告, 不影响哈希计算
+//   - HMAC 模式下不使用缓存, 仅执行清理操作
+func openHashCache(cl *colorlib.ColorLib) *hashcache.Cache {
+	mode := hashCmdCacheMode.Get()
+	if digest.HMACEnabled() {
+		mode = hashcache.ModeOff // HMAC 摘要依赖密钥, 不能与普通哈希值共用缓存
+	}
+	if mode == hashcache.ModeOff && !hashCmdPrune.Get() {
+		return nil
+	}
+
+	cache, err := hashcache.OpenDefault()
+	if err != nil {
+		cl.PrintWarnf("哈希缓存不可用, 将直接计算哈希值: %v\n", err)
+		return nil
+	}
+
+	// 重建模式: 清空已有缓存
+	if mode == hashcache.ModeRebuild {
+		if err := cache.Clear(); err != nil {
+			cl.PrintWarnf("清空哈希缓存失败: %v\n", err)
+		}
+	}
+
+	// 清理失效的缓存条目
+	if hashCmdPrune.Get() {
+		removed, err := cache.Prune()
+		if err != nil {
+			cl.PrintWarnf("清理哈希缓存失败: %v\n", err)
+		} else {
+			cl.PrintOkf("已清理 %d 个失效的缓存条目\n", removed)
+		}
+	}
+
+	// 仅清理时不在计算中使用缓存
+	if mode == hashcache.ModeOff {
+		_ = cache.Close()
+		return nil
+	}
+
+	return cache
+}
+
 // processSinglePath 处理单个路径
 //
 // 参数:
@@ -43,41 +428,107 @@ func HashCmdMain(cl *colorlib.ColorLib) error {
 //   - hashType: 哈希算法类型
 //
 // 返回:
-//   - error: 错误信息
-func processSinglePath(cl *colorlib.ColorLib, targetPath string, hashType string) error {
-	// 收集文件
-	files, err := collectFiles(targetPath, hashCmdRecursion.Get(), cl)
-	if err != nil {
-		return fmt.Errorf("收集文件失败: %w", err)
+//   - int: 计算失败的文件数量
+//   - error: 路径无效或遍历失败时返回错误
+//
+// 注意:
+//   - 遍历到的文件立即交给计算协程, 遍历出错时已发现文件的结果仍会输出
+func processSinglePath(cl *colorlib.ColorLib, targetPath string, hashType string) (int, error) {
+	count := 0
+	manager := NewStreamHashTaskManager(func(visit visitFunc) error {
+		return walkTargetFiles(cl, targetPath, func(file string) bool {
+			count++
+			return visit(file)
+		})
+	}, hashType)
+
+	// 执行哈希任务
+	errors := manager.Run()
+	if hashCmdCtx.Err() != nil {
+		return len(errors), exitcode.ErrInterrupted
+	}
+	printUniqueErrors(cl, errors)
+	if manager.walkErr != nil {
+		return len(errors), manager.walkErr
 	}
 
 	// 检查文件列表是否为空
-	if len(files) == 0 {
+	if count == 0 {
 		cl.PrintWarnf("路径 %s 没有找到任何文件\n", targetPath)
-		return nil
+		return 0, nil
+	}
+
+	// 处理执行结果
+	if len(errors) == 0 && hashCmdWrite.Get() {
+		cl.PrintOkf("已将哈希值写入文件 %s, 共处理 %d 个文件\n", strings.Join(outputFileNames(hashType), ", "), count)
 	}
 
+	return len(errors), nil
+}
+
+// walkTargetFiles 流式遍历单个路径下需要计算的文件
+//
+// 参数:
+//   - cl: 颜色库对象
+//   - targetPath: 目标路径
+//   - visit: 文件处理函数(便携模式写入文件时传入相对路径)
+//
+// 返回:
+//   - error: 错误信息
+func walkTargetFiles(cl *colorlib.ColorLib, targetPath string, visit visitFunc) error {
 	// 如果是便携模式且需要写入文件，转换为相对路径
-	if hashCmdWrite.Get() && !hashCmdLocal.Get() {
-		files, err = convertToRelativePaths(files)
-		if err != nil {
+	relative := hashCmdWrite.Get() && !hashCmdLocal.Get()
+
+	var basePath string
+	if relative {
+		var err error
+		if basePath, err = relativeBasePath(); err != nil {
 			return fmt.Errorf("转换相对路径失败: %w", err)
 		}
 	}
 
-	// 执行哈希任务
-	errors := hashRunTasksRefactored(files, hashType)
+	var convertErr error
+	err := walkFiles(targetPath, hashCmdRecursion.Get(), cl, func(file string) bool {
+		if relative {
+			relPath, err := relativePath(basePath, file)
+			if err != nil {
+				convertErr = err
+				return false
+			}
+			file = relPath
+		}
+		return visit(file)
+	})
 
-	// 处理执行结果
-	if len(errors) > 0 {
-		printUniqueErrors(cl, errors)
-	} else if hashCmdWrite.Get() {
-		cl.PrintOkf("已将哈希值写入文件 %s, 共处理 %d 个文件\n", types.OutputFileName, len(files))
+	if convertErr != nil {
+		return fmt.Errorf("转换相对路径失败: %w", convertErr)
+	}
+	if err != nil {
+		return fmt.Errorf("收集文件失败: %w", err)
 	}
-
 	return nil
 }
 
+// outputFileNames 获取写入的校验文件名列表
+//
+// 参数:
+//   - hashType: 哈希算法(多个算法以逗号分隔)
+//
+// 返回:
+//   - []string: 校验文件名列表
+func outputFileNames(hashType string) []string {
+	hashTypes, err := digest.ParseAlgorithms(hashType)
+	if err != nil || len(hashTypes) == 1 {
+		return []string{outputFileName(hashType, false)}
+	}
+
+	names := make([]string, len(hashTypes))
+	for i, t := range hashTypes {
+		names[i] = outputFileName(t, true)
+	}
+	return names
+}
+
 // convertToRelativePaths 将文件路径转换为相对路径
 //
 // 参数:
@@ -87,37 +538,62 @@ func processSinglePath(cl *colorlib.ColorLib, targetPath string, hashType string
 //   - []string: 转换后的相对路径列表
 //   - error: 错误信息
 func convertToRelativePaths(files []string) ([]string, error) {
-	// 获取基准路径
+	basePath, err := relativeBasePath()
+	if err != nil {
+		return nil, err
+	}
+
+	var relativePaths []string
+	for _, file := range files {
+		relPath, err := relativePath(basePath, file)
+		if err != nil {
+			return nil, err
+		}
+		relativePaths = append(relativePaths, relPath)
+	}
+	return relativePaths, nil
+}
+
+// relativeBasePath 获取便携模式下相对路径的基准路径
+//
+// 返回:
+//   - string: 基准路径(默认为当前工作目录)
+//   - error: 错误信息
+func relativeBasePath() (string, error) {
 	basePath := hashCmdBasePath.Get()
 	if basePath == "" {
 		var err error
 		basePath, err = os.Getwd() // 默认使用当前工作目录
 		if err != nil {
-			return nil, fmt.Errorf("获取当前工作目录失败: %v", err)
+			return "", fmt.Errorf("获取当前工作目录失败: %v", err)
 		}
 	}
+	return basePath, nil
+}
 
-	var relativePaths []string
-	for _, file := range files {
-		var relPath string
+// relativePath 将文件路径转换为相对于基准路径的相对路径
+//
+// 参数:
+//   - basePath: 基准路径
+//   - file: 文件路径
+//
+// 返回:
+//   - string: 使用正斜杠分隔的相对路径
+//   - error: 错误信息
+func relativePath(basePath, file string) (string, error) {
+	relPath := file
 
-		// 如果已经是相对路径，直接使用
-		if !filepath.IsAbs(file) {
-			relPath = file
-		} else {
-			// 将绝对路径转换为相对于basePath的相对路径
-			var err error
-			relPath, err = filepath.Rel(basePath, file)
-			if err != nil {
-				return nil, fmt.Errorf("无法转换路径 %s: %v", file, err)
-			}
+	// 将绝对路径转换为相对于basePath的相对路径, 已经是相对路径时直接使用
+	if filepath.IsAbs(file) {
+		var err error
+		relPath, err = filepath.Rel(basePath, file)
+		if err != nil {
+			return "", fmt.Errorf("无法转换路径 %s: %v", file, err)
 		}
-
-		// 统一使用正斜杠作为分隔符（跨平台兼容）
-		relPath = filepath.ToSlash(relPath)
-		relativePaths = append(relativePaths, relPath)
 	}
-	return relativePaths, nil
+
+	// 统一使用正斜杠作为分隔符（跨平台兼容）
+	return filepath.ToSlash(relPath), nil
 }
 
 // printUniqueErrors 去重并打印错误信息
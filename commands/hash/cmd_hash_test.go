@@ -1,13 +1,19 @@
 package hash
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
 	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
@@ -55,7 +61,7 @@ func TestProcessSinglePath(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := processSinglePath(cl, tt.path, "md5")
+			_, err := processSinglePath(cl, tt.path, "md5")
 
 			if tt.expectError && err == nil {
 				t.Errorf("processSinglePath() 期望返回错误，但没有错误")
@@ -138,7 +144,7 @@ func TestHashCmdMainWithWrite(t *testing.T) {
 	cl.SetColor(false)
 
 	// 测试写入功能
-	err := processSinglePath(cl, testFile, "md5")
+	_, err := processSinglePath(cl, testFile, "md5")
 	if err != nil {
 		t.Errorf("processSinglePath() 返回错误: %v", err)
 	}
@@ -183,7 +189,7 @@ func TestHashCmdMainWithRecursion(t *testing.T) {
 	cl := colorlib.NewColorLib()
 	cl.SetColor(false)
 
-	err := processSinglePath(cl, tempDir, "md5")
+	_, err := processSinglePath(cl, tempDir, "md5")
 	if err != nil {
 		t.Errorf("processSinglePath() 返回错误: %v", err)
 	}
@@ -221,7 +227,7 @@ func TestHashCmdMainWithGlob(t *testing.T) {
 
 	// 测试通配符处理
 	globPattern := "test*.txt"
-	err := processSinglePath(cl, globPattern, "md5")
+	_, err := processSinglePath(cl, globPattern, "md5")
 	if err != nil {
 		t.Logf("processSinglePath() 对通配符返回错误: %v (这可能是正常的)", err)
 	}
@@ -245,7 +251,7 @@ func TestHashCmdMainEmptyDirectory(t *testing.T) {
 	cl.SetColor(false)
 
 	// 空目录应该返回错误（非递归模式）
-	err := processSinglePath(cl, tempDir, "md5")
+	_, err := processSinglePath(cl, tempDir, "md5")
 	if err == nil {
 		t.Errorf("processSinglePath() 在空目录中应该返回错误")
 	}
@@ -276,6 +282,441 @@ func BenchmarkProcessSinglePath(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = processSinglePath(cl, testFile, "md5")
+		_, _ = processSinglePath(cl, testFile, "md5")
+	}
+}
+
+// TestHashCmdMainBwLimit 测试读取带宽限制
+func TestHashCmdMainBwLimit(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.bin")
+	if err := os.WriteFile(testFile, make([]byte, 96*1024), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	// 无效的参数
+	hashCmd = InitHashCmd()
+	_ = hashCmdBwLimit.Set("fast")
+	if err := HashCmdMain(context.Background(), cl); err == nil {
+		t.Error("无效的 --bwlimit 应返回错误")
+	}
+
+	hashCmd = InitHashCmd()
+	_ = hashCmdJobs.Set("-1")
+	if err := HashCmdMain(context.Background(), cl); err == nil {
+		t.Error("负数的 --jobs 应返回错误")
+	}
+
+	// 64K/s 的限速下, 读取 96K 数据需在首秒配额用完后再等待约0.5秒
+	hashCmd = InitHashCmd()
+	_ = hashCmdCacheMode.Set("off")
+	_ = hashCmdBwLimit.Set("64K/s")
+	if err := hashCmd.Parse([]string{testFile}); err != nil {
+		t.Fatalf("解析参数失败: %v", err)
+	}
+
+	start := time.Now()
+	if err := HashCmdMain(context.Background(), cl); err != nil {
+		t.Fatalf("HashCmdMain() 返回错误: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("限速未生效: 耗时 %v", elapsed)
+	}
+}
+
+// TestHashCmdMainExitCode 测试返回错误携带的退出码
+func TestHashCmdMainExitCode(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("test"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	tests := []struct {
+		name  string
+		flags map[string]string
+		args  []string
+		code  int
+	}{
+		{name: "全部成功", args: []string{testFile}, code: exitcode.OK},
+		{name: "部分路径不存在", args: []string{testFile, filepath.Join(tempDir, "missing.txt")}, code: exitcode.Partial},
+		{name: "无效的并发数", flags: map[string]string{"jobs": "-1"}, args: []string{testFile}, code: exitcode.Usage},
+		{name: "无效的带宽限制", flags: map[string]string{"bwlimit": "fast"}, args: []string{testFile}, code: exitcode.Usage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashCmd = InitHashCmd()
+			_ = hashCmdCacheMode.Set("off")
+			args := make([]string, 0, len(tt.flags)*2+len(tt.args))
+			for name, value := range tt.flags {
+				args = append(args, "--"+name, value)
+			}
+			if err := hashCmd.Parse(append(args, tt.args...)); err != nil {
+				t.Fatalf("解析参数失败: %v", err)
+			}
+
+			err := HashCmdMain(context.Background(), cl)
+			if code := exitcode.Code(err); code != tt.code {
+				t.Errorf("期望退出码 %d, 实际 %d (错误: %v)", tt.code, code, err)
+			}
+		})
+	}
+}
+
+// TestHashCmdMainHMAC 测试 HMAC 密钥模式
+func TestHashCmdMainHMAC(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	if err := os.WriteFile("a.txt", []byte("a"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "hmac.key")
+	if err := os.WriteFile(keyFile, []byte("secret"), 0600); err != nil {
+		t.Fatalf("创建密钥文件失败: %v", err)
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	// 无效的参数组合
+	tests := []struct {
+		name  string
+		setup func()
+	}{
+		{name: "非加密算法", setup: func() { _ = hashCmdType.Set("xxhash64") }},
+		{name: "GNU格式写入", setup: func() { _ = hashCmdWrite.Set("true"); _ = hashCmdFormat.Set("gnu") }},
+		{name: "密钥文件不存在", setup: func() { _ = hashCmdHMACKey.Set(filepath.Join(tempDir, "missing.key")) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashCmd = InitHashCmd()
+			_ = hashCmdHMACKey.Set(keyFile)
+			tt.setup()
+			if err := HashCmdMain(context.Background(), cl); err == nil {
+				t.Error("期望错误但没有发生错误")
+			}
+		})
+	}
+
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("sha256")
+	_ = hashCmdWrite.Set("true")
+	_ = hashCmdHMACKey.Set(keyFile)
+	if err := HashCmdMain(context.Background(), cl); err != nil {
+		t.Fatalf("HashCmdMain() 返回错误: %v", err)
+	}
+
+	content, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "#hmac-sha256#") {
+		t.Errorf("文件头未记录 HMAC 模式: %q", content)
+	}
+
+	// 与标准库计算的 HMAC-SHA256 一致
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("a"))
+	if expected := hex.EncodeToString(mac.Sum(nil)); !strings.Contains(string(content), expected) {
+		t.Errorf("校验文件中的 HMAC 值不正确: %q, want %s", content, expected)
+	}
+	if strings.Contains(string(content), "secret") {
+		t.Error("校验文件不应包含密钥")
+	}
+}
+
+// TestHashCmdMainChunkSize 测试 --chunk-size 记录分块哈希值
+func TestHashCmdMainChunkSize(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	if err := os.WriteFile("a.bin", []byte(strings.Repeat("a", 2500)), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	// 无效的参数组合
+	tests := []struct {
+		name  string
+		setup func()
+	}{
+		{name: "未指定写入", setup: func() { _ = hashCmdWrite.Set("false") }},
+		{name: "目录树模式", setup: func() { _ = hashCmdTree.Set("true") }},
+		{name: "GNU格式写入", setup: func() { _ = hashCmdFormat.Set("gnu") }},
+		{name: "无效的分块大小", setup: func() { _ = hashCmdChunkSize.Set("1X") }},
+		{name: "分块大小为0", setup: func() { _ = hashCmdChunkSize.Set("0") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashCmd = InitHashCmd()
+			_ = hashCmdWrite.Set("true")
+			_ = hashCmdChunkSize.Set("1K")
+			tt.setup()
+			if err := HashCmdMain(context.Background(), cl); err == nil {
+				t.Error("期望错误但没有发生错误")
+			}
+		})
+	}
+
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("md5")
+	_ = hashCmdWrite.Set("true")
+	_ = hashCmdChunkSize.Set("1K")
+	if err := HashCmdMain(context.Background(), cl); err != nil {
+		t.Fatalf("HashCmdMain() 返回错误: %v", err)
+	}
+
+	content, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("校验文件行数不正确: %q", content)
+	}
+	if lines[1]+"\n" != types.FormatChunkSizeLine(1024) {
+		t.Errorf("分块大小记录不正确: %q", lines[1])
+	}
+
+	// 分块记录的字节范围连续, 最后一块按文件实际大小截断
+	expected := []string{"0-1023", "1024-2047", "2048-2499"}
+	for i, want := range expected {
+		start, end, hash, ok := types.ParseChunkLine(lines[4+i])
+		if !ok {
+			t.Fatalf("第 %d 块记录格式不正确: %q", i, lines[4+i])
+		}
+		if got := fmt.Sprintf("%d-%d", start, end); got != want {
+			t.Errorf("第 %d 块范围 = %s, want %s", i, got, want)
+		}
+		if len(hash) != 32 {
+			t.Errorf("第 %d 块哈希值不正确: %s", i, hash)
+		}
+	}
+}
+
+func TestHashCmdMainWithMetadata(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	if err := os.WriteFile("a.txt", []byte("a"), 0640); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	if err := os.Chmod("a.txt", 0640); err != nil {
+		t.Fatalf("修改文件权限失败: %v", err)
+	}
+	if err := os.Symlink("a.txt", "link"); err != nil {
+		t.Skipf("当前平台不支持创建软链接: %v", err)
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	// 无效的参数组合
+	tests := []struct {
+		name  string
+		setup func()
+	}{
+		{name: "未指定写入", setup: func() { _ = hashCmdWrite.Set("false") }},
+		{name: "目录树模式", setup: func() { _ = hashCmdTree.Set("true") }},
+		{name: "GNU格式写入", setup: func() { _ = hashCmdFormat.Set("gnu") }},
+		{name: "仅指定扩展属性", setup: func() { _ = hashCmdMetadata.Set("false"); _ = hashCmdXattrs.Set("true") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashCmd = InitHashCmd()
+			_ = hashCmdWrite.Set("true")
+			_ = hashCmdMetadata.Set("true")
+			tt.setup()
+			if err := HashCmdMain(context.Background(), cl); err == nil {
+				t.Error("期望错误但没有发生错误")
+			}
+		})
+	}
+
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("md5")
+	_ = hashCmdWrite.Set("true")
+	_ = hashCmdMetadata.Set("true")
+	if err := HashCmdMain(context.Background(), cl); err != nil {
+		t.Fatalf("HashCmdMain() 返回错误: %v", err)
+	}
+
+	content, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("校验文件行数不正确: %q", content)
+	}
+	if fields, ok := types.ParseMetadataFieldsLine(lines[1]); !ok || fields != "mode,owner,link" {
+		t.Errorf("元数据字段记录不正确: %q", lines[1])
+	}
+	if policy, ok := types.ParseSymlinksLine(lines[2]); !ok || policy != types.SymlinksLink {
+		t.Errorf("记录元数据时应使用 link 策略: %q", lines[2])
+	}
+
+	// 普通文件记录权限位, 软链接记录链接目标且以链接目标作为内容
+	records := map[string]string{}
+	for i := 3; i < len(lines); i += 2 {
+		encoded, ok := types.ParseMetadataLine(lines[i+1])
+		if !ok {
+			t.Fatalf("元数据记录格式不正确: %q", lines[i+1])
+		}
+		records[lines[i]] = encoded
+	}
+	if meta := records["0cc175b9c0f1b6a831c399e269772661\t\"a.txt\""]; !strings.Contains(meta, "mode=0640") {
+		t.Errorf("a.txt 的元数据不正确: %q", meta)
+	}
+	const linkHash = "a5e54d1fd7bb69a228ef0dcd2431367e" // md5("a.txt")
+	if meta, ok := records[linkHash+"\t\"link\""]; !ok || !strings.Contains(meta, "link=a.txt") {
+		t.Errorf("link 的记录不正确: %q", content)
+	}
+}
+
+func TestHashCmdMainSymlinks(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	if err := os.WriteFile("a.txt", []byte("a"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	if err := os.Symlink("a.txt", "link"); err != nil {
+		t.Skipf("当前平台不支持创建软链接: %v", err)
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	// 无效的参数组合
+	tests := []struct {
+		name  string
+		setup func()
+	}{
+		{name: "目录树模式", setup: func() { _ = hashCmdTree.Set("true") }},
+		{name: "压缩包模式", setup: func() { _ = hashCmdArchive.Set("true") }},
+		{name: "记录元数据时跟随软链接", setup: func() { _ = hashCmdMetadata.Set("true") }},
+		{name: "GNU格式", setup: func() { _ = hashCmdFormat.Set(types.ChecksumFormatGNU) }},
+		{name: "BSD格式", setup: func() { _ = hashCmdFormat.Set(types.ChecksumFormatBSD) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashCmd = InitHashCmd()
+			_ = hashCmdWrite.Set("true")
+			_ = hashCmdSymlinks.Set(types.SymlinksFollow)
+			tt.setup()
+			if err := HashCmdMain(context.Background(), cl); err == nil {
+				t.Error("期望错误但没有发生错误")
+			}
+		})
+	}
+
+	// 策略记录在文件头之后, 未指定时记录默认的 skip 策略
+	policies := []struct {
+		policy string
+		want   []string
+	}{
+		{policy: "", want: []string{"#symlinks#skip", "0cc175b9c0f1b6a831c399e269772661\t\"a.txt\""}},
+		{policy: types.SymlinksSkip, want: []string{"#symlinks#skip", "0cc175b9c0f1b6a831c399e269772661\t\"a.txt\""}},
+		{policy: types.SymlinksLink, want: []string{"#symlinks#link", "0cc175b9c0f1b6a831c399e269772661\t\"a.txt\"", "a5e54d1fd7bb69a228ef0dcd2431367e\t\"link\""}},
+		{policy: types.SymlinksFollow, want: []string{"#symlinks#follow", "0cc175b9c0f1b6a831c399e269772661\t\"a.txt\"", "0cc175b9c0f1b6a831c399e269772661\t\"link\""}},
+	}
+	for _, tt := range policies {
+		hashCmd = InitHashCmd()
+		_ = hashCmdType.Set("md5")
+		_ = hashCmdWrite.Set("true")
+		if tt.policy != "" {
+			_ = hashCmdSymlinks.Set(tt.policy)
+		}
+		if err := HashCmdMain(context.Background(), cl); err != nil {
+			t.Fatalf("HashCmdMain() 返回错误: %v", err)
+		}
+
+		content, err := os.ReadFile(types.OutputFileName)
+		if err != nil {
+			t.Fatalf("读取校验文件失败: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		if got := strings.Join(lines[1:], "\n"); got != strings.Join(tt.want, "\n") {
+			t.Errorf("策略 %q 的校验文件内容不正确:\n%s", tt.policy, got)
+		}
+	}
+}
+
+func TestHashCmdMainWalkError(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root 用户可以读取任意目录, 无法构造无法遍历的目录")
+	}
+
+	tempDir := t.TempDir()
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	const previous = "#md5#2024-01-01 10:00:00#PORTABLE\n"
+	if err := os.WriteFile(types.OutputFileName, []byte(previous), 0644); err != nil {
+		t.Fatalf("创建校验文件失败: %v", err)
+	}
+	if err := os.WriteFile("a.txt", []byte("a"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join("sub", "locked"), 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	if err := os.Chmod(filepath.Join("sub", "locked"), 0); err != nil {
+		t.Fatalf("修改目录权限失败: %v", err)
+	}
+	defer func() { _ = os.Chmod(filepath.Join(tempDir, "sub", "locked"), 0755) }()
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("md5")
+	_ = hashCmdRecursion.Set("true")
+	_ = hashCmdWrite.Set("true")
+	_ = hashCmd.Parse([]string{"."})
+
+	if err := HashCmdMain(context.Background(), cl); exitcode.Code(err) != exitcode.Partial {
+		t.Errorf("HashCmdMain() 期望返回部分失败错误, 实际: %v", err)
+	}
+
+	// 遍历未完成时保留原校验文件
+	content, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+	if string(content) != previous {
+		t.Errorf("遍历失败后校验文件被覆盖:\n%s", content)
 	}
 }
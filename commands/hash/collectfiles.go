@@ -1,35 +1,54 @@
 // Package hash 实现了文件收集功能，用于哈希计算前的文件路径收集。
-// 该文件提供了文件收集器，支持单个文件、目录遍历和通配符匹配等多种文件收集方式。
+// 该文件提供了流式文件遍历器，支持单个文件、目录遍历和通配符匹配等多种文件收集方式，
+// 发现的文件按完整路径的字典序依次交给调用方处理, 无需先收集完整的文件列表。
+// 软链接按 --symlinks 指定的策略跳过、作为文件交给计算协程或跟随遍历。
 package hash
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"gitee.com/MM-Q/colorlib"
 	"gitee.com/MM-Q/fck/commands/internal/common"
+	"gitee.com/MM-Q/fck/commands/internal/pathfilter"
+	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
-// collectFiles 函数用于收集指定路径下的所有文件
+// errStopWalk 调用方停止接收文件时用于终止遍历
+var errStopWalk = errors.New("遍历已停止")
+
+// visitFunc 文件处理函数, 返回 false 时停止遍历
+type visitFunc func(path string) bool
+
+// walkFiles 流式遍历指定路径下的所有文件
 //
 // 参数:
-//   - targetPath: 要收集文件的路径
+//   - targetPath: 要遍历的路径
 //   - recursive: 是否递归处理目录
 //   - cl: ColorLib 实例，用于彩色输出
+//   - visit: 文件处理函数, 按完整路径的字典序依次调用
 //
 // 返回值：
-//   - []string: 收集到的文件路径列表
-//   - error: 如果发生错误，则返回错误信息
-func collectFiles(targetPath string, recursive bool, cl *colorlib.ColorLib) ([]string, error) {
+//   - error: 遍历过程中的错误(visit 主动停止时返回nil)
+func walkFiles(targetPath string, recursive bool, cl *colorlib.ColorLib, visit visitFunc) error {
+	var err error
+
 	// 检查路径是否包含通配符
 	if strings.ContainsAny(targetPath, "*?[]{}") {
-		return collectGlobFiles(targetPath, recursive, cl)
+		err = collectGlobFiles(targetPath, recursive, cl, visit)
+	} else {
+		err = collectSinglePath(targetPath, recursive, cl, visit)
 	}
 
-	return collectSinglePath(targetPath, recursive, cl)
+	if errors.Is(err, errStopWalk) {
+		return nil
+	}
+	return err
 }
 
 // collectGlobFiles 处理包含通配符的路径
@@ -38,43 +57,54 @@ func collectFiles(targetPath string, recursive bool, cl *colorlib.ColorLib) ([]s
 //	 - pattern: 包含通配符的路径模式
 //	 - recursive: 是否递归处理目录
 //	 - cl: ColorLib 实例，用于彩色输出
+//	 - visit: 文件处理函数
 //
 //	返回值：
-//	 - []string: 收集到的文件路径列表
 //	 - error: 如果发生错误，则返回错误信息
-func collectGlobFiles(pattern string, recursive bool, cl *colorlib.ColorLib) ([]string, error) {
+func collectGlobFiles(pattern string, recursive bool, cl *colorlib.ColorLib, visit visitFunc) error {
 	matchedPaths, err := filepath.Glob(pattern)
 	if err != nil {
-		return nil, fmt.Errorf("路径无效: %w", err)
+		return fmt.Errorf("路径无效: %w", err)
 	}
 
 	if len(matchedPaths) == 0 {
-		return nil, fmt.Errorf("没有找到匹配的文件")
+		return fmt.Errorf("没有找到匹配的文件")
 	}
 
-	// 预估容量
-	var allFiles []string
+	// 目录以 "路径/" 参与排序, 使逐个遍历的输出与完整路径的字典序一致
+	sortKeys := make(map[string]string, len(matchedPaths))
+	for _, path := range matchedPaths {
+		sortKeys[path] = path
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			sortKeys[path] = path + string(filepath.Separator)
+		}
+	}
+	slices.SortFunc(matchedPaths, func(a, b string) int {
+		return strings.Compare(sortKeys[a], sortKeys[b])
+	})
 
 	for _, path := range matchedPaths {
 		if shouldSkipHidden(path) {
 			continue
 		}
 
-		pathFiles, err := collectSinglePath(path, recursive, cl)
-		if err != nil {
+		// 跳过被过滤的文件和目录
+		if shouldSkipFiltered(path, strings.HasSuffix(sortKeys[path], string(filepath.Separator))) {
+			continue
+		}
+
+		if err := collectSinglePath(path, recursive, cl, visit); err != nil {
 			// 对于通配符匹配，如果是目录相关的错误，只打印警告而不中断整个过程
 			if isDirectorySkipError(err) {
 				cl.PrintWarn(err.Error())
 				continue
 			}
 			// 其他错误仍然返回
-			return nil, err
+			return err
 		}
-
-		allFiles = append(allFiles, pathFiles...)
 	}
 
-	return allFiles, nil
+	return nil
 }
 
 // collectSinglePath 处理单个路径(文件或目录)
@@ -83,26 +113,45 @@ func collectGlobFiles(pattern string, recursive bool, cl *colorlib.ColorLib) ([]
 //   - targetPath: 要收集文件的路径
 //   - recursive: 是否递归处理目录
 //   - cl: ColorLib 实例，用于彩色输出
+//   - visit: 文件处理函数
 //
 // 返回:
-//   - []item: 包含文件信息的切片
 //   - error: 错误信息，如果发生错误则返回非nil值
-func collectSinglePath(targetPath string, recursive bool, cl *colorlib.ColorLib) ([]string, error) {
-	info, err := os.Stat(targetPath)
+//
+// 注意:
+//   - 路径本身为软链接时同样按软链接处理策略处理, 跳过时不报告错误
+func collectSinglePath(targetPath string, recursive bool, cl *colorlib.ColorLib, visit visitFunc) error {
+	info, err := os.Lstat(targetPath)
 	if err != nil {
-		return nil, wrapStatError(err, targetPath)
+		return wrapStatError(err, targetPath)
 	}
 
 	if shouldSkipHidden(targetPath) {
-		return nil, fmt.Errorf("跳过隐藏项: %s", targetPath)
+		return fmt.Errorf("跳过隐藏项: %s", targetPath)
+	}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		switch hashCmdLinkPolicy {
+		case types.SymlinksLink:
+			// 作为文件交给计算协程
+		case types.SymlinksFollow:
+			if info, err = os.Stat(targetPath); err != nil {
+				return wrapStatError(err, targetPath)
+			}
+		default:
+			return nil
+		}
 	}
 
 	if info.IsDir() {
-		return handleDirectory(targetPath, recursive, cl)
+		return handleDirectory(targetPath, recursive, cl, visit)
 	}
 
 	// 普通文件
-	return []string{targetPath}, nil
+	if !visit(targetPath) {
+		return errStopWalk
+	}
+	return nil
 }
 
 // handleDirectory 处理目录
@@ -111,16 +160,16 @@ func collectSinglePath(targetPath string, recursive bool, cl *colorlib.ColorLib)
 //   - dirPath: 要处理的目录路径
 //   - recursive: 是否递归处理目录
 //   - cl: ColorLib 实例，用于彩色输出
+//   - visit: 文件处理函数
 //
 // 返回:
-//   - []string: 包含文件路径的切片
 //   - error: 错误信息，如果发生错误则返回非nil值
-func handleDirectory(dirPath string, recursive bool, cl *colorlib.ColorLib) ([]string, error) {
+func handleDirectory(dirPath string, recursive bool, cl *colorlib.ColorLib, visit visitFunc) error {
 	if !recursive {
-		return nil, fmt.Errorf("跳过目录: %s 请使用 -r 选项以递归方式处理", dirPath)
+		return fmt.Errorf("跳过目录: %s 请使用 -r 选项以递归方式处理", dirPath)
 	}
 
-	return walkDir(dirPath, recursive, cl)
+	return walkDir(dirPath, recursive, cl, visit)
 }
 
 // shouldSkipHidden 检查是否应该跳过隐藏文件/目录
@@ -134,6 +183,46 @@ func shouldSkipHidden(path string) bool {
 	return !hashCmdHidden.Get() && common.IsHidden(path)
 }
 
+// shouldSkipFiltered 检查文件或目录是否被 --include/--exclude/--ignore-file 过滤
+//
+// 参数:
+//   - path: 要检查的路径
+//   - isDir: 是否为目录
+//
+// 返回:
+//   - bool: 目录被排除, 或文件被排除/不满足包含规则时返回true
+func shouldSkipFiltered(path string, isDir bool) bool {
+	if hashCmdFilter.Excluded(path, isDir) {
+		return true
+	}
+	return !isDir && !hashCmdFilter.Included(path)
+}
+
+// newPathFilter 根据过滤标志创建路径过滤器
+//
+// 返回:
+//   - *pathfilter.Filter: 路径过滤器, 未指定任何过滤条件时返回nil
+//   - error: 模式无效或忽略文件读取失败时返回错误
+func newPathFilter() (*pathfilter.Filter, error) {
+	includes, excludes, ignoreFiles := hashCmdInclude.Get(), hashCmdExclude.Get(), hashCmdIgnoreFile.Get()
+	if len(includes) == 0 && len(excludes) == 0 && len(ignoreFiles) == 0 {
+		return nil, nil
+	}
+
+	filter, err := pathfilter.New(includes, excludes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ignoreFile := range ignoreFiles {
+		if err := filter.AddIgnoreFile(ignoreFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return filter, nil
+}
+
 // wrapStatError 统一处理 os.Stat 错误
 //
 // 参数:
@@ -152,47 +241,104 @@ func wrapStatError(err error, path string) error {
 	return fmt.Errorf("无法获取文件信息: %w", err)
 }
 
-// walkDir 函数用于根据递归标志遍历指定目录并收集文件列表
+// walkDir 函数用于根据递归标志遍历指定目录
 //
 // 参数:
 //   - dirPath: 要遍历的目录路径
 //   - recursive: 是否递归遍历子目录
 //   - cl: ColorLib 实例，用于彩色输出
+//   - visit: 文件处理函数
 //
 // 返回:
-//   - []string: 包含文件路径的切片
 //   - error: 错误信息，如果发生错误则返回非nil值
-func walkDir(dirPath string, recursive bool, cl *colorlib.ColorLib) ([]string, error) {
-	var files []string
-
+func walkDir(dirPath string, recursive bool, cl *colorlib.ColorLib, visit visitFunc) error {
 	if !recursive {
-		return walkDirNonRecursive(dirPath, cl)
+		return walkDirNonRecursive(dirPath, cl, visit)
 	}
 
-	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
+	if shouldSkipHidden(dirPath) {
+		return nil
+	}
+
+	if err := walkDirSorted(dirPath, nil, cl, visit); err != nil {
+		if errors.Is(err, errStopWalk) {
 			return err
 		}
+		return wrapWalkError(err, dirPath)
+	}
 
-		if shouldSkipHidden(path) {
-			if d.IsDir() {
-				return filepath.SkipDir
-			}
+	return nil
+}
+
+// walkDirSorted 深度优先递归遍历目录
+//
+// 参数:
+//   - dirPath: 要遍历的目录路径
+//   - ancestors: 上级目录的标识列表(仅跟随软链接时记录, 用于检测循环)
+//   - cl: ColorLib 实例，用于彩色输出
+//   - visit: 文件处理函数
+//
+// 返回:
+//   - error: 读取目录失败或 visit 停止遍历时返回错误
+//
+// 注意:
+//   - 同级条目中目录以 "名称/" 参与排序, 使输出顺序与完整路径的字典序一致
+//   - 被排除的目录整体跳过, 不再遍历
+//   - 跟随软链接时, 目录与某个上级目录为同一目录(设备号/inode相同)则视为循环, 输出警告后跳过
+func walkDirSorted(dirPath string, ancestors []string, cl *colorlib.ColorLib, visit visitFunc) error {
+	if hashCmdLinkPolicy == types.SymlinksFollow {
+		id, err := dirID(dirPath)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(ancestors, id) {
+			cl.PrintWarnf("检测到软链接循环, 跳过目录: %s\n", dirPath)
 			return nil
 		}
+		ancestors = append(ancestors, id)
+	}
 
-		if !d.IsDir() {
-			files = append(files, path)
-		}
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return err
+	}
 
-		return nil
+	// 按软链接处理策略确定每个条目的类型
+	isDir := make(map[string]bool, len(entries))
+	skipped := make(map[string]bool)
+	for _, entry := range entries {
+		isDir[entry.Name()], skipped[entry.Name()] = entryKind(filepath.Join(dirPath, entry.Name()), entry)
+	}
+
+	sortKey := func(entry os.DirEntry) string {
+		if isDir[entry.Name()] {
+			return entry.Name() + string(filepath.Separator)
+		}
+		return entry.Name()
+	}
+	slices.SortFunc(entries, func(a, b os.DirEntry) int {
+		return strings.Compare(sortKey(a), sortKey(b))
 	})
 
-	if err != nil {
-		return nil, wrapWalkError(err, dirPath)
+	for _, entry := range entries {
+		path := filepath.Join(dirPath, entry.Name())
+		if skipped[entry.Name()] || shouldSkipHidden(path) || shouldSkipFiltered(path, isDir[entry.Name()]) {
+			continue
+		}
+
+		if isDir[entry.Name()] {
+			if err := walkDirSorted(path, ancestors, cl, visit); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !visit(path) {
+			return errStopWalk
+		}
 	}
 
-	return files, nil
+	return nil
 }
 
 // walkDirNonRecursive 非递归遍历目录
@@ -200,31 +346,90 @@ func walkDir(dirPath string, recursive bool, cl *colorlib.ColorLib) ([]string, e
 // 参数:
 //   - dirPath: 要遍历的目录路径
 //   - cl: ColorLib 实例，用于彩色输出
+//   - visit: 文件处理函数
 //
 // 返回:
-//   - []string: 包含文件路径的切片
 //   - error: 错误信息，如果发生错误则返回非nil值
-func walkDirNonRecursive(dirPath string, cl *colorlib.ColorLib) ([]string, error) {
+func walkDirNonRecursive(dirPath string, cl *colorlib.ColorLib, visit visitFunc) error {
 	entries, err := os.ReadDir(dirPath)
 	if err != nil {
-		return nil, fmt.Errorf("读取目录失败: %w", err)
+		return fmt.Errorf("读取目录失败: %w", err)
 	}
 
-	var files []string
 	for _, entry := range entries {
 		if shouldSkipHidden(entry.Name()) {
 			continue
 		}
 
-		if entry.IsDir() {
+		path := filepath.Join(dirPath, entry.Name())
+		isDir, skipped := entryKind(path, entry)
+		if skipped || shouldSkipFiltered(path, isDir) {
+			continue
+		}
+
+		if isDir {
 			cl.PrintWarnf("跳过目录: %s 请使用 -r 选项以递归方式处理\n", entry.Name())
 			continue
 		}
 
-		files = append(files, filepath.Join(dirPath, entry.Name()))
+		if !visit(path) {
+			return errStopWalk
+		}
+	}
+
+	return nil
+}
+
+// entryKind 按软链接处理策略判断遍历到的条目如何处理
+//
+// 参数:
+//   - path: 条目路径
+//   - entry: 目录条目
+//
+// 返回:
+//   - bool: 是否作为目录遍历
+//   - bool: 是否跳过该条目
+//
+// 注意:
+//   - follow 策略下悬空的软链接作为文件交给计算协程, 由计算阶段报告错误
+func entryKind(path string, entry os.DirEntry) (bool, bool) {
+	if entry.Type()&fs.ModeSymlink == 0 {
+		return entry.IsDir(), false
+	}
+
+	switch hashCmdLinkPolicy {
+	case types.SymlinksLink:
+		return false, false
+	case types.SymlinksFollow:
+		info, err := os.Stat(path)
+		return err == nil && info.IsDir(), false
+	default:
+		return false, true
+	}
+}
+
+// dirID 获取目录的唯一标识, 用于检测软链接循环
+//
+// 参数:
+//   - dirPath: 目录路径
+//
+// 返回:
+//   - string: 设备号:inode, 无法获取 inode 时为解析软链接后的绝对路径
+//   - error: 获取目录信息失败时返回错误
+func dirID(dirPath string) (string, error) {
+	info, err := os.Stat(dirPath)
+	if err != nil {
+		return "", err
+	}
+	if dev, ino, ok := common.GetFileID(info); ok {
+		return fmt.Sprintf("%d:%d", dev, ino), nil
 	}
 
-	return files, nil
+	realPath, err := filepath.EvalSymlinks(dirPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(realPath)
 }
 
 // wrapWalkError 统一处理遍历错误
@@ -3,14 +3,29 @@ package hash
 import (
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
 	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
-// TestCollectFiles 测试文件收集主函数
-func TestCollectFiles(t *testing.T) {
+// walkAll 执行流式遍历并返回遍历到的全部文件
+func walkAll(walk func(visit visitFunc) error) ([]string, error) {
+	var files []string
+	err := walk(func(path string) bool {
+		files = append(files, path)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// TestWalkFiles 测试流式遍历主函数
+func TestWalkFiles(t *testing.T) {
 	// 创建临时目录和文件
 	tempDir := t.TempDir()
 	testFile := filepath.Join(tempDir, "test.txt")
@@ -81,18 +96,18 @@ func TestCollectFiles(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			files, err := collectFiles(tt.targetPath, tt.recursive, cl)
+			files, err := walkAll(func(visit visitFunc) error { return walkFiles(tt.targetPath, tt.recursive, cl, visit) })
 
 			if tt.expectError {
 				if err == nil {
-					t.Errorf("collectFiles() 期望返回错误，但没有错误")
+					t.Errorf("walkFiles() 期望返回错误，但没有错误")
 				}
 			} else {
 				if err != nil {
-					t.Errorf("collectFiles() 返回意外错误: %v", err)
+					t.Errorf("walkFiles() 返回意外错误: %v", err)
 				}
 				if len(files) != tt.expectCount {
-					t.Errorf("collectFiles() 返回 %d 个文件，期望 %d 个", len(files), tt.expectCount)
+					t.Errorf("walkFiles() 返回 %d 个文件，期望 %d 个", len(files), tt.expectCount)
 				}
 			}
 		})
@@ -159,7 +174,7 @@ func TestCollectGlobFiles(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			files, err := collectGlobFiles(tt.pattern, tt.recursive, cl)
+			files, err := walkAll(func(visit visitFunc) error { return collectGlobFiles(tt.pattern, tt.recursive, cl, visit) })
 
 			if tt.expectError {
 				if err == nil {
@@ -252,7 +267,7 @@ func TestCollectSinglePath(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			_ = hashCmdHidden.Set(tt.hidden)
 
-			files, err := collectSinglePath(tt.targetPath, tt.recursive, cl)
+			files, err := walkAll(func(visit visitFunc) error { return collectSinglePath(tt.targetPath, tt.recursive, cl, visit) })
 
 			if tt.expectError {
 				if err == nil {
@@ -322,7 +337,7 @@ func TestHandleDirectory(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			files, err := handleDirectory(tt.dirPath, tt.recursive, cl)
+			files, err := walkAll(func(visit visitFunc) error { return handleDirectory(tt.dirPath, tt.recursive, cl, visit) })
 
 			if tt.expectError {
 				if err == nil {
@@ -411,7 +426,7 @@ func TestWalkDir(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			_ = hashCmdHidden.Set(tt.hidden)
 
-			files, err := walkDir(tempDir, tt.recursive, cl)
+			files, err := walkAll(func(visit visitFunc) error { return walkDir(tempDir, tt.recursive, cl, visit) })
 
 			if tt.expectError {
 				if err == nil {
@@ -638,7 +653,7 @@ func TestWalkDirNonRecursive(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			_ = hashCmdHidden.Set(tt.hidden)
 
-			files, err := walkDirNonRecursive(tempDir, cl)
+			files, err := walkAll(func(visit visitFunc) error { return walkDirNonRecursive(tempDir, cl, visit) })
 
 			if tt.expectError {
 				if err == nil {
@@ -655,3 +670,257 @@ func TestWalkDirNonRecursive(t *testing.T) {
 		})
 	}
 }
+
+// TestCollectFilesWithFilter 测试包含/排除模式和忽略文件
+func TestCollectFilesWithFilter(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 切换到临时目录, 使含 / 的模式相对临时目录匹配
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	for _, name := range []string{
+		"main.go", "app.log", "keep.log",
+		"node_modules/pkg/index.js",
+		"src/util.go", "src/gen/types.go", "src/docs/readme.md",
+		"build/out.o",
+	} {
+		_ = os.MkdirAll(filepath.Dir(name), 0755)
+		if err := os.WriteFile(name, []byte(name), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+	if err := os.WriteFile(".fckignore", []byte("# 构建输出\nbuild/\n*.log\n!keep.log\n"), 0644); err != nil {
+		t.Fatalf("创建忽略文件失败: %v", err)
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	tests := []struct {
+		name        string
+		include     string
+		exclude     string
+		ignoreFile  string
+		targetPath  string
+		expectFiles []string
+	}{
+		{
+			name:       "排除目录名",
+			exclude:    "node_modules,build,*.log",
+			targetPath: "*",
+			expectFiles: []string{
+				"main.go", "src/docs/readme.md", "src/gen/types.go", "src/util.go",
+			},
+		},
+		{
+			name:        "包含扩展名并排除子目录",
+			include:     "*.go",
+			exclude:     "src/gen",
+			targetPath:  ".",
+			expectFiles: []string{"main.go", "src/util.go"},
+		},
+		{
+			name:        "双星号跨目录匹配",
+			include:     "src/**/*.md",
+			targetPath:  "src",
+			expectFiles: []string{"src/docs/readme.md"},
+		},
+		{
+			name:       "忽略文件",
+			exclude:    "node_modules",
+			ignoreFile: ".fckignore",
+			targetPath: "*",
+			expectFiles: []string{
+				"keep.log", "main.go", "src/docs/readme.md", "src/gen/types.go", "src/util.go",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 初始化命令标志
+			hashCmd = InitHashCmd()
+			if tt.include != "" {
+				_ = hashCmdInclude.Set(tt.include)
+			}
+			if tt.exclude != "" {
+				_ = hashCmdExclude.Set(tt.exclude)
+			}
+			if tt.ignoreFile != "" {
+				_ = hashCmdIgnoreFile.Set(tt.ignoreFile)
+			}
+
+			filter, err := newPathFilter()
+			if err != nil {
+				t.Fatalf("newPathFilter() 返回错误: %v", err)
+			}
+			hashCmdFilter = filter
+			defer func() { hashCmdFilter = nil }()
+
+			files, err := walkAll(func(visit visitFunc) error { return walkFiles(tt.targetPath, true, cl, visit) })
+			if err != nil {
+				t.Fatalf("walkFiles() 返回错误: %v", err)
+			}
+
+			got := make([]string, len(files))
+			for i, file := range files {
+				got[i] = filepath.ToSlash(filepath.Clean(file))
+			}
+			sort.Strings(got)
+
+			if strings.Join(got, ",") != strings.Join(tt.expectFiles, ",") {
+				t.Errorf("收集结果不正确: got %v, want %v", got, tt.expectFiles)
+			}
+		})
+	}
+}
+
+// TestNewPathFilterInvalidPattern 测试无效的匹配模式
+func TestNewPathFilterInvalidPattern(t *testing.T) {
+	hashCmd = InitHashCmd()
+	_ = hashCmdExclude.Set("[abc")
+
+	if _, err := newPathFilter(); err == nil {
+		t.Error("无效的匹配模式应返回错误")
+	}
+}
+
+// TestWalkFilesSymlinkPolicy 测试按软链接处理策略遍历
+func TestWalkFilesSymlinkPolicy(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 切换到临时目录, 使输出路径为相对路径
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	_ = os.MkdirAll("d/sub", 0755)
+	_ = os.MkdirAll("outside", 0755)
+	_ = os.WriteFile("d/a.txt", []byte("a"), 0644)
+	_ = os.WriteFile("d/sub/s.txt", []byte("s"), 0644)
+	_ = os.WriteFile("outside/o.txt", []byte("o"), 0644)
+	for link, target := range map[string]string{
+		"d/file.link": "a.txt",      // 指向文件
+		"d/dir.link":  "sub",        // 指向目录
+		"d/out.link":  "../outside", // 指向目录树外部的目录
+		"d/sub/loop":  "..",         // 指向上级目录, 跟随时形成循环
+	} {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("当前平台不支持创建软链接: %v", err)
+		}
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	tests := []struct {
+		name        string
+		policy      string
+		expectFiles []string
+	}{
+		{
+			name:        "未指定策略",
+			policy:      "",
+			expectFiles: []string{"d/a.txt", "d/sub/s.txt"},
+		},
+		{
+			name:        "跳过软链接",
+			policy:      types.SymlinksSkip,
+			expectFiles: []string{"d/a.txt", "d/sub/s.txt"},
+		},
+		{
+			name:        "软链接作为文件",
+			policy:      types.SymlinksLink,
+			expectFiles: []string{"d/a.txt", "d/dir.link", "d/file.link", "d/out.link", "d/sub/loop", "d/sub/s.txt"},
+		},
+		{
+			name:        "跟随软链接",
+			policy:      types.SymlinksFollow,
+			expectFiles: []string{"d/a.txt", "d/dir.link/s.txt", "d/file.link", "d/out.link/o.txt", "d/sub/s.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashCmd = InitHashCmd()
+			hashCmdLinkPolicy = tt.policy
+			defer func() { hashCmdLinkPolicy = "" }()
+
+			files, err := walkAll(func(visit visitFunc) error { return walkFiles("d", true, cl, visit) })
+			if err != nil {
+				t.Fatalf("walkFiles() 返回错误: %v", err)
+			}
+
+			var got []string
+			for _, file := range files {
+				got = append(got, filepath.ToSlash(file))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expectFiles, ",") {
+				t.Errorf("walkFiles() = %v, 期望 %v", got, tt.expectFiles)
+			}
+		})
+	}
+
+	// 路径本身为软链接时同样按策略处理
+	hashCmd = InitHashCmd()
+	files, err := walkAll(func(visit visitFunc) error { return walkFiles(filepath.Join("d", "dir.link"), true, cl, visit) })
+	if err != nil || len(files) != 0 {
+		t.Errorf("默认策略应跳过软链接路径: %v, %v", files, err)
+	}
+}
+
+// TestEntryKind 测试按软链接处理策略判断目录条目的处理方式
+func TestEntryKind(t *testing.T) {
+	tempDir := t.TempDir()
+	_ = os.Mkdir(filepath.Join(tempDir, "dir"), 0755)
+	_ = os.WriteFile(filepath.Join(tempDir, "file"), []byte("f"), 0644)
+	for link, target := range map[string]string{
+		"dir.link":      "dir",
+		"file.link":     "file",
+		"dangling.link": "missing",
+	} {
+		if err := os.Symlink(target, filepath.Join(tempDir, link)); err != nil {
+			t.Skipf("当前平台不支持创建软链接: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("读取目录失败: %v", err)
+	}
+
+	// 期望结果: 是否作为目录遍历, 是否跳过
+	tests := []struct {
+		policy string
+		expect map[string][2]bool
+	}{
+		{
+			policy: types.SymlinksSkip,
+			expect: map[string][2]bool{"dir": {true, false}, "file": {false, false}, "dir.link": {false, true}, "file.link": {false, true}, "dangling.link": {false, true}},
+		},
+		{
+			policy: types.SymlinksLink,
+			expect: map[string][2]bool{"dir": {true, false}, "file": {false, false}, "dir.link": {false, false}, "file.link": {false, false}, "dangling.link": {false, false}},
+		},
+		{
+			policy: types.SymlinksFollow,
+			expect: map[string][2]bool{"dir": {true, false}, "file": {false, false}, "dir.link": {true, false}, "file.link": {false, false}, "dangling.link": {false, false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			hashCmdLinkPolicy = tt.policy
+			defer func() { hashCmdLinkPolicy = "" }()
+
+			for _, entry := range entries {
+				isDir, skip := entryKind(filepath.Join(tempDir, entry.Name()), entry)
+				if want := tt.expect[entry.Name()]; isDir != want[0] || skip != want[1] {
+					t.Errorf("entryKind(%s) = (%v, %v), 期望 (%v, %v)", entry.Name(), isDir, skip, want[0], want[1])
+				}
+			}
+		})
+	}
+}
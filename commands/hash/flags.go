@@ -5,42 +5,156 @@ package hash
 import (
 	"flag"
 	"fmt"
+	"strings"
 
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/hashcache"
+	"gitee.com/MM-Q/fck/commands/internal/output"
+	"gitee.com/MM-Q/fck/commands/internal/types"
 	"gitee.com/MM-Q/qflag"
 )
 
+// 结果输出顺序
+const (
+	orderPath       = "path"       // 按文件路径排序输出(默认)
+	orderCompletion = "completion" // 按计算完成顺序输出
+)
+
 var (
 	// fck hash 子命令
-	hashCmd          *qflag.Cmd
-	hashCmdType      *qflag.EnumFlag   // type 标志
-	hashCmdRecursion *qflag.BoolFlag   // recursion 标志
-	hashCmdWrite     *qflag.BoolFlag   // write 标志
-	hashCmdHidden    *qflag.BoolFlag   // hidden 标志
-	hashCmdProgress  *qflag.BoolFlag   // progress 标志
-	hashCmdLocal     *qflag.BoolFlag   // local 标志
-	hashCmdBasePath  *qflag.StringFlag // base-path 标志
+	hashCmd           *qflag.Cmd
+	hashCmdType       *qflag.StringFlag // type 标志
+	hashCmdRecursion  *qflag.BoolFlag   // recursion 标志
+	hashCmdWrite      *qflag.BoolFlag   // write 标志
+	hashCmdHidden     *qflag.BoolFlag   // hidden 标志
+	hashCmdProgress   *qflag.BoolFlag   // progress 标志
+	hashCmdLocal      *qflag.BoolFlag   // local 标志
+	hashCmdBasePath   *qflag.StringFlag // base-path 标志
+	hashCmdFormat     *qflag.EnumFlag   // format 标志
+	hashCmdCacheMode  *qflag.EnumFlag   // cache 标志
+	hashCmdPrune      *qflag.BoolFlag   // cache-prune 标志
+	hashCmdStats      *qflag.BoolFlag   // cache-stats 标志
+	hashCmdOrder      *qflag.EnumFlag   // order 标志
+	hashCmdOutput     *qflag.EnumFlag   // output 标志
+	hashCmdString     *qflag.StringFlag // string 标志
+	hashCmdOutputFile *qflag.StringFlag // output-file 标志
+	hashCmdUpdate     *qflag.BoolFlag   // update 标志
+	hashCmdJobs       *qflag.IntFlag    // jobs 标志
+	hashCmdBwLimit    *qflag.StringFlag // bwlimit 标志
+	hashCmdSign       *qflag.StringFlag // sign 标志
+	hashCmdHMACKey    *qflag.StringFlag // hmac-key-file 标志
+	hashCmdChunkSize  *qflag.StringFlag // chunk-size 标志
+	hashCmdArchive    *qflag.BoolFlag   // archive 标志
+	hashCmdMetadata   *qflag.BoolFlag   // with-metadata 标志
+	hashCmdXattrs     *qflag.BoolFlag   // xattrs 标志
+	hashCmdSymlinks   *qflag.EnumFlag   // symlinks 标志
+
+	hashCmdInclude    *qflag.StringSliceFlag // include 标志
+	hashCmdExclude    *qflag.StringSliceFlag // exclude 标志
+	hashCmdIgnoreFile *qflag.StringSliceFlag // ignore-file 标志
+
+	hashCmdTree         *qflag.BoolFlag // tree 标志
+	hashCmdDepth        *qflag.IntFlag  // depth 标志
+	hashCmdTreeMode     *qflag.BoolFlag // tree-mode 标志
+	hashCmdTreeSymlinks *qflag.BoolFlag // tree-symlinks 标志
 )
 
+// hashTypeValidator 哈希算法列表验证器
+type hashTypeValidator struct{}
+
+// Validate 检查以逗号分隔的算法列表是否均受支持
+func (hashTypeValidator) Validate(value any) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("哈希算法必须为字符串")
+	}
+	_, err := digest.ParseAlgorithms(s)
+	return err
+}
+
 func InitHashCmd() *qflag.Cmd {
 	// fck hash 子命令
-	hashCmd = qflag.NewCmd("hash", "h", flag.ExitOnError)
+	hashCmd = qflag.NewCmd("hash", "h", flag.ContinueOnError)
 
 	hashCmdCfg := qflag.CmdConfig{
-		UseChinese:  true,
-		Desc:        "文件哈希计算工具, 计算指定文件或目录的哈希值，支持多种哈希算法和并发处理",
-		Notes:       []string{"哈希值计算基于文件内容，不包括元数据"},
-		UsageSyntax: fmt.Sprintf("%s hash [options] <path>\n", qflag.Root.LongName()),
+		UseChinese: true,
+		Desc:       "文件哈希计算工具, 计算指定文件或目录的哈希值，支持多种哈希算法和并发处理",
+		Notes: []string{
+			"哈希值计算基于文件内容，不包括元数据; 指定 --with-metadata 后额外记录权限位、uid/gid 和软链接目标(--xattrs 额外记录扩展属性)",
+			"指定多个算法时每个文件只读取一次, 写入文件时每个算法生成一个校验文件(checksum.<算法>.hash)",
+			"--tree 模式按相对路径排序计算Merkle根摘要, 空目录和根目录下的checksum.hash不参与计算, 使用 -w 写入后可通过 check 校验",
+			"哈希缓存默认关闭, 通过 --cache on 启用(位于用户缓存目录的fck/hash-cache.db), 设备号/inode/大小/修改时间均未变化的文件直接使用缓存结果",
+			"校验文件先写入同目录临时文件再重命名替换, 写入中断、存在无法遍历的路径或无法计算哈希值的文件时保留原校验文件及其签名",
+			"目录边遍历边计算, 无需等待全部文件收集完成; 默认按完整路径的字典序输出, 多个路径按参数顺序依次输出",
+			"--include/--exclude 中含 / 的模式相对当前工作目录匹配完整路径, 否则匹配任意层级的文件或目录名, ** 可跨目录匹配; 被排除的目录不会被遍历",
+			"--hmac-key-file 以密钥文件的完整内容(包括结尾换行符)作为密钥, 校验文件头中算法记为 hmac-<算法> 但不记录密钥, --update 时须使用与原校验文件相同的密钥; 不支持 xxhash64/crc32c, 且不使用哈希缓存",
+			"--chunk-size 的分块哈希值以 #chunk# 开头的行紧跟在文件记录之后, 不支持分块的工具会将其视为注释; 分块模式不使用哈希缓存; check 据此定位不一致的字节范围, 并可通过 check --resume 从中断处继续校验",
+			"--archive 将路径视为压缩包(zip/tar/tgz/tar.gz/tar.bz2/gz/bz2/zlib), 不解压直接流式计算每个文件条目的哈希值, 记录路径为 <压缩包>!/<条目路径>, 目录和软链接条目会被跳过",
+			"--with-metadata 的元数据以 #meta# 开头的行紧跟在文件记录之后, 文件头之后的 #metadata# 行记录包含的字段; 未指定 --symlinks 时软链接按 link 策略处理",
+			"--symlinks 默认跳过软链接; follow 跟随软链接(含指向目录的软链接), 按设备号/inode 检测循环, 悬空链接报告错误; 显式指定的策略记录在校验文件头之后的 #symlinks# 行, check 按相同策略校验",
+			"--update 为每个文件记录大小和修改时间(#stat# 行), 再次更新时两者均未变化的文件沿用原哈希值, 其余文件重新计算; 仅支持 fck 格式",
+			"--sign 生成的签名文件与校验文件位于同一目录, 可通过 check --verify-key 使用对应公钥验证; 未签名重写校验文件后原签名文件失效",
+			"--bwlimit 限制所有并发协程读取文件的总速率, 单位 K/M/G/T 按1024进制计算, 如 50M/s",
+			"路径为 - 时从标准输入流式读取数据计算哈希值, 如: tar c dir | fck hash -t sha256 -",
+			"--output 为 json/ndjson/csv 时标准输出仅包含结构化记录, 提示和错误信息输出到标准错误",
+		},
+		UsageSyntax: fmt.Sprintf("%s hash [options] <path|->...\n", qflag.Root.LongName()),
 	}
 
 	hashCmd.ApplyConfig(hashCmdCfg)
 
-	hashCmdType = hashCmd.Enum("type", "t", "md5", "指定哈希算法，支持 md5、sha1、sha256、sha512", []string{"md5", "sha1", "sha256", "sha512"})
+	hashCmdType = hashCmd.String("type", "t", digest.MD5, fmt.Sprintf("指定哈希算法，多个算法以逗号分隔(如 md5,sha256)，支持 %s", strings.Join(digest.SupportedAlgorithms, "、")))
+	hashCmdType.SetValidator(hashTypeValidator{})
 	hashCmdRecursion = hashCmd.Bool("recursion", "r", false, "递归处理目录")
-	hashCmdWrite = hashCmd.Bool("write", "w", false, "将哈希值写入文件, 文件名为checksum.hash")
+	hashCmdWrite = hashCmd.Bool("write", "w", false, "将哈希值写入文件, 默认文件名为checksum.hash")
+	hashCmdOutputFile = hashCmd.String("output-file", "o", "", "指定 -w 写入的校验文件路径(默认为checksum.hash)")
+	hashCmdUpdate = hashCmd.Bool("update", "u", false, "与 -w 一起使用, 增量更新已有校验文件: 仅重新计算新增或大小/修改时间与记录不一致的文件, 移除已删除文件的记录(仅支持 fck 格式)")
+	hashCmdSign = hashCmd.String("sign", "", "", "与 -w 一起使用, 使用指定的 Ed25519 私钥(可通过 keygen 命令生成)为校验文件生成签名文件<校验文件>.sig")
+	hashCmdHMACKey = hashCmd.String("hmac-key-file", "", "", "使用指定文件的内容作为密钥计算 HMAC(所选算法), 没有密钥无法重新计算摘要")
+	hashCmdChunkSize = hashCmd.String("chunk-size", "", "", "与 -w 一起使用, 除整体哈希值外按指定大小(如 64M、1G)记录每个分块的哈希值, check 可据此报告不一致的字节范围")
+	hashCmdArchive = hashCmd.Bool("archive", "", false, "将指定路径作为压缩包, 计算包内每个文件的哈希值(路径记为 archive.zip!/inner/path), 无需解压")
+	hashCmdMetadata = hashCmd.Bool("with-metadata", "", false, "与 -w 一起使用, 除文件内容外记录权限位、uid/gid 和软链接目标, check 可据此报告具体变化的属性")
+	hashCmdXattrs = hashCmd.Bool("xattrs", "", false, "与 --with-metadata 一起使用, 额外记录文件的扩展属性")
+	hashCmdSymlinks = hashCmd.Enum("symlinks", "", types.SymlinksSkip, "指定软链接的处理策略，支持以下选项：\n"+
+		"\t\t\t\t[follow] - 跟随软链接, 按目标文件计算, 指向目录时继续遍历\n"+
+		"\t\t\t\t[skip] - 跳过软链接\n"+
+		"\t\t\t\t[link] - 以链接目标文本作为内容计算, 不跟随", types.SupportedSymlinkPolicies)
+	hashCmdInclude = hashCmd.StringSlice("include", "", nil, "仅计算匹配指定通配符的文件, 多个模式以逗号分隔(如 *.go,src/**/*.md)")
+	hashCmdExclude = hashCmd.StringSlice("exclude", "", nil, "排除匹配指定通配符的文件或目录, 多个模式以逗号分隔(如 node_modules,*.log)")
+	hashCmdIgnoreFile = hashCmd.StringSlice("ignore-file", "", nil, "从指定文件读取 .gitignore 风格的排除规则, 多个文件以逗号分隔")
+	for _, f := range []*qflag.StringSliceFlag{hashCmdInclude, hashCmdExclude, hashCmdIgnoreFile} {
+		f.SetDelimiters([]string{","})
+		f.SetSkipEmpty(true)
+	}
 	hashCmdHidden = hashCmd.Bool("hidden", "H", false, "启用计算隐藏文件/目录的哈希值，默认跳过")
-	hashCmdProgress = hashCmd.Bool("progress", "p", false, "显示文件哈希计算进度条, 推荐在大文件处理时使用")
+	hashCmdProgress = hashCmd.Bool("progress", "p", false, "在标准错误中显示进度条(已发现/已计算的文件数), 推荐在处理大目录时使用")
 	hashCmdLocal = hashCmd.Bool("local", "l", false, "生成本地模式校验文件，记录绝对路径和基准目录")
 	hashCmdBasePath = hashCmd.String("base-path", "b", "", "指定基准路径(默认为当前工作目录)")
+	hashCmdFormat = hashCmd.Enum("format", "f", types.ChecksumFormatFck, "指定输出格式，支持以下选项：\n"+
+		"\t\t\t\t[fck] - fck原生格式, 写入文件时带文件头\n"+
+		"\t\t\t\t[gnu] - GNU coreutils格式(hash  path), 兼容sha256sum等工具\n"+
+		"\t\t\t\t[bsd] - BSD标签格式(ALGO (path) = hash)", types.SupportedChecksumFormats)
+	hashCmdCacheMode = hashCmd.Enum("cache", "", hashcache.ModeOff, "指定哈希缓存模式，支持以下选项：\n"+
+		"\t\t\t\t[on] - 使用并更新缓存\n"+
+		"\t\t\t\t[off] - 不使用缓存, 始终重新计算\n"+
+		"\t\t\t\t[rebuild] - 清空缓存后重新计算并写入", hashcache.SupportedModes)
+	hashCmdPrune = hashCmd.Bool("cache-prune", "", false, "清理缓存中已删除或已变化文件的条目, 未指定路径时仅执行清理")
+	hashCmdJobs = hashCmd.Int("jobs", "j", 0, "指定并发计算的协程数(默认为逻辑处理器数量的2倍, 最多50个), 机械硬盘或网络存储建议调低")
+	hashCmdBwLimit = hashCmd.String("bwlimit", "", "", "限制读取文件的总带宽(如 50M/s、512K), 默认不限速")
+	hashCmdOrder = hashCmd.Enum("order", "", orderPath, "指定结果输出顺序，支持以下选项：\n"+
+		"\t\t\t\t[path] - 按文件路径排序输出, 多次运行结果一致\n"+
+		"\t\t\t\t[completion] - 按计算完成顺序输出, 速度更快", []string{orderPath, orderCompletion})
+	hashCmdOutput = hashCmd.Enum("output", "", output.FormatText, "指定控制台结果的输出格式，支持以下选项：\n"+
+		"\t\t\t\t[text] - 文本格式, 由 --format 决定行格式\n"+
+		"\t\t\t\t[json] - JSON对象, 包含逐文件记录(path/algorithm/digest/size/error)和汇总信息\n"+
+		"\t\t\t\t[ndjson] - 每行一个JSON记录, 最后一行为汇总信息\n"+
+		"\t\t\t\t[csv] - 带表头的CSV记录", output.SupportedFormats)
+	hashCmdString = hashCmd.String("string", "s", "", "计算指定文本的哈希值(不包含换行符)")
+	hashCmdStats = hashCmd.Bool("cache-stats", "", false, "计算完成后显示缓存命中统计")
+	hashCmdTree = hashCmd.Bool("tree", "", false, "计算目录的Merkle树摘要, 为整个目录生成单一指纹(始终递归)")
+	hashCmdDepth = hashCmd.Int("depth", "", 0, "--tree 模式下输出子树摘要的目录深度, 0表示仅输出根目录")
+	hashCmdTreeMode = hashCmd.Bool("tree-mode", "", false, "--tree 模式下将文件权限位计入摘要")
+	hashCmdTreeSymlinks = hashCmd.Bool("tree-symlinks", "", false, "--tree 模式下将软链接及其目标计入摘要(默认跳过软链接)")
 
 	return hashCmd
 }
@@ -152,7 +152,7 @@ func TestHashCmdBoolFlags(t *testing.T) {
 func TestHashCmdTypeFlag(t *testing.T) {
 	hashCmd = InitHashCmd()
 
-	validTypes := []string{"md5", "sha1", "sha256", "sha512"}
+	validTypes := []string{"md5", "sha1", "sha256", "sha512", "sha3-256", "sha3-512", "blake2b", "blake3", "xxhash64", "crc32c"}
 
 	for _, validType := range validTypes {
 		t.Run("valid_type_"+validType, func(t *testing.T) {
@@ -167,8 +167,17 @@ func TestHashCmdTypeFlag(t *testing.T) {
 		})
 	}
 
+	// 测试多个算法
+	for _, multiType := range []string{"md5,sha256", "sha256, sha512", "md5,sha256,sha512"} {
+		t.Run("multi_type_"+multiType, func(t *testing.T) {
+			if err := hashCmdType.Set(multiType); err != nil {
+				t.Errorf("设置多个算法 %s 失败: %v", multiType, err)
+			}
+		})
+	}
+
 	// 测试无效类型
-	invalidTypes := []string{"invalid", "md4", "sha3"}
+	invalidTypes := []string{"invalid", "md4", "sha3", "md5,md4", ","}
 
 	for _, invalidType := range invalidTypes {
 		t.Run("invalid_type_"+invalidType, func(t *testing.T) {
@@ -8,52 +8,97 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"gitee.com/MM-Q/fck/commands/internal/common"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/hashcache"
+	"gitee.com/MM-Q/fck/commands/internal/metadata"
 	"gitee.com/MM-Q/fck/commands/internal/types"
-	"gitee.com/MM-Q/go-kit/hash"
+	"github.com/schollz/progressbar/v3"
 )
 
 // HashResult 哈希计算结果
 type HashResult struct {
-	FilePath  string // 文件路径
-	HashValue string // 哈希值
-	Error     error  // 错误信息
+	Index      int            // 文件在任务列表中的序号(用于按路径顺序输出)
+	FilePath   string         // 文件路径
+	HashValue  string         // 哈希值(多个算法时为第一个算法的哈希值)
+	HashValues []string       // 各算法的哈希值(与算法列表顺序一致)
+	Chunks     [][]string     // 各算法的分块哈希值(仅 --chunk-size, 与算法列表顺序一致)
+	Metadata   metadata.Attrs // 文件元数据(仅 --with-metadata)
+	Size       int64          // 文件大小
+	ModTime    time.Time      // 计算前的文件修改时间(--update 时记录到校验文件)
+	Error      error          // 错误信息
+	Skipped    bool           // 是否跳过(如软链接), 跳过的文件不输出
 }
 
+// fileTask 文件计算任务
+type fileTask struct {
+	index int    // 文件序号
+	path  string // 文件路径
+}
+
+// reorderWindowFactor 重排窗口大小相对于并发数的倍数
+//
+// 按路径顺序输出时, 已分发但尚未输出的文件数不超过 并发数*reorderWindowFactor,
+// 避免某个大文件阻塞时后续结果在内存中无限堆积
+const reorderWindowFactor = 4
+
+// progressInterval 进度条刷新间隔
+const progressInterval = 200 * time.Millisecond
+
 // WriteRequest 写入请求
 type WriteRequest struct {
 	Content string     // 要写入的内容
+	Target  int        // 目标校验文件序号(与算法列表顺序一致)
 	Done    chan error // 完成通知通道
 }
 
 // HashTaskManager 哈希任务管理器
 type HashTaskManager struct {
 	// 配置参数
-	files       []string // 文件列表
-	hashType    string   // 哈希类型
-	concurrency int      // 并发数
+	files       []string                    // 文件列表
+	walk        func(visit visitFunc) error // 流式文件来源(为nil时使用文件列表)
+	archives    []archiveSource             // 压缩包来源(不为nil时计算压缩包内的条目)
+	hashType    string                      // 哈希类型(多个算法以逗号分隔)
+	hashTypes   []string                    // 解析后的哈希算法列表
+	concurrency int                         // 并发数
+	ordered     bool                        // 是否按文件列表顺序输出结果
+	cache       *hashcache.Cache            // 哈希缓存(为nil时不使用缓存)
+	records     *hashRecorder               // 结构化输出记录器(为nil时输出文本)
+	previous    *manifest                   // 增量更新时已有的校验文件(为nil时全部重新计算)
+	chunkSize   int64                       // 分块大小(为0时不计算分块哈希值)
+	metadata    []string                    // 记录的元数据字段(为nil时不记录元数据)
+	symlinks    string                      // 软链接处理策略(为空时跳过软链接)
 
 	// 通道
 	resultCh chan HashResult   // 哈希结果通道
 	writeCh  chan WriteRequest // 写入请求通道
+	window   chan struct{}     // 重排窗口(按顺序输出时限制在途文件数)
 
 	// 控制
 	ctx    context.Context         // 上下文
 	cancel context.CancelCauseFunc // 上下文取消函数
 
 	// 状态
+	walkErr     error          // 流式遍历或压缩包读取返回的第一个错误(计算任务全部完成后可读取)
 	wg          sync.WaitGroup // 并发任务等待组
 	writerWg    sync.WaitGroup // 写入协程等待组
 	errors      []error        // 错误列表
 	errorsMutex sync.Mutex     // 错误列表互斥锁
 
 	// 统计
-	processedCount atomic.Int64 // 已处理文件数
-	errorCount     atomic.Int64 // 错误计数
+	discoveredCount atomic.Int64 // 已发现(已分发)的文件数
+	completedCount  atomic.Int64 // 已完成计算的文件数(包括出错和跳过的文件)
+	discoveryDone   atomic.Bool  // 文件是否已全部发现
+	processedCount  atomic.Int64 // 已处理文件数
+	errorCount      atomic.Int64 // 错误计数
+	reusedCount     atomic.Int64 // 沿用已有校验文件哈希值的文件数
 }
 
 // NewHashTaskManager 创建哈希任务管理器
@@ -65,23 +110,63 @@ type HashTaskManager struct {
 // 返回值:
 //   - *HashTaskManager: 哈希任务管理器
 func NewHashTaskManager(files []string, hashType string) *HashTaskManager {
-	ctx, cancel := context.WithCancelCause(context.Background())
+	m := newHashTaskManager(hashType, len(files))
+	m.files = files
+	return m
+}
+
+// NewStreamHashTaskManager 创建流式哈希任务管理器
+//
+// 参数:
+//   - walk: 流式文件来源, 每发现一个文件调用一次 visit, visit 返回 false 时应停止遍历
+//   - hashType: 哈希类型
+//
+// 返回值:
+//   - *HashTaskManager: 哈希任务管理器
+//
+// 注意:
+//   - 文件边发现边计算, 无需先收集完整的文件列表; 遍历错误在 Run 返回后通过 walkErr 获取
+func NewStreamHashTaskManager(walk func(visit visitFunc) error, hashType string) *HashTaskManager {
+	m := newHashTaskManager(hashType, -1)
+	m.walk = walk
+	return m
+}
+
+// newHashTaskManager 创建哈希任务管理器
+//
+// 参数:
+//   - hashType: 哈希类型
+//   - fileCount: 文件数量(未知时为-1)
+//
+// 返回值:
+//   - *HashTaskManager: 哈希任务管理器
+func newHashTaskManager(hashType string, fileCount int) *HashTaskManager {
+	ctx, cancel := context.WithCancelCause(hashCmdCtx)
 
-	// 根据CPU核心数和文件数量调整并发数
-	concurrency := runtime.NumCPU() * 2
-	if concurrency > len(files) {
-		concurrency = len(files) // 如果并发数大于文件数量，则使用文件数量作为并发数
+	// 根据CPU核心数和文件数量调整并发数, 通过 --jobs 指定时不受50的上限约束
+	concurrency := min(runtime.NumCPU()*2, 50)
+	if hashCmdWorkers > 0 {
+		concurrency = hashCmdWorkers
+	}
+	if fileCount >= 0 && concurrency > fileCount {
+		concurrency = fileCount // 如果并发数大于文件数量，则使用文件数量作为并发数
 	}
 
-	// 并发数不能大于50
-	if concurrency > 50 {
-		concurrency = 50
+	// 解析算法列表, 解析失败时保留原值, 由计算阶段报告错误
+	hashTypes, err := digest.ParseAlgorithms(hashType)
+	if err != nil {
+		hashTypes = []string{hashType}
 	}
 
 	return &HashTaskManager{
-		files:       files,                                // 文件列表
 		hashType:    hashType,                             // 哈希类型
+		hashTypes:   hashTypes,                            // 哈希算法列表
 		concurrency: concurrency,                          // 并发数
+		cache:       hashCmdCache,                         // 哈希缓存
+		records:     hashCmdRecorder,                      // 结构化输出记录器
+		chunkSize:   hashCmdChunkBytes,                    // 分块大小
+		metadata:    hashCmdMetaFields,                    // 元数据字段
+		symlinks:    hashCmdLinkPolicy,                    // 软链接处理策略
 		resultCh:    make(chan HashResult, concurrency*2), // 适当的缓冲区
 		writeCh:     make(chan WriteRequest, 100),         // 写入请求缓冲区
 		ctx:         ctx,                                  // 上下文
@@ -97,6 +182,12 @@ func NewHashTaskManager(files []string, hashType string) *HashTaskManager {
 func (m *HashTaskManager) Run() []error {
 	defer m.cancel(nil)
 
+	// 默认按文件列表顺序输出, 保证多次运行结果一致
+	m.ordered = hashCmdOrder.Get() != orderCompletion
+	if m.ordered {
+		m.window = make(chan struct{}, m.concurrency*reorderWindowFactor)
+	}
+
 	// 启动写入协程
 	if hashCmdWrite.Get() {
 		m.writerWg.Go(
@@ -106,6 +197,12 @@ func (m *HashTaskManager) Run() []error {
 		)
 	}
 
+	// 显示已发现和已计算的文件数
+	if hashCmdProgress.Get() {
+		stopProgress := m.startProgress()
+		defer stopProgress()
+	}
+
 	// 启动结果处理协程
 	var resultWg sync.WaitGroup
 	resultWg.Add(1)
@@ -135,8 +232,19 @@ func (m *HashTaskManager) Run() []error {
 
 // startComputeWorkers 启动计算工作池
 func (m *HashTaskManager) startComputeWorkers() {
+	// 压缩包条目只能按包内顺序依次读取, 由单个协程计算
+	if m.archives != nil {
+		m.wg.Go(
+			func() {
+				defer m.discoveryDone.Store(true)
+				m.processArchives()
+			},
+		)
+		return
+	}
+
 	// 创建文件任务通道
-	fileCh := make(chan string, m.concurrency)
+	fileCh := make(chan fileTask, m.concurrency)
 
 	// 启动工作协程
 	for i := 0; i < m.concurrency; i++ {
@@ -150,28 +258,119 @@ func (m *HashTaskManager) startComputeWorkers() {
 	// 分发文件任务
 	go func() {
 		defer close(fileCh)
-		for _, file := range m.files {
+		defer m.discoveryDone.Store(true)
+
+		index := 0
+		dispatch := func(file string) bool {
+			// 按顺序输出时, 等待重排窗口有空位后再分发
+			if m.ordered {
+				select {
+				case m.window <- struct{}{}:
+				case <-m.ctx.Done():
+					return false
+				}
+			}
+
 			select {
-			case fileCh <- file:
+			case fileCh <- fileTask{index: index, path: file}:
 			case <-m.ctx.Done():
+				return false
+			}
+
+			index++
+			m.discoveredCount.Add(1)
+			return true
+		}
+
+		// 流式来源: 遍历器每发现一个文件立即分发
+		if m.walk != nil {
+			m.walkErr = m.walk(dispatch)
+			return
+		}
+
+		for _, file := range m.files {
+			if !dispatch(file) {
 				return
 			}
 		}
 	}()
 }
 
+// startProgress 启动进度条, 在标准错误中显示已发现和已计算的文件数
+//
+// 返回值:
+//   - func(): 停止并清除进度条的函数
+func (m *HashTaskManager) startProgress() func() {
+	bar := progressbar.NewOptions64(
+		1,                                      // 进度条总长度(随发现的文件数更新)
+		progressbar.OptionSetWriter(os.Stderr), // 输出到标准错误, 不影响计算结果
+		progressbar.OptionClearOnFinish(),      // 结束时清除进度条
+		progressbar.OptionSetElapsedTime(true), // 显示已用时间
+		progressbar.OptionSetRenderBlankState(true),        // 在进度条完成之前显示空白状态
+		progressbar.OptionShowCount(),                      // 显示已计算/已发现的文件数
+		progressbar.OptionSetTheme(progressbar.ThemeASCII), // ASCII 进度条主题
+		progressbar.OptionFullWidth(),                      // 设置进度条为终端最大宽度
+	)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.updateProgress(bar)
+			case <-done:
+				m.updateProgress(bar)
+				_ = bar.Finish()
+				_ = bar.Close()
+				return
+			}
+		}
+	})
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// updateProgress 根据当前统计刷新进度条
+//
+// 参数:
+//   - bar: 进度条
+//
+// 注意:
+//   - 文件尚未全部发现时总数多计1, 避免进度条提前结束
+func (m *HashTaskManager) updateProgress(bar *progressbar.ProgressBar) {
+	discovered, completed := m.discoveredCount.Load(), m.completedCount.Load()
+
+	total := discovered
+	if !m.discoveryDone.Load() {
+		total++
+		bar.Describe(fmt.Sprintf("正在计算(已发现 %d 个文件)", discovered))
+	} else {
+		bar.Describe(fmt.Sprintf("正在计算(共 %d 个文件)", discovered))
+	}
+
+	bar.ChangeMax64(max(total, 1))
+	_ = bar.Set64(completed)
+}
+
 // computeWorker 计算工作协程
 //
 // 参数:
-//   - fileCh: 文件路径通道
-func (m *HashTaskManager) computeWorker(fileCh <-chan string) {
+//   - fileCh: 文件任务通道
+func (m *HashTaskManager) computeWorker(fileCh <-chan fileTask) {
 	for {
 		select {
-		case filePath, ok := <-fileCh:
+		case task, ok := <-fileCh:
 			if !ok {
 				return // 通道已关闭
 			}
-			m.processFile(filePath)
+			m.processFile(task.index, task.path)
 
 		case <-m.ctx.Done():
 			return // 上下文已取消
@@ -182,11 +381,13 @@ func (m *HashTaskManager) computeWorker(fileCh <-chan string) {
 // processFile 处理单个文件
 //
 // 参数:
+//   - index: 文件序号
 //   - filePath: 要处理的文件路径
-func (m *HashTaskManager) processFile(filePath string) {
+func (m *HashTaskManager) processFile(index int, filePath string) {
 	defer func() {
 		if r := recover(); r != nil {
 			result := HashResult{
+				Index:    index,
 				FilePath: filePath,
 				Error:    fmt.Errorf("处理文件 %s 时发生panic: %v", filePath, r),
 			}
@@ -195,34 +396,73 @@ func (m *HashTaskManager) processFile(filePath string) {
 	}()
 
 	// 检查文件状态
-	if skip, err := shouldSkipFile(filePath); err != nil {
+	isLink, err := shouldSkipFile(filePath)
+	if err != nil {
 		result := HashResult{
+			Index:    index,
 			FilePath: filePath,
 			Error:    fmt.Errorf("检查文件 %s 状态失败: %w", filePath, err),
 		}
 		// 发送结果并返回
 		m.sendResult(result)
 		return
-	} else if skip {
-		return // 跳过文件
+	} else if isLink && m.symlinks != types.SymlinksLink && m.symlinks != types.SymlinksFollow {
+		// 跳过文件, 仍需发送结果以推进输出顺序
+		m.sendResult(HashResult{Index: index, FilePath: filePath, Skipped: true})
+		return
 	}
 
 	// 创建结果对象
 	result := HashResult{
+		Index:    index,    // 文件序号
 		FilePath: filePath, // 文件路径
 	}
 
-	// 计算哈希值, 并设置结果的哈希值和错误信息
-	if hashCmdProgress.Get() {
-		result.HashValue, result.Error = hash.ChecksumProgress(filePath, m.hashType)
+	// 计算前获取文件状态, 计算期间文件被修改时下次增量更新会重新计算
+	info, statErr := os.Stat(filePath)
+
+	// 计算哈希值, 并设置结果的哈希值和错误信息(增量更新时未修改的文件沿用原哈希值)
+	if isLink && m.symlinks == types.SymlinksLink {
+		// link 策略下软链接以链接目标作为内容
+		result.HashValues, result.Error = digest.ChecksumLink(filePath, m.hashTypes)
+	} else if hashValue, ok := m.previous.lookup(filePath); ok {
+		result.HashValues = []string{hashValue}
+		m.reusedCount.Add(1)
+	} else if m.chunkSize > 0 {
+		// 分块哈希值不在缓存中, 始终读取文件
+		result.HashValues, result.Chunks, result.Size, result.Error = digest.ChecksumChunks(filePath, m.hashTypes, m.chunkSize)
 	} else {
-		result.HashValue, result.Error = hash.Checksum(filePath, m.hashType)
+		result.HashValues, result.Error = m.checksum(filePath)
+	}
+	if result.Error == nil {
+		result.HashValue = result.HashValues[0]
+	}
+	if statErr == nil {
+		result.ModTime = info.ModTime()
+		if result.Chunks == nil {
+			result.Size = info.Size() // 分块模式下使用实际读取的字节数, 保证与分块范围一致
+		}
+	}
+	if result.Error == nil && m.metadata != nil {
+		result.Metadata, result.Error = metadata.Collect(filePath, m.metadata)
 	}
 
 	// 发送结果
 	m.sendResult(result)
 }
 
+// checksum 一次读取计算文件所有算法的哈希值, 文件未变化时优先使用缓存结果
+//
+// 参数:
+//   - filePath: 文件路径
+//
+// 返回值:
+//   - []string: 文件的十六进制哈希值(与算法列表顺序一致)
+//   - error: 错误信息
+func (m *HashTaskManager) checksum(filePath string) ([]string, error) {
+	return m.cache.ChecksumMulti(filePath, m.hashTypes, digest.ChecksumMulti)
+}
+
 // sendResult 发送计算结果
 //
 // 参数：
@@ -237,34 +477,144 @@ func (m *HashTaskManager) sendResult(result HashResult) {
 }
 
 // resultCollector 结果收集协程
+//
+// 注意:
+//   - 按顺序输出时, 先到达的后序结果暂存在重排缓冲区中, 直到前序结果全部输出
 func (m *HashTaskManager) resultCollector() {
+	if !m.ordered {
+		for result := range m.resultCh {
+			m.handleResult(result)
+		}
+		return
+	}
+
+	pending := make(map[int]HashResult, cap(m.window)) // 重排缓冲区
+	next := 0                                          // 下一个待输出的文件序号
+
 	for result := range m.resultCh {
-		if result.Error != nil {
-			m.addError(result.Error)
-			m.errorCount.Add(1)
-			continue
+		pending[result.Index] = result
+
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			m.handleResult(r)
+			next++
+			<-m.window // 释放重排窗口
 		}
+	}
+}
+
+// handleResult 处理单个计算结果(输出到控制台或写入文件)
+//
+// 参数:
+//   - result: 计算结果
+func (m *HashTaskManager) handleResult(result HashResult) {
+	m.completedCount.Add(1)
+
+	if result.Skipped {
+		return
+	}
 
-		// 输出到控制台
-		if !hashCmdWrite.Get() {
-			fmt.Printf("%s\t%q\n", result.HashValue, result.FilePath)
+	// 输出结构化记录(包括出错的文件)
+	if m.records != nil {
+		if err := m.records.add(result, m.hashTypes); err != nil {
+			m.addError(err)
 		}
+	}
 
-		// 发送写入请求
-		if hashCmdWrite.Get() {
-			content := fmt.Sprintf("%s\t%q\n", result.HashValue, result.FilePath)
-			m.requestWrite(content)
+	if result.Error != nil {
+		m.addError(result.Error)
+		m.errorCount.Add(1)
+		return
+	}
+
+	// 输出到控制台
+	if !hashCmdWrite.Get() && m.records == nil {
+		fmt.Print(m.formatConsoleLine(result))
+	}
+
+	// 发送写入请求, 每个算法写入各自的校验文件
+	if hashCmdWrite.Get() {
+		for i, hashType := range m.hashTypes {
+			m.requestWrite(i, m.formatManifestEntry(hashType, i, result))
 		}
+	}
+
+	m.processedCount.Add(1)
+}
+
+// formatManifestEntry 生成写入校验文件的记录
+//
+// 参数:
+//   - hashType: 校验文件对应的哈希算法
+//   - i: 算法在算法列表中的序号
+//   - result: 计算结果
+//
+// 返回值:
+//   - string: 以换行符结尾的记录, 文件状态(仅 --update)、元数据和分块哈希值依次紧跟在文件记录之后
+func (m *HashTaskManager) formatManifestEntry(hashType string, i int, result HashResult) string {
+	line := types.FormatChecksumLine(hashCmdFormat.Get(), digest.Tag(hashType), result.HashValues[i], result.FilePath)
+	recordStat := m.previous != nil && !result.ModTime.IsZero()
+	if !recordStat && result.Metadata == nil && i >= len(result.Chunks) {
+		return line
+	}
+
+	var b strings.Builder
+	b.WriteString(line)
+	if recordStat {
+		b.WriteString(types.FormatStatLine(result.Size, result.ModTime.UnixNano()))
+	}
+	if result.Metadata != nil {
+		b.WriteString(types.FormatMetadataLine(result.Metadata.Encode()))
+	}
+	if i >= len(result.Chunks) {
+		return b.String()
+	}
+	for index, chunk := range result.Chunks[i] {
+		start := int64(index) * m.chunkSize
+		end := min(start+m.chunkSize, result.Size) - 1
+		b.WriteString(types.FormatChunkLine(start, end, chunk))
+	}
+	return b.String()
+}
+
+// formatConsoleLine 生成控制台输出记录
+//
+// 参数:
+//   - result: 计算结果
+//
+// 返回值:
+//   - string: 以换行符结尾的输出记录
+//
+// 注意:
+//   - 多个算法时按算法顺序输出多列哈希值, BSD 格式每个算法输出一行带标签的记录
+func (m *HashTaskManager) formatConsoleLine(result HashResult) string {
+	format := hashCmdFormat.Get()
+
+	if len(m.hashTypes) == 1 {
+		return types.FormatChecksumLine(format, digest.Tag(m.hashTypes[0]), result.HashValue, result.FilePath)
+	}
 
-		m.processedCount.Add(1)
+	if format == types.ChecksumFormatBSD {
+		var b strings.Builder
+		for i, hashType := range m.hashTypes {
+			b.WriteString(types.FormatChecksumLine(format, digest.Tag(hashType), result.HashValues[i], result.FilePath))
+		}
+		return b.String()
 	}
+
+	return types.FormatChecksumLine(format, "", strings.Join(result.HashValues, "  "), result.FilePath)
 }
 
 // requestWrite 请求写入
 //
 // 参数:
+//   - target: 目标校验文件序号
 //   - content: 要写入的内容
-func (m *HashTaskManager) requestWrite(content string) {
+func (m *HashTaskManager) requestWrite(target int, content string) {
 	// 检查上下文是否已取消
 	if m.ctx.Err() != nil {
 		return
@@ -272,6 +622,7 @@ func (m *HashTaskManager) requestWrite(content string) {
 
 	req := WriteRequest{
 		Content: content,             // 要写入的内容
+		Target:  target,              // 目标校验文件序号
 		Done:    make(chan error, 1), // 写入完成信号
 	}
 
@@ -293,42 +644,82 @@ func (m *HashTaskManager) requestWrite(content string) {
 
 // writerWorker 写入工作协程
 func (m *HashTaskManager) writerWorker() {
-	// 初始化文件写入器
-	wrapper, err := m.initFileWriter()
-	if err != nil {
-		m.addError(fmt.Errorf("初始化文件写入器失败: %w", err))
-		return
-	}
-	defer m.closeWriter(wrapper)
+	var wrappers []*FileWriterWrapper
+	var initErr error
+	defer func() {
+		for _, wrapper := range wrappers {
+			m.closeWriter(wrapper)
+		}
+	}()
 
 	// 处理写入请求
 	for req := range m.writeCh {
-		err := m.writeContent(wrapper, req.Content)
-		req.Done <- err
+		// 收到第一个写入请求时再创建校验文件, 没有任何文件时不会生成(或覆盖)校验文件
+		if wrappers == nil && initErr == nil {
+			wrappers, initErr = m.initFileWriters()
+			if initErr != nil {
+				m.addError(fmt.Errorf("初始化文件写入器失败: %w", initErr))
+			}
+		}
+
+		// 初始化失败时继续消费写入请求, 避免结果处理协程阻塞
+		if initErr != nil {
+			req.Done <- initErr
+			continue
+		}
+
+		req.Done <- m.writeContent(wrappers[req.Target], req.Content)
 	}
 }
 
+// initFileWriters 初始化所有算法的文件写入器, 每个算法一个校验文件
+//
+// 返回值:
+//   - []*FileWriterWrapper: 文件写入器包装(与算法列表顺序一致)
+//   - error: 错误信息, 失败时已创建的临时文件会被删除
+func (m *HashTaskManager) initFileWriters() ([]*FileWriterWrapper, error) {
+	wrappers := make([]*FileWriterWrapper, 0, len(m.hashTypes))
+	for _, hashType := range m.hashTypes {
+		wrapper, err := m.initFileWriter(hashType)
+		if err != nil {
+			for _, w := range wrappers {
+				w.atomic.Abort()
+			}
+			return nil, err
+		}
+		wrappers = append(wrappers, wrapper)
+	}
+	return wrappers, nil
+}
+
 // initFileWriter 初始化文件写入器
 //
+// 参数:
+//   - hashType: 校验文件对应的哈希算法
+//
 // 返回值:
 //   - *FileWriterWrapper: 文件写入器包装
 //   - error: 错误信息，如果发生错误则返回非nil值
-func (m *HashTaskManager) initFileWriter() (*FileWriterWrapper, error) {
-	file, err := os.OpenFile(types.OutputFileName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+func (m *HashTaskManager) initFileWriter(hashType string) (*FileWriterWrapper, error) {
+	// 先写入临时文件, 全部写入完成后再替换校验文件
+	fileName := outputFileName(hashType, len(m.hashTypes) > 1)
+	atomicFile, err := common.CreateAtomicFile(fileName, 0644)
 	if err != nil {
-		_ = file.Close()
-		return nil, fmt.Errorf("打开文件 %s 失败: %w", types.OutputFileName, err)
+		return nil, fmt.Errorf("打开文件 %s 失败: %w", fileName, err)
 	}
 
-	// 写入文件头
-	if err := m.writeFileHeader(file, hashCmdType.Get()); err != nil {
-		_ = file.Close()
-		return nil, fmt.Errorf("写入文件头失败: %w", err)
+	// 写入文件头(GNU/BSD 格式没有文件头, 以保持与外部工具兼容)
+	if hashCmdFormat.Get() == types.ChecksumFormatFck {
+		if err := m.writeFileHeader(atomicFile.File, hashType); err != nil {
+			atomicFile.Abort()
+			return nil, fmt.Errorf("写入文件头失败: %w", err)
+		}
 	}
 
 	return &FileWriterWrapper{
-		file:   file,
-		writer: bufio.NewWriter(file),
+		file:   atomicFile.File,
+		writer: bufio.NewWriter(atomicFile),
+		atomic: atomicFile,
 	}, nil
 }
 
@@ -349,6 +740,10 @@ func (m *HashTaskManager) writeFileHeader(file *os.File, hashType string) error
 	header := &types.ChecksumHeader{
 		HashType:  hashType,                                 // 哈希类型
 		Timestamp: time.Now().Format("2006-01-02 15:04:05"), // 生成时间戳
+		Keyed:     digest.HMACEnabled(),                     // HMAC 密钥模式
+		ChunkSize: m.chunkSize,                              // 分块大小
+		Metadata:  m.metadata,                               // 元数据字段
+		Symlinks:  m.symlinks,                               // 软链接处理策略
 	}
 
 	if hashCmdLocal.Get() {
@@ -394,12 +789,16 @@ func (m *HashTaskManager) writeContent(wrapper *FileWriterWrapper, content strin
 type FileWriterWrapper struct {
 	file   *os.File
 	writer *bufio.Writer
+	atomic *common.AtomicFile // 原子写入文件(关闭时替换目标文件)
 }
 
 // closeWriter 关闭写入器
 //
 // 参数:
 //   - wrapper: 文件写入器包装
+//
+// 注意:
+//   - 流式遍历或压缩包读取返回错误、存在无法计算的文件或收到中断信号时校验文件缺少部分文件的记录, 放弃写入并保留原校验文件(及其签名)
 func (m *HashTaskManager) closeWriter(wrapper *FileWriterWrapper) {
 	var errs []error
 
@@ -407,8 +806,18 @@ func (m *HashTaskManager) closeWriter(wrapper *FileWriterWrapper) {
 		errs = append(errs, fmt.Errorf("flush失败: %w", err))
 	}
 
-	if err := wrapper.file.Close(); err != nil {
-		errs = append(errs, fmt.Errorf("关闭文件失败: %w", err))
+	switch {
+	case wrapper.atomic == nil:
+		if err := wrapper.file.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭文件失败: %w", err))
+		}
+	case len(errs) > 0 || m.walkErr != nil || m.hasErrors() || m.ctx.Err() != nil:
+		// 写入不完整、遍历未完成、部分文件计算失败或已中断时保留原校验文件
+		wrapper.atomic.Abort()
+	default:
+		if err := wrapper.atomic.Commit(); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	if len(errs) > 0 {
@@ -428,6 +837,13 @@ func (m *HashTaskManager) addError(err error) {
 	m.errors = append(m.errors, err)
 }
 
+// hasErrors 线程安全地检查是否已记录错误
+func (m *HashTaskManager) hasErrors() bool {
+	m.errorsMutex.Lock()
+	defer m.errorsMutex.Unlock()
+	return len(m.errors) > 0
+}
+
 // GetStats 获取统计信息
 //
 // 返回值:
@@ -437,21 +853,26 @@ func (m *HashTaskManager) GetStats() (processed, errors int64) {
 	return m.processedCount.Load(), m.errorCount.Load()
 }
 
-// hashRunTasksRefactored 重构后的任务执行函数
+// outputFileName 获取校验文件名
 //
 // 参数:
-//   - files: 文件列表
-//   - hashType: 哈希类型函数
+//   - hashType: 哈希算法
+//   - multi: 是否同时计算多个算法
 //
 // 返回:
-//   - []error: 错误列表
-func hashRunTasksRefactored(files []string, hashType string) []error {
-	if len(files) == 0 {
-		return nil
+//   - string: 单个算法时为 --output-file 指定的文件(默认 checksum.hash),
+//     多个算法时在扩展名前插入算法名(如 checksum.<算法>.hash)
+func outputFileName(hashType string, multi bool) string {
+	name := hashCmdOutputFile.Get()
+	if name == "" {
+		name = types.OutputFileName
+	}
+	if !multi {
+		return name
 	}
 
-	manager := NewHashTaskManager(files, hashType)
-	return manager.Run()
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hashType + ext
 }
 
 // shouldSkipFile 检查是否应该跳过文件
@@ -462,6 +883,9 @@ func hashRunTasksRefactored(files []string, hashType string) []error {
 // 返回:
 //   - bool: 如果应该跳过文件，则返回true；否则返回false
 //   - error: 错误信息，如果发生错误则返回非nil值
+//
+// 注意:
+//   - 返回 true 表示文件为软链接, 由调用方按软链接处理策略跳过、以链接目标作为内容计算或跟随计算
 func shouldSkipFile(filePath string) (bool, error) {
 	fileInfo, err := os.Lstat(filePath)
 	if err != nil {
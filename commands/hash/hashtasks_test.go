@@ -7,6 +7,10 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/hashcache"
+	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
 // TestNewHashTaskManager 测试哈希任务管理器创建
@@ -103,6 +107,218 @@ func TestHashTaskManagerRunWithWrite(t *testing.T) {
 	}
 }
 
+// TestHashTaskManagerRunWithGNUFormat 测试以GNU格式写入校验文件
+func TestHashTaskManagerRunWithGNUFormat(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "test.txt"), []byte("abc"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdWrite.Set("true")
+	_ = hashCmdProgress.Set("false")
+	_ = hashCmdType.Set("sha256")
+	_ = hashCmdFormat.Set("gnu")
+
+	manager := NewHashTaskManager([]string{"test.txt"}, "sha256")
+	if errors := manager.Run(); len(errors) > 0 {
+		t.Fatalf("Run() 返回错误: %v", errors)
+	}
+
+	content, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+
+	want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  test.txt\n"
+	if string(content) != want {
+		t.Errorf("校验文件内容不正确: got %q, want %q", content, want)
+	}
+}
+
+// TestHashTaskManagerRunWithMultipleTypes 测试一次读取计算多个算法
+func TestHashTaskManagerRunWithMultipleTypes(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "test.txt"), []byte("abc"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdWrite.Set("true")
+	_ = hashCmdProgress.Set("false")
+	_ = hashCmdType.Set("md5,sha256")
+	_ = hashCmdFormat.Set("gnu")
+
+	manager := NewHashTaskManager([]string{"test.txt"}, hashCmdType.Get())
+	if errors := manager.Run(); len(errors) > 0 {
+		t.Fatalf("Run() 返回错误: %v", errors)
+	}
+
+	want := map[string]string{
+		"checksum.md5.hash":    "900150983cd24fb0d6963f7d28e17f72  test.txt\n",
+		"checksum.sha256.hash": "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  test.txt\n",
+	}
+	for name, wantContent := range want {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("读取校验文件 %s 失败: %v", name, err)
+		}
+		if string(content) != wantContent {
+			t.Errorf("校验文件 %s 内容不正确: got %q, want %q", name, content, wantContent)
+		}
+	}
+
+	if _, err := os.Stat(types.OutputFileName); !os.IsNotExist(err) {
+		t.Errorf("多个算法时不应生成 %s", types.OutputFileName)
+	}
+}
+
+// TestHashTaskManagerRunOrdered 测试按文件列表顺序输出
+func TestHashTaskManagerRunOrdered(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	// 前面的文件较大, 使其通常晚于后面的文件完成计算
+	var files []string
+	for i := 0; i < 40; i++ {
+		name := fmt.Sprintf("file%02d.txt", i)
+		content := strings.Repeat("x", (40-i)*4096)
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+		files = append(files, name)
+	}
+
+	// 软链接会被跳过, 但不能阻塞后续结果的输出
+	if err := os.Symlink("file00.txt", "file05.link"); err == nil {
+		files = append(files[:6], append([]string{"file05.link"}, files[6:]...)...)
+	}
+
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdWrite.Set("true")
+	_ = hashCmdProgress.Set("false")
+	_ = hashCmdFormat.Set("gnu")
+
+	manager := NewHashTaskManager(files, "md5")
+	if errors := manager.Run(); len(errors) > 0 {
+		t.Fatalf("Run() 返回错误: %v", errors)
+	}
+
+	content, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 40 {
+		t.Fatalf("输出行数不正确: got %d, want 40", len(lines))
+	}
+	for i, line := range lines {
+		want := fmt.Sprintf("file%02d.txt", i)
+		if !strings.HasSuffix(line, "  "+want) {
+			t.Errorf("第%d行顺序不正确: got %q, want 文件 %s", i+1, line, want)
+		}
+	}
+}
+
+// TestHashTaskManagerRunWithCache 测试哈希缓存命中与失效
+func TestHashTaskManagerRunWithCache(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("test content"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	cache, err := hashcache.Open(filepath.Join(tempDir, "cache", "hash-cache.db"))
+	if err != nil {
+		t.Fatalf("打开哈希缓存失败: %v", err)
+	}
+	defer func() { _ = cache.Close() }()
+
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdWrite.Set("false")
+	_ = hashCmdProgress.Set("false")
+
+	hashCmdCache = cache
+	defer func() { hashCmdCache = nil }()
+
+	// 第一次计算未命中, 第二次命中
+	for i := 0; i < 2; i++ {
+		if errs := NewHashTaskManager([]string{testFile}, "sha256").Run(); len(errs) > 0 {
+			t.Fatalf("Run() 返回错误: %v", errs)
+		}
+	}
+	if hits, misses := cache.Stats(); hits != 1 || misses != 1 {
+		t.Errorf("缓存统计不正确: 命中 %d, 未命中 %d, 期望命中 1, 未命中 1", hits, misses)
+	}
+
+	// 修改文件内容和修改时间后缓存失效
+	if err := os.WriteFile(testFile, []byte("changed content"), 0644); err != nil {
+		t.Fatalf("修改测试文件失败: %v", err)
+	}
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(testFile, future, future); err != nil {
+		t.Fatalf("修改文件时间失败: %v", err)
+	}
+
+	manager := NewHashTaskManager([]string{testFile}, "sha256")
+	hashValues, err := manager.checksum(testFile)
+	if err != nil {
+		t.Fatalf("计算哈希失败: %v", err)
+	}
+	if want, _ := digest.Checksum(testFile, "sha256"); hashValues[0] != want {
+		t.Errorf("文件变化后哈希值不正确: got %s, want %s", hashValues[0], want)
+	}
+	if hits, misses := cache.Stats(); hits != 1 || misses != 2 {
+		t.Errorf("文件变化后不应命中缓存: 命中 %d, 未命中 %d", hits, misses)
+	}
+
+	// 同一文件不同算法使用独立的缓存条目
+	if _, ok := cache.Get(testFile, "md5", mustStat(t, testFile)); ok {
+		t.Error("不同算法不应命中缓存")
+	}
+
+	// 删除文件后清理失效条目
+	if err := os.Remove(testFile); err != nil {
+		t.Fatalf("删除测试文件失败: %v", err)
+	}
+	removed, err := cache.Prune()
+	if err != nil {
+		t.Fatalf("清理缓存失败: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("清理条目数不正确: got %d, want 1", removed)
+	}
+}
+
+// mustStat 获取文件信息, 失败时终止测试
+func mustStat(t *testing.T, filePath string) os.FileInfo {
+	t.Helper()
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatalf("获取文件信息失败: %v", err)
+	}
+	return info
+}
+
 // TestHashTaskManagerRunWithErrors 测试错误处理
 func TestHashTaskManagerRunWithErrors(t *testing.T) {
 	// 使用不存在的文件
@@ -216,8 +432,8 @@ func TestShouldSkipFile(t *testing.T) {
 	}
 }
 
-// TestHashRunTasksRefactored 测试重构后的任务执行函数
-func TestHashRunTasksRefactored(t *testing.T) {
+// TestHashTaskManagerRunFiles 测试按文件列表执行哈希任务
+func TestHashTaskManagerRunFiles(t *testing.T) {
 	// 创建临时文件
 	tempDir := t.TempDir()
 	var files []string
@@ -235,19 +451,24 @@ func TestHashRunTasksRefactored(t *testing.T) {
 	_ = hashCmdWrite.Set("false")
 	_ = hashCmdProgress.Set("false")
 
-	errors := hashRunTasksRefactored(files, "md5")
+	errors := NewHashTaskManager(files, "md5").Run()
 
 	if len(errors) > 0 {
-		t.Errorf("hashRunTasksRefactored() 返回错误: %v", errors)
+		t.Errorf("Run() 返回错误: %v", errors)
 	}
 }
 
-// TestHashRunTasksRefactoredEmpty 测试空文件列表
-func TestHashRunTasksRefactoredEmpty(t *testing.T) {
-	errors := hashRunTasksRefactored([]string{}, "md5")
+// TestHashTaskManagerRunEmpty 测试空文件列表
+func TestHashTaskManagerRunEmpty(t *testing.T) {
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdWrite.Set("false")
+	_ = hashCmdProgress.Set("false")
+
+	errors := NewHashTaskManager([]string{}, "md5").Run()
 
-	if errors != nil {
-		t.Errorf("hashRunTasksRefactored() 对空列表应该返回nil，但返回: %v", errors)
+	if len(errors) > 0 {
+		t.Errorf("Run() 对空列表不应返回错误，但返回: %v", errors)
 	}
 }
 
@@ -339,8 +560,8 @@ func BenchmarkHashTaskManager(b *testing.B) {
 	}
 }
 
-// BenchmarkHashRunTasksRefactored 性能测试重构函数
-func BenchmarkHashRunTasksRefactored(b *testing.B) {
+// BenchmarkHashTaskManagerRun 性能测试哈希任务执行
+func BenchmarkHashTaskManagerRun(b *testing.B) {
 	// 创建临时文件
 	tempDir := b.TempDir()
 	testFile := filepath.Join(tempDir, "benchmark.txt")
@@ -359,6 +580,117 @@ func BenchmarkHashRunTasksRefactored(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = hashRunTasksRefactored(files, "md5")
+		_ = NewHashTaskManager(files, "md5").Run()
+	}
+}
+
+// TestNewHashTaskManagerJobs 测试通过 --jobs 指定并发数
+func TestNewHashTaskManagerJobs(t *testing.T) {
+	defer func() { hashCmdWorkers = 0 }()
+
+	files := make([]string, 200)
+	for i := range files {
+		files[i] = fmt.Sprintf("file%d.txt", i)
+	}
+
+	tests := []struct {
+		name    string
+		workers int
+		files   []string
+		want    int
+	}{
+		{name: "指定并发数", workers: 3, files: files, want: 3},
+		{name: "不受默认上限约束", workers: 100, files: files, want: 100},
+		{name: "不超过文件数量", workers: 8, files: files[:2], want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashCmdWorkers = tt.workers
+			manager := NewHashTaskManager(tt.files, "md5")
+			if manager.concurrency != tt.want {
+				t.Errorf("并发数不正确: got %d, want %d", manager.concurrency, tt.want)
+			}
+		})
+	}
+}
+
+// TestStreamHashTaskManager 测试流式哈希任务管理器
+func TestStreamHashTaskManager(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var files []string
+	for i := 0; i < 20; i++ {
+		file := filepath.Join(tempDir, fmt.Sprintf("file%02d.txt", i))
+		if err := os.WriteFile(file, []byte(file), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+		files = append(files, file)
+	}
+
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdCacheMode.Set("off")
+
+	walkErr := fmt.Errorf("遍历失败")
+	manager := NewStreamHashTaskManager(func(visit visitFunc) error {
+		for _, file := range files {
+			if !visit(file) {
+				return nil
+			}
+		}
+		return walkErr
+	}, "md5")
+
+	records := captureRecords(t, func() {
+		manager.records = hashCmdRecorder
+		if errors := manager.Run(); len(errors) > 0 {
+			t.Errorf("Run() 返回错误: %v", errors)
+		}
+	})
+
+	if manager.walkErr != walkErr {
+		t.Errorf("遍历错误未传递: got %v", manager.walkErr)
+	}
+	if got := manager.discoveredCount.Load(); got != int64(len(files)) {
+		t.Errorf("已发现文件数不正确: got %d, want %d", got, len(files))
+	}
+
+	// 默认按发现顺序输出
+	if len(records) != len(files) {
+		t.Fatalf("记录数不正确: got %d, want %d", len(records), len(files))
+	}
+	for i, file := range files {
+		if records[i].Path != file {
+			t.Errorf("第 %d 条记录顺序不正确: got %s, want %s", i, records[i].Path, file)
+		}
+	}
+}
+
+// TestStreamHashTaskManagerWalkError 测试遍历未完成时放弃写入校验文件
+func TestStreamHashTaskManagerWalkError(t *testing.T) {
+	tempDir := t.TempDir()
+	file := filepath.Join(tempDir, "a.txt")
+	if err := os.WriteFile(file, []byte("a"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdCacheMode.Set("off")
+	_ = hashCmdWrite.Set("true")
+	outputFile := filepath.Join(tempDir, "checksum.hash")
+	_ = hashCmdOutputFile.Set(outputFile)
+
+	manager := NewStreamHashTaskManager(func(visit visitFunc) error {
+		visit(file)
+		return fmt.Errorf("权限不足: %s", filepath.Join(tempDir, "locked"))
+	}, "md5")
+	if errors := manager.Run(); len(errors) > 0 {
+		t.Errorf("Run() 返回错误: %v", errors)
+	}
+
+	if _, err := os.Stat(outputFile); !os.IsNotExist(err) {
+		t.Errorf("遍历未完成时不应写入校验文件: %v", err)
 	}
 }
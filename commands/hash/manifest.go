@@ -0,0 +1,283 @@
+// Package hash 实现了校验文件的写入和增量更新。
+// 该文件负责将所有目标路径的哈希值写入同一组校验文件, 并在 --update 模式下读取已有校验文件,
+// 仅重新计算新增或已修改的文件, 移除已删除文件的记录。
+package hash
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/signature"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// manifest 已有校验文件的内容
+type manifest struct {
+	hashes map[string]string   // 文件路径 -> 哈希值
+	stats  map[string]fileStat // 文件路径 -> 记录的文件状态(由 --update 写入)
+}
+
+// fileStat 校验文件中记录的文件状态
+type fileStat struct {
+	size    int64 // 文件大小
+	modTime int64 // 修改时间(Unix纳秒)
+}
+
+// writeChecksumFiles 计算所有目标路径的哈希值并写入校验文件
+//
+// 参数:
+//   - cl: 颜色库对象
+//   - targetPaths: 目标路径列表
+//   - hashType: 哈希算法类型
+//
+// 返回:
+//   - error: 错误信息
+//
+// 注意:
+//   - 校验文件及其签名文件不参与计算
+//   - 文件边遍历边计算, 多个目标路径按参数顺序写入
+//   - --update 模式下, 已记录且大小和修改时间均未变化的文件沿用原哈希值, 并为每个文件记录当前的大小和修改时间
+//   - 任意目标路径无法完整遍历、任意文件无法计算哈希值或收到中断信号时不写入(或覆盖)校验文件
+func writeChecksumFiles(cl *colorlib.ColorLib, targetPaths []string, hashType string) error {
+	hashTypes, err := digest.ParseAlgorithms(hashType)
+	if err != nil {
+		return err
+	}
+	if hashCmdUpdate.Get() && len(hashTypes) > 1 {
+		return exitcode.Usagef("--update 仅支持单个哈希算法: %s", hashType)
+	}
+
+	// 读取已有校验文件
+	var previous *manifest
+	if hashCmdUpdate.Get() {
+		previous, err = loadManifest(outputFileName(hashTypes[0], false), hashTypes[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	cl.PrintOk("正在将哈希值写入文件，请稍候...")
+
+	// 校验文件本身不参与计算
+	outputs := make(map[string]struct{})
+	for _, name := range outputFileNames(hashType) {
+		outputs[absPath(name)] = struct{}{}
+		outputs[absPath(signature.SignatureFileName(name))] = struct{}{}
+	}
+
+	// 多个目标路径可能包含相同的文件, 需要去重
+	var seen map[string]struct{}
+	if len(targetPaths) > 1 {
+		seen = make(map[string]struct{})
+	}
+
+	// 增量更新时记录仍然存在的已记录文件, 用于统计被移除的记录
+	kept := make(map[string]struct{})
+
+	// 边遍历边计算, 所有目标路径写入同一组校验文件
+	count := 0
+	failed := 0 // 无法处理的目标路径数量
+	manager := NewStreamHashTaskManager(func(visit visitFunc) error {
+		for _, targetPath := range targetPaths {
+			stopped := false
+			err := walkTargetFiles(cl, filepath.Clean(targetPath), func(file string) bool {
+				if seen != nil {
+					if _, ok := seen[file]; ok {
+						return true
+					}
+					seen[file] = struct{}{}
+				}
+
+				// 跳过校验文件本身
+				if _, ok := outputs[absPath(file)]; ok {
+					return true
+				}
+
+				if previous != nil {
+					if _, ok := previous.hashes[file]; ok {
+						kept[file] = struct{}{}
+					}
+				}
+
+				count++
+				stopped = !visit(file)
+				return !stopped
+			})
+			if stopped {
+				return nil
+			}
+			if err != nil {
+				failed++
+				cl.PrintErrorf("处理路径 %s 时发生错误: %v\n", targetPath, err)
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d 个路径遍历失败", failed)
+		}
+		return nil
+	}, hashType)
+	manager.previous = previous
+
+	// 执行哈希任务
+	errors := manager.Run()
+	if hashCmdCtx.Err() != nil {
+		cl.PrintErrorf("操作已中断, 未写入校验文件 %s\n", strings.Join(outputFileNames(hashType), ", "))
+		return exitcode.ErrInterrupted
+	}
+	printUniqueErrors(cl, errors)
+
+	// 遍历未完成时校验文件缺少部分记录, 写入协程已放弃写入
+	if manager.walkErr != nil {
+		cl.PrintErrorf("存在无法遍历的路径, 未写入校验文件 %s\n", strings.Join(outputFileNames(hashType), ", "))
+		return failedError(failed + len(errors))
+	}
+	// 部分文件计算失败时同样放弃写入, 避免提交缺少记录且与原签名不一致的校验文件
+	if len(errors) > 0 {
+		cl.PrintErrorf("存在无法计算哈希值的文件, 未写入校验文件 %s\n", strings.Join(outputFileNames(hashType), ", "))
+		return failedError(failed + len(errors))
+	}
+
+	if count == 0 {
+		cl.PrintWarnf("没有找到任何文件\n")
+		return failedError(failed)
+	}
+
+	names := strings.Join(outputFileNames(hashType), ", ")
+	if previous == nil {
+		cl.PrintOkf("已将哈希值写入文件 %s, 共处理 %d 个文件\n", names, count)
+	} else {
+		reused := int(manager.reusedCount.Load())
+		removed := len(previous.hashes) - len(kept)
+		cl.PrintOkf("已更新校验文件 %s: 重新计算 %d 个, 未变化 %d 个, 移除 %d 个\n", names, count-reused, reused, removed)
+	}
+
+	if err := signChecksumFiles(cl, outputFileNames(hashType)); err != nil {
+		return err
+	}
+	return failedError(failed)
+}
+
+// loadManifest 读取已有校验文件
+//
+// 参数:
+//   - path: 校验文件路径
+//   - hashType: 当前使用的哈希算法
+//
+// 返回:
+//   - *manifest: 校验文件内容(文件不存在时为空)
+//   - error: 错误信息
+//
+// 注意:
+//   - 算法或 HMAC 模式与当前不一致的记录会被忽略, 对应文件将重新计算
+//   - 紧跟在记录之后的 #stat# 行为该文件的状态, 没有状态的记录(如 GNU/BSD 格式)对应文件将重新计算
+func loadManifest(path, hashType string) (*manifest, error) {
+	m := &manifest{hashes: make(map[string]string), stats: make(map[string]fileStat)}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取校验文件 %s 失败: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	lastPath := "" // 上一条有效记录的文件路径(文件状态记录属于该文件)
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+
+		if size, modTime, ok := types.ParseStatLine(line); ok {
+			if lastPath != "" {
+				m.stats[lastPath] = fileStat{size: size, modTime: modTime}
+			}
+			continue
+		}
+		lastPath = ""
+
+		// fck 文件头: #hashType#timestamp#mode, HMAC 模式须与当前一致
+		if lineNum == 1 && strings.HasPrefix(line, "#") {
+			headerField, _, _ := strings.Cut(strings.TrimPrefix(line, "#"), "#")
+			headerType, keyed := types.ParseHashType(headerField)
+			if !strings.EqualFold(headerType, hashType) || keyed != digest.HMACEnabled() {
+				return m, nil
+			}
+			continue
+		}
+
+		// 无文件头的校验文件不可能是 HMAC 模式生成的
+		if lineNum == 1 && digest.HMACEnabled() {
+			return m, nil
+		}
+
+		format, tag, hashValue, filePath, ok := types.ParseChecksumLine(line)
+		if !ok {
+			continue
+		}
+
+		// 无文件头格式根据标签或哈希值长度判断算法
+		if format == types.ChecksumFormatBSD {
+			if algorithm, ok := digest.FromTag(tag); !ok || algorithm != hashType {
+				continue
+			}
+		}
+		if len(hashValue) != digest.HexLen(hashType) {
+			continue
+		}
+
+		m.hashes[filePath] = strings.ToLower(hashValue)
+		lastPath = filePath
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取校验文件 %s 失败: %w", path, err)
+	}
+
+	return m, nil
+}
+
+// lookup 查询文件在校验文件中的哈希值
+//
+// 参数:
+//   - filePath: 文件路径(与校验文件中记录的路径形式一致)
+//
+// 返回:
+//   - string: 哈希值
+//   - bool: 文件已记录且大小和修改时间与记录的状态一致时返回 true
+//
+// 注意:
+//   - 不依赖校验文件本身的修改时间, 通过 cp -p、rsync -t 或解压替换的文件修改时间与记录不同, 同样会重新计算
+func (m *manifest) lookup(filePath string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+
+	hashValue, ok := m.hashes[filePath]
+	if !ok {
+		return "", false
+	}
+	stat, ok := m.stats[filePath]
+	if !ok {
+		return "", false
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil || info.Size() != stat.size || info.ModTime().UnixNano() != stat.modTime {
+		return "", false
+	}
+
+	return hashValue, true
+}
+
+// absPath 获取绝对路径, 失败时返回清理后的原路径
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
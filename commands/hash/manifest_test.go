@@ -0,0 +1,326 @@
+package hash
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// TestLoadManifest 测试读取不同格式的校验文件
+func TestLoadManifest(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		content  string
+		hashType string
+		want     map[string]string
+	}{
+		{
+			name:     "fck格式",
+			content:  "#md5#2024-01-01 00:00:00#PORTABLE\n900150983cd24fb0d6963f7d28e17f72\t\"a b.txt\"\n",
+			hashType: "md5",
+			want:     map[string]string{"a b.txt": "900150983cd24fb0d6963f7d28e17f72"},
+		},
+		{
+			name:     "fck格式算法不一致",
+			content:  "#sha1#2024-01-01 00:00:00#PORTABLE\n900150983cd24fb0d6963f7d28e17f72\t\"a.txt\"\n",
+			hashType: "md5",
+			want:     map[string]string{},
+		},
+		{
+			name:     "GNU格式",
+			content:  "900150983cd24fb0d6963f7d28e17f72  a.txt\n\\900150983cd24fb0d6963f7d28e17f72  line\\nbreak.txt\n",
+			hashType: "md5",
+			want: map[string]string{
+				"a.txt":           "900150983cd24fb0d6963f7d28e17f72",
+				"line\nbreak.txt": "900150983cd24fb0d6963f7d28e17f72",
+			},
+		},
+		{
+			name:     "BSD格式",
+			content:  "MD5 (a.txt) = 900150983cd24fb0d6963f7d28e17f72\nSHA1 (b.txt) = a9993e364706816aba3e25717850c26c9cd0d89d\n",
+			hashType: "md5",
+			want:     map[string]string{"a.txt": "900150983cd24fb0d6963f7d28e17f72"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, "checksum.hash")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("创建校验文件失败: %v", err)
+			}
+
+			m, err := loadManifest(path, tt.hashType)
+			if err != nil {
+				t.Fatalf("loadManifest() 返回错误: %v", err)
+			}
+			if len(m.hashes) != len(tt.want) {
+				t.Fatalf("记录数不正确: got %v, want %v", m.hashes, tt.want)
+			}
+			for p, h := range tt.want {
+				if m.hashes[p] != h {
+					t.Errorf("%s 的哈希值不正确: got %s, want %s", p, m.hashes[p], h)
+				}
+			}
+		})
+	}
+
+	// 校验文件不存在时返回空记录
+	m, err := loadManifest(filepath.Join(tempDir, "missing.hash"), "md5")
+	if err != nil || len(m.hashes) != 0 {
+		t.Errorf("校验文件不存在时应返回空记录, got %v, err %v", m, err)
+	}
+}
+
+// TestWriteChecksumFilesUpdate 测试增量更新校验文件
+func TestWriteChecksumFilesUpdate(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	for name, content := range map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"} {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("md5")
+	_ = hashCmdWrite.Set("true")
+	_ = hashCmdCacheMode.Set("off")
+	_ = hashCmdUpdate.Set("true")
+
+	// 校验文件不存在时全部计算, 并记录每个文件的大小和修改时间
+	if err := writeChecksumFiles(cl, []string{"*"}, "md5"); err != nil {
+		t.Fatalf("writeChecksumFiles() 返回错误: %v", err)
+	}
+
+	// 手动篡改未修改文件的记录, 以确认增量更新时沿用原记录而不是重新计算
+	content, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+	const fakeHash = "00000000000000000000000000000000"
+	content = []byte(strings.Replace(string(content), "0cc175b9c0f1b6a831c399e269772661", fakeHash, 1))
+	if err := os.WriteFile(types.OutputFileName, content, 0644); err != nil {
+		t.Fatalf("写入校验文件失败: %v", err)
+	}
+
+	// 以相同大小修改 b.txt 并恢复为更早的修改时间(模拟 cp -p), 删除 c.txt, 新增 d.txt
+	past := time.Now().Add(-time.Hour)
+	if err := os.WriteFile("b.txt", []byte("x"), 0644); err != nil {
+		t.Fatalf("修改测试文件失败: %v", err)
+	}
+	_ = os.Chtimes("b.txt", past, past)
+	_ = os.Remove("c.txt")
+	if err := os.WriteFile("d.txt", []byte("d"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	if err := writeChecksumFiles(cl, []string{"*"}, "md5"); err != nil {
+		t.Fatalf("writeChecksumFiles() 返回错误: %v", err)
+	}
+
+	m, err := loadManifest(types.OutputFileName, "md5")
+	if err != nil {
+		t.Fatalf("loadManifest() 返回错误: %v", err)
+	}
+
+	want := map[string]string{
+		"a.txt": fakeHash,                           // 未修改, 沿用原记录
+		"b.txt": "9dd4e461268c8034f5c8564e155c67a6", // 已修改, 重新计算
+		"d.txt": "8277e0910d750195b448797616e091ad", // 新增
+	}
+	if len(m.hashes) != len(want) {
+		t.Fatalf("记录不正确: got %v, want %v", m.hashes, want)
+	}
+	for p, h := range want {
+		if m.hashes[p] != h {
+			t.Errorf("%s 的哈希值不正确: got %s, want %s", p, m.hashes[p], h)
+		}
+		if _, ok := m.stats[p]; !ok {
+			t.Errorf("%s 没有记录文件状态", p)
+		}
+	}
+
+	// 没有记录文件状态的校验文件全部重新计算
+	_ = hashCmdUpdate.Set("false")
+	if err := writeChecksumFiles(cl, []string{"*"}, "md5"); err != nil {
+		t.Fatalf("writeChecksumFiles() 返回错误: %v", err)
+	}
+	if m, err = loadManifest(types.OutputFileName, "md5"); err != nil || len(m.stats) != 0 {
+		t.Fatalf("未指定 --update 时不应记录文件状态: %v, %v", m.stats, err)
+	}
+	for _, p := range []string{"a.txt", "b.txt", "d.txt"} {
+		if _, ok := m.lookup(p); ok {
+			t.Errorf("%s 没有记录文件状态, 不应沿用原哈希值", p)
+		}
+	}
+
+	// 不应残留临时文件
+	entries, _ := os.ReadDir(".")
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			t.Errorf("残留临时文件: %s", entry.Name())
+		}
+	}
+
+	// GNU/BSD 格式无法记录文件状态, 不支持增量更新
+	hashCmd = InitHashCmd()
+	_ = hashCmdWrite.Set("true")
+	_ = hashCmdUpdate.Set("true")
+	_ = hashCmdFormat.Set(types.ChecksumFormatGNU)
+	if err := HashCmdMain(context.Background(), cl); exitcode.Code(err) != exitcode.Usage {
+		t.Errorf("GNU 格式增量更新期望返回用法错误, 实际: %v", err)
+	}
+}
+
+// TestWriteChecksumFilesUpdateUnreadable 测试增量更新时文件无法读取则保留原校验文件
+func TestWriteChecksumFilesUpdateUnreadable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root 用户可以读取任意文件, 无法构造无法读取的文件")
+	}
+
+	tempDir := t.TempDir()
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	for name, content := range map[string]string{"a.txt": "a", "b.txt": "b"} {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("md5")
+	_ = hashCmdWrite.Set("true")
+	_ = hashCmdCacheMode.Set("off")
+	_ = hashCmdUpdate.Set("true")
+
+	if err := writeChecksumFiles(cl, []string{"*"}, "md5"); err != nil {
+		t.Fatalf("writeChecksumFiles() 返回错误: %v", err)
+	}
+	previous, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+
+	// 修改 b.txt 后使其无法读取, 增量更新时需要重新计算但读取失败
+	if err := os.WriteFile("b.txt", []byte("bb"), 0644); err != nil {
+		t.Fatalf("修改测试文件失败: %v", err)
+	}
+	if err := os.Chmod("b.txt", 0); err != nil {
+		t.Fatalf("修改文件权限失败: %v", err)
+	}
+	defer func() { _ = os.Chmod(filepath.Join(tempDir, "b.txt"), 0644) }()
+
+	if err := writeChecksumFiles(cl, []string{"*"}, "md5"); exitcode.Code(err) != exitcode.Partial {
+		t.Fatalf("writeChecksumFiles() 期望返回部分失败错误, 实际: %v", err)
+	}
+
+	// 原校验文件保持不变, b.txt 的记录不会丢失
+	content, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+	if string(content) != string(previous) {
+		t.Errorf("文件无法读取时校验文件被覆盖:\n%s", content)
+	}
+}
+
+// TestHashCmdMainInterrupted 测试收到中断信号时保留原校验文件且不遗留临时文件
+func TestHashCmdMainInterrupted(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	for name, content := range map[string]string{"a.txt": "a", "b.txt": "b"} {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+	previous := "#md5#2006-01-02 15:04:05#PORTABLE\n00000000000000000000000000000000  a.txt\n"
+	if err := os.WriteFile(types.OutputFileName, []byte(previous), 0644); err != nil {
+		t.Fatalf("创建校验文件失败: %v", err)
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	hashCmd = InitHashCmd()
+	_ = hashCmd.Parse([]string{"*"})
+	_ = hashCmdType.Set("md5")
+	_ = hashCmdWrite.Set("true")
+	_ = hashCmdCacheMode.Set("off")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := HashCmdMain(ctx, cl); err != exitcode.ErrInterrupted {
+		t.Fatalf("HashCmdMain() 期望返回中断错误, 实际: %v", err)
+	}
+
+	// 原校验文件保持不变
+	content, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+	if string(content) != previous {
+		t.Errorf("中断后校验文件被覆盖:\n%s", content)
+	}
+
+	// 不遗留临时文件
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		t.Fatalf("读取目录失败: %v", err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp") {
+			t.Errorf("中断后遗留临时文件: %s", entry.Name())
+		}
+	}
+}
+
+// TestOutputFileName 测试自定义校验文件路径
+func TestOutputFileName(t *testing.T) {
+	hashCmd = InitHashCmd()
+
+	if got := outputFileName("md5", false); got != types.OutputFileName {
+		t.Errorf("默认文件名不正确: %s", got)
+	}
+	if got := outputFileName("md5", true); got != "checksum.md5.hash" {
+		t.Errorf("多算法默认文件名不正确: %s", got)
+	}
+
+	_ = hashCmdOutputFile.Set(filepath.Join("out", "sums.txt"))
+	if got := outputFileName("md5", false); got != filepath.Join("out", "sums.txt") {
+		t.Errorf("自定义文件名不正确: %s", got)
+	}
+	if got := outputFileName("sha1", true); got != filepath.Join("out", "sums.sha1.txt") {
+		t.Errorf("多算法自定义文件名不正确: %s", got)
+	}
+}
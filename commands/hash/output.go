@@ -0,0 +1,102 @@
+// Package hash 实现了哈希结果的结构化输出。
+// 该文件负责将 hash 命令的逐文件计算结果以 JSON、NDJSON 或 CSV 记录输出, 便于脚本和流水线解析。
+package hash
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gitee.com/MM-Q/fck/commands/internal/output"
+)
+
+// hashRecordColumns CSV 表头
+var hashRecordColumns = []string{"path", "algorithm", "digest", "size", "error"}
+
+// hashRecord 单个文件单个算法的哈希记录
+type hashRecord struct {
+	Path      string `json:"path"`            // 文件路径
+	Algorithm string `json:"algorithm"`       // 哈希算法
+	Digest    string `json:"digest"`          // 十六进制哈希值(出错时为空)
+	Size      int64  `json:"size"`            // 文件大小
+	Error     string `json:"error,omitempty"` // 错误信息
+}
+
+// Values 返回 CSV 字段值
+func (r hashRecord) Values() []string {
+	return []string{r.Path, r.Algorithm, r.Digest, strconv.FormatInt(r.Size, 10), r.Error}
+}
+
+// hashSummary 哈希计算汇总
+type hashSummary struct {
+	Files  int `json:"files"`  // 成功计算的文件数
+	Errors int `json:"errors"` // 出错的文件数
+}
+
+// hashRecorder 哈希结果记录器
+type hashRecorder struct {
+	w       *output.Writer // 结构化记录写入器
+	summary hashSummary    // 汇总统计
+}
+
+// newHashRecorder 创建哈希结果记录器
+//
+// 参数:
+//   - w: 输出目标
+//   - format: 输出格式(json/ndjson/csv)
+//
+// 返回:
+//   - *hashRecorder: 记录器
+//   - error: 错误信息
+func newHashRecorder(w io.Writer, format string) (*hashRecorder, error) {
+	writer, err := output.NewWriter(w, format, hashRecordColumns)
+	if err != nil {
+		return nil, err
+	}
+	return &hashRecorder{w: writer}, nil
+}
+
+// add 写入单个文件的计算结果
+//
+// 参数:
+//   - result: 计算结果
+//   - hashTypes: 哈希算法列表
+//
+// 返回:
+//   - error: 错误信息
+//
+// 注意:
+//   - 多个算法时每个算法输出一条记录; 出错时输出一条包含全部算法名称和错误信息的记录
+func (r *hashRecorder) add(result HashResult, hashTypes []string) error {
+	if result.Error != nil {
+		r.summary.Errors++
+		return r.w.Write(hashRecord{
+			Path:      result.FilePath,
+			Algorithm: strings.Join(hashTypes, ","),
+			Size:      result.Size,
+			Error:     result.Error.Error(),
+		})
+	}
+
+	r.summary.Files++
+	for i, hashType := range hashTypes {
+		if err := r.w.Write(hashRecord{
+			Path:      result.FilePath,
+			Algorithm: hashType,
+			Digest:    result.HashValues[i],
+			Size:      result.Size,
+		}); err != nil {
+			return fmt.Errorf("输出记录失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// close 输出汇总信息并结束输出
+//
+// 返回:
+//   - error: 错误信息
+func (r *hashRecorder) close() error {
+	return r.w.Close(r.summary)
+}
@@ -0,0 +1,135 @@
+package hash
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runWithRecorder 使用指定输出格式执行哈希任务并返回输出内容
+func runWithRecorder(t *testing.T, format string, files []string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	recorder, err := newHashRecorder(&buf, format)
+	if err != nil {
+		t.Fatalf("newHashRecorder() 返回错误: %v", err)
+	}
+
+	hashCmdRecorder = recorder
+	defer func() { hashCmdRecorder = nil }()
+
+	NewHashTaskManager(files, hashCmdType.Get()).Run()
+
+	if err := recorder.close(); err != nil {
+		t.Fatalf("close() 返回错误: %v", err)
+	}
+	return buf.String()
+}
+
+// TestHashRecorderJSON 测试JSON格式输出
+func TestHashRecorderJSON(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("abc"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	missing := filepath.Join(tempDir, "missing.txt")
+
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("md5,sha256")
+
+	out := runWithRecorder(t, "json", []string{testFile, missing})
+
+	var report struct {
+		Files   []hashRecord `json:"files"`
+		Summary hashSummary  `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("解析JSON输出失败: %v\n%s", err, out)
+	}
+
+	if len(report.Files) != 3 {
+		t.Fatalf("期望 3 条记录(两个算法 + 一个错误), 实际 %d 条: %s", len(report.Files), out)
+	}
+	if r := report.Files[0]; r.Algorithm != "md5" || r.Digest != "900150983cd24fb0d6963f7d28e17f72" || r.Size != 3 {
+		t.Errorf("md5 记录不正确: %+v", r)
+	}
+	if r := report.Files[1]; r.Algorithm != "sha256" || r.Digest != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("sha256 记录不正确: %+v", r)
+	}
+	if r := report.Files[2]; r.Path != missing || r.Error == "" || r.Digest != "" {
+		t.Errorf("错误记录不正确: %+v", r)
+	}
+	if report.Summary.Files != 1 || report.Summary.Errors != 1 {
+		t.Errorf("汇总信息不正确: %+v", report.Summary)
+	}
+}
+
+// TestHashRecorderNDJSON 测试NDJSON格式输出
+func TestHashRecorderNDJSON(t *testing.T) {
+	tempDir := t.TempDir()
+	var files []string
+	for i := 0; i < 3; i++ {
+		path := filepath.Join(tempDir, fmt.Sprintf("file%d.txt", i))
+		if err := os.WriteFile(path, []byte(path), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+		files = append(files, path)
+	}
+
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("sha1")
+
+	lines := strings.Split(strings.TrimSpace(runWithRecorder(t, "ndjson", files)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("期望 4 行(3 条记录 + 汇总), 实际 %d 行", len(lines))
+	}
+
+	for i, line := range lines[:3] {
+		var r hashRecord
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("解析第 %d 行失败: %v", i+1, err)
+		}
+		if r.Path != files[i] || r.Algorithm != "sha1" {
+			t.Errorf("第 %d 行记录不正确: %+v", i+1, r)
+		}
+	}
+
+	if !strings.HasPrefix(lines[3], `{"summary":`) {
+		t.Errorf("最后一行应为汇总信息: %s", lines[3])
+	}
+}
+
+// TestHashRecorderCSV 测试CSV格式输出
+func TestHashRecorderCSV(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "a,b.txt") // 文件名包含逗号
+	if err := os.WriteFile(testFile, []byte("abc"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("md5")
+
+	rows, err := csv.NewReader(strings.NewReader(runWithRecorder(t, "csv", []string{testFile}))).ReadAll()
+	if err != nil {
+		t.Fatalf("解析CSV输出失败: %v", err)
+	}
+
+	want := [][]string{
+		hashRecordColumns,
+		{testFile, "md5", "900150983cd24fb0d6963f7d28e17f72", "3", ""},
+	}
+	if fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Errorf("CSV输出不正确: got %v, want %v", rows, want)
+	}
+}
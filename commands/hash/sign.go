@@ -0,0 +1,67 @@
+// Package hash 实现了校验文件的签名。
+// 该文件负责在 -w --sign 模式下读取私钥, 并在校验文件写入完成后生成分离式签名文件(<校验文件>.sig)。
+package hash
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/signature"
+)
+
+// hashCmdSignKey 本次运行使用的签名私钥(未指定 --sign 时为nil)
+var hashCmdSignKey ed25519.PrivateKey
+
+// loadSignKey 根据 --sign 标志读取签名私钥
+//
+// 返回:
+//   - ed25519.PrivateKey: 私钥, 未指定 --sign 时返回nil
+//   - error: 错误信息
+func loadSignKey() (ed25519.PrivateKey, error) {
+	keyPath := hashCmdSign.Get()
+	if keyPath == "" {
+		return nil, nil
+	}
+	if !hashCmdWrite.Get() {
+		return nil, exitcode.Usagef("--sign 需要与 -w 一起使用")
+	}
+
+	key, err := signature.LoadPrivateKey(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取签名私钥失败: %w", err)
+	}
+	return key, nil
+}
+
+// signChecksumFiles 为写入的校验文件生成签名文件
+//
+// 参数:
+//   - cl: 颜色库对象
+//   - names: 校验文件路径列表
+//
+// 返回:
+//   - error: 错误信息
+//
+// 注意:
+//   - 未指定 --sign 时, 已存在的签名文件与新内容不再匹配, 仅输出警告
+func signChecksumFiles(cl *colorlib.ColorLib, names []string) error {
+	for _, name := range names {
+		sigPath := signature.SignatureFileName(name)
+
+		if hashCmdSignKey == nil {
+			if _, err := os.Stat(sigPath); err == nil {
+				cl.PrintWarnf("校验文件 %s 已更新, 原签名文件 %s 不再有效, 请使用 --sign 重新签名\n", name, sigPath)
+			}
+			continue
+		}
+
+		if _, err := signature.SignFile(name, hashCmdSignKey); err != nil {
+			return fmt.Errorf("签名校验文件 %s 失败: %w", name, err)
+		}
+		cl.PrintOkf("已签名校验文件 %s, 签名文件 %s (密钥 %s)\n", name, sigPath, signature.KeyID(hashCmdSignKey.Public().(ed25519.PublicKey)))
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package hash
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/signature"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// TestWriteChecksumFilesSign 测试写入校验文件时生成签名
+func TestWriteChecksumFilesSign(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	if err := os.WriteFile("a.txt", []byte("a"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	pub, priv, err := signature.GenerateKey()
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "key")
+	if err := signature.WritePrivateKey(keyPath, priv); err != nil {
+		t.Fatalf("写入私钥失败: %v", err)
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	// --sign 需要与 -w 一起使用
+	hashCmd = InitHashCmd()
+	_ = hashCmdSign.Set(keyPath)
+	if _, err := loadSignKey(); err == nil {
+		t.Fatal("未指定 -w 时 --sign 应返回错误")
+	}
+
+	_ = hashCmdWrite.Set("true")
+	_ = hashCmdCacheMode.Set("off")
+	if hashCmdSignKey, err = loadSignKey(); err != nil {
+		t.Fatalf("loadSignKey() 返回错误: %v", err)
+	}
+	defer func() { hashCmdSignKey = nil }()
+
+	// 连续写入两次, 签名文件不应被计入校验文件
+	for i := 0; i < 2; i++ {
+		if err := writeChecksumFiles(cl, []string{"*"}, "md5"); err != nil {
+			t.Fatalf("writeChecksumFiles() 返回错误: %v", err)
+		}
+	}
+
+	m, err := loadManifest(types.OutputFileName, "md5")
+	if err != nil {
+		t.Fatalf("loadManifest() 返回错误: %v", err)
+	}
+	if len(m.hashes) != 1 {
+		t.Errorf("校验文件记录不正确: %v", m.hashes)
+	}
+
+	content, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+	if err := signature.Verify(content, signature.SignatureFileName(types.OutputFileName), ed25519.PublicKey(pub)); err != nil {
+		t.Errorf("签名验证失败: %v", err)
+	}
+}
+
+// TestWriteChecksumFilesSignKeepsOldOnError 测试部分文件计算失败时保留原校验文件及其签名
+func TestWriteChecksumFilesSignKeepsOldOnError(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 切换到临时目录
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	if err := os.WriteFile("a.txt", []byte("a"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	pub, priv, err := signature.GenerateKey()
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "key")
+	if err := signature.WritePrivateKey(keyPath, priv); err != nil {
+		t.Fatalf("写入私钥失败: %v", err)
+	}
+
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	hashCmd = InitHashCmd()
+	_ = hashCmdSign.Set(keyPath)
+	_ = hashCmdWrite.Set("true")
+	_ = hashCmdRecursion.Set("true")
+	_ = hashCmdCacheMode.Set("off")
+	if hashCmdSignKey, err = loadSignKey(); err != nil {
+		t.Fatalf("loadSignKey() 返回错误: %v", err)
+	}
+	defer func() { hashCmdSignKey = nil }()
+	hashCmdLinkPolicy = types.SymlinksFollow
+	defer func() { hashCmdLinkPolicy = "" }()
+
+	if err := writeChecksumFiles(cl, []string{"."}, "md5"); err != nil {
+		t.Fatalf("writeChecksumFiles() 返回错误: %v", err)
+	}
+	previous, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+
+	// 跟随悬空链接时计算失败
+	if err := os.WriteFile("b.txt", []byte("b"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	if err := os.Symlink("missing.txt", "dangling"); err != nil {
+		t.Fatalf("创建软链接失败: %v", err)
+	}
+	if err := writeChecksumFiles(cl, []string{"."}, "md5"); exitcode.Code(err) != exitcode.Partial {
+		t.Fatalf("writeChecksumFiles() 期望返回部分失败错误, 实际: %v", err)
+	}
+
+	// 原校验文件和签名保持不变且仍然有效
+	content, err := os.ReadFile(types.OutputFileName)
+	if err != nil {
+		t.Fatalf("读取校验文件失败: %v", err)
+	}
+	if string(content) != string(previous) {
+		t.Errorf("部分文件计算失败时校验文件被覆盖:\n%s", content)
+	}
+	if err := signature.Verify(content, signature.SignatureFileName(types.OutputFileName), ed25519.PublicKey(pub)); err != nil {
+		t.Errorf("签名验证失败: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+// Package hash 实现了标准输入和字符串的哈希计算。
+// 该文件负责处理路径 "-"(标准输入)和 --string 指定的文本, 计算结果与文件使用相同的输出格式。
+package hash
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+)
+
+// stdinPath 表示标准输入的路径
+const stdinPath = "-"
+
+// processStdin 流式计算标准输入的哈希值
+//
+// 参数:
+//   - cl: 颜色库对象
+//   - hashType: 哈希算法类型
+//
+// 返回:
+//   - int: 计算失败时返回 1, 否则返回 0
+func processStdin(cl *colorlib.ColorLib, hashType string) int {
+	return processReader(cl, stdinPath, os.Stdin, hashType)
+}
+
+// processString 计算文本的哈希值
+//
+// 参数:
+//   - cl: 颜色库对象
+//   - text: 要计算哈希值的文本
+//   - hashType: 哈希算法类型
+//
+// 返回:
+//   - int: 计算失败时返回 1, 否则返回 0
+//
+// 注意:
+//   - 输出记录的路径为文本本身
+func processString(cl *colorlib.ColorLib, text, hashType string) int {
+	return processReader(cl, text, strings.NewReader(text), hashType)
+}
+
+// processReader 计算数据流的哈希值并按当前输出格式输出
+//
+// 参数:
+//   - cl: 颜色库对象
+//   - label: 输出记录中显示的路径
+//   - reader: 数据源读取器
+//   - hashType: 哈希算法类型
+//
+// 返回:
+//   - int: 计算失败时返回 1, 否则返回 0
+func processReader(cl *colorlib.ColorLib, label string, reader io.Reader, hashType string) int {
+	manager := NewHashTaskManager(nil, hashType)
+
+	result := HashResult{FilePath: label}
+	result.HashValues, result.Size, result.Error = digest.HashReaderMulti(reader, manager.hashTypes)
+	if result.Error == nil {
+		result.HashValue = result.HashValues[0]
+	}
+
+	// 与文件结果使用相同的输出流程
+	manager.handleResult(result)
+	if len(manager.errors) > 0 {
+		printUniqueErrors(cl, manager.errors)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,112 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"gitee.com/MM-Q/colorlib"
+)
+
+// captureRecords 使用 NDJSON 记录器执行函数并返回解析后的记录
+func captureRecords(t *testing.T, fn func()) []hashRecord {
+	t.Helper()
+
+	var buf bytes.Buffer
+	recorder, err := newHashRecorder(&buf, "ndjson")
+	if err != nil {
+		t.Fatalf("newHashRecorder() 返回错误: %v", err)
+	}
+	hashCmdRecorder = recorder
+	defer func() { hashCmdRecorder = nil }()
+
+	fn()
+
+	var records []hashRecord
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var r hashRecord
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("解析记录失败: %v", err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+// TestProcessString 测试计算文本的哈希值
+func TestProcessString(t *testing.T) {
+	cl := colorlib.NewColorLib()
+
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("md5,sha256")
+
+	records := captureRecords(t, func() {
+		processString(cl, "abc", hashCmdType.Get())
+	})
+
+	if len(records) != 2 {
+		t.Fatalf("期望 2 条记录, 实际 %d 条", len(records))
+	}
+	if records[0].Path != "abc" || records[0].Digest != "900150983cd24fb0d6963f7d28e17f72" || records[0].Size != 3 {
+		t.Errorf("md5 记录不正确: %+v", records[0])
+	}
+	if records[1].Digest != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("sha256 记录不正确: %+v", records[1])
+	}
+}
+
+// TestProcessStdin 测试流式计算标准输入的哈希值
+func TestProcessStdin(t *testing.T) {
+	cl := colorlib.NewColorLib()
+
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("sha256")
+
+	// 使用管道替换标准输入
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("创建管道失败: %v", err)
+	}
+	oldStdin := os.Stdin
+	os.Stdin = r
+	defer func() {
+		os.Stdin = oldStdin
+		_ = r.Close()
+	}()
+
+	data := bytes.Repeat([]byte("abc"), 100000)
+	go func() {
+		_, _ = w.Write(data)
+		_ = w.Close()
+	}()
+
+	records := captureRecords(t, func() {
+		processStdin(cl, hashCmdType.Get())
+	})
+
+	if len(records) != 1 {
+		t.Fatalf("期望 1 条记录, 实际 %d 条", len(records))
+	}
+	if records[0].Path != stdinPath || records[0].Size != int64(len(data)) || records[0].Error != "" {
+		t.Errorf("标准输入记录不正确: %+v", records[0])
+	}
+}
+
+// TestHashCmdMainStdinWithWrite 测试标准输入不能与写入文件同时使用
+func TestHashCmdMainStdinWithWrite(t *testing.T) {
+	cl := colorlib.NewColorLib()
+
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdWrite.Set("true")
+	_ = hashCmdString.Set("abc")
+
+	if err := HashCmdMain(context.Background(), cl); err == nil {
+		t.Error("--string 与 -w 同时使用时应返回错误")
+	}
+}
@@ -0,0 +1,202 @@
+// Package hash 实现了目录 Merkle 树摘要模式。
+// 该文件负责 hash --tree 的文件收集、树计算以及按指定深度输出或写入各目录的子树摘要。
+package hash
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/common"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
+	"gitee.com/MM-Q/fck/commands/internal/merkle"
+	"gitee.com/MM-Q/fck/commands/internal/signature"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// treeCmdMain 处理 --tree 模式
+//
+// 参数:
+//   - cl: 颜色库对象
+//   - targetPaths: 目标目录列表
+//
+// 返回:
+//   - error: 错误信息
+func treeCmdMain(cl *colorlib.ColorLib, targetPaths []string) error {
+	if hashCmdLocal.Get() {
+		return exitcode.Usagef("--tree 模式不支持 --local, 树摘要只依赖相对路径")
+	}
+	if hashCmdFormat.Get() != types.ChecksumFormatFck {
+		return exitcode.Usagef("--tree 模式仅支持 fck 格式输出")
+	}
+	if hashTypes, err := digest.ParseAlgorithms(hashCmdType.Get()); err != nil {
+		return err
+	} else if len(hashTypes) > 1 {
+		return exitcode.Usagef("--tree 模式仅支持单个哈希算法: %s", hashCmdType.Get())
+	}
+	if hashCmdDepth.Get() < 0 {
+		return exitcode.Usagef("--depth 不能为负数: %d", hashCmdDepth.Get())
+	}
+
+	// 未指定路径时计算当前目录
+	if len(targetPaths) == 0 {
+		targetPaths = []string{"."}
+	}
+
+	opts := treeOptions()
+
+	var lines []string
+	var failed int
+	for _, targetPath := range targetPaths {
+		treeLines, err := processTreePath(targetPath, opts)
+		if hashCmdCtx.Err() != nil {
+			return exitcode.ErrInterrupted
+		}
+		if err != nil {
+			failed++
+			cl.PrintErrorf("处理路径 %s 时发生错误: %v\n", targetPath, err)
+			continue
+		}
+		lines = append(lines, treeLines...)
+	}
+
+	if !hashCmdWrite.Get() {
+		for _, line := range lines {
+			fmt.Print(line)
+		}
+		return failedError(failed)
+	}
+
+	if len(lines) == 0 {
+		return failedError(failed)
+	}
+
+	if err := writeTreeFile(lines, opts); err != nil {
+		return err
+	}
+	cl.PrintOkf("已将目录树摘要写入文件 %s, 共 %d 个目录\n", outputFileName(opts.Algorithm, false), len(lines))
+	if err := signChecksumFiles(cl, []string{outputFileName(opts.Algorithm, false)}); err != nil {
+		return err
+	}
+	return failedError(failed)
+}
+
+// treeOptions 根据命令行标志生成树计算选项
+//
+// 返回:
+//   - merkle.Options: 树计算选项
+//
+// 注意:
+//   - --output-file 指定的校验文件位于目录内时同样不参与计算, 否则写入校验文件后根摘要会发生变化
+func treeOptions() merkle.Options {
+	algorithm := strings.ToLower(hashCmdType.Get())
+	fileName := outputFileName(algorithm, false)
+	return merkle.Options{
+		Algorithm: algorithm,
+		Mode:      hashCmdTreeMode.Get(),
+		Symlinks:  hashCmdTreeSymlinks.Get(),
+		Hidden:    hashCmdHidden.Get(),
+		Workers:   hashCmdWorkers,
+		Exclude:   []string{fileName, signature.SignatureFileName(fileName)},
+	}
+}
+
+// processTreePath 计算单个目录的 Merkle 树并生成输出记录
+//
+// 参数:
+//   - targetPath: 目标目录
+//   - opts: 树计算选项
+//
+// 返回:
+//   - []string: 各目录的子树摘要记录(根目录在前)
+//   - error: 错误信息
+//
+// 注意:
+//   - 与 check 使用相同的 merkle.Collect 收集文件(不跟随软链接), 是否计入软链接只由 --tree-symlinks 决定
+func processTreePath(targetPath string, opts merkle.Options) ([]string, error) {
+	info, err := os.Stat(targetPath)
+	if err != nil {
+		return nil, wrapStatError(err, targetPath)
+	}
+	if !info.IsDir() {
+		return nil, exitcode.Usagef("--tree 模式需要指定目录: %s", targetPath)
+	}
+
+	// 收集目录下的全部文件(始终递归)
+	files, err := merkle.Collect(targetPath, opts.Hidden)
+	if err != nil {
+		return nil, fmt.Errorf("收集文件失败: %w", err)
+	}
+
+	// 文件摘要优先使用哈希缓存
+	sum := func(filePath, algorithm string) (string, error) {
+		if err := hashCmdCtx.Err(); err != nil {
+			return "", err
+		}
+		return hashCmdCache.Checksum(filePath, algorithm, digest.Checksum)
+	}
+
+	tree, err := merkle.Build(targetPath, files, opts, sum)
+	if err != nil {
+		return nil, err
+	}
+
+	// 记录路径与便携模式一致, 使用相对于基准路径的路径
+	displayPaths, err := convertToRelativePaths([]string{targetPath})
+	if err != nil {
+		return nil, fmt.Errorf("转换相对路径失败: %w", err)
+	}
+	displayRoot := displayPaths[0]
+
+	var lines []string
+	for _, rel := range tree.Dirs(hashCmdDepth.Get()) {
+		d, _ := tree.Digest(rel)
+		lines = append(lines, types.FormatChecksumLine(types.ChecksumFormatFck, digest.Tag(opts.Algorithm), d, treeEntryPath(displayRoot, rel)))
+	}
+
+	return lines, nil
+}
+
+// treeEntryPath 拼接目录记录路径
+//
+// 参数:
+//   - root: 目标目录的记录路径
+//   - rel: 目录在树中的相对路径
+//
+// 返回:
+//   - string: 以/分隔的目录记录路径
+func treeEntryPath(root, rel string) string {
+	if rel == merkle.RootPath {
+		return root
+	}
+	return path.Join(strings.TrimSuffix(root, "/"), rel)
+}
+
+// writeTreeFile 将目录树摘要写入校验文件
+//
+// 参数:
+//   - lines: 目录摘要记录
+//   - opts: 树计算选项(写入文件头, 供 check 使用相同选项校验)
+//
+// 返回:
+//   - error: 错误信息
+func writeTreeFile(lines []string, opts merkle.Options) error {
+	header := &types.ChecksumHeader{
+		HashType:  opts.Algorithm,
+		Timestamp: time.Now().Format(types.TimestampFormat),
+		Mode:      types.ChecksumModeTree,
+		Tree:      opts.String(),
+		Keyed:     digest.HMACEnabled(),
+	}
+
+	content := header.String() + strings.Join(lines, "")
+	fileName := outputFileName(opts.Algorithm, false)
+	if err := common.WriteFileAtomic(fileName, []byte(content), 0644); err != nil {
+		return fmt.Errorf("写入文件 %s 失败: %w", fileName, err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package hash
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitee.com/MM-Q/fck/commands/internal/merkle"
+)
+
+// createTreeFixture 在指定目录下创建测试目录树
+func createTreeFixture(t *testing.T, root string) {
+	t.Helper()
+
+	files := map[string]string{
+		"a.txt":         "alpha",
+		"sub/b.txt":     "bravo",
+		"sub/deep/c.md": "charlie",
+		"other/d.bin":   "delta",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("创建目录失败: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+}
+
+// treeRoot 计算目录的根摘要
+func treeRoot(t *testing.T, dir string) string {
+	t.Helper()
+
+	lines, err := processTreePath(dir, treeOptions())
+	if err != nil {
+		t.Fatalf("processTreePath() 返回错误: %v", err)
+	}
+	if len(lines) == 0 {
+		t.Fatal("processTreePath() 没有返回任何记录")
+	}
+	return strings.SplitN(lines[0], "\t", 2)[0]
+}
+
+// TestProcessTreePath 测试目录 Merkle 树摘要
+func TestProcessTreePath(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// 初始化命令标志
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("sha256")
+	_ = hashCmdWrite.Set("false")
+
+	dirA := filepath.Join(tempDir, "a")
+	dirB := filepath.Join(tempDir, "b")
+	createTreeFixture(t, dirA)
+	createTreeFixture(t, dirB)
+
+	// 内容相同的目录根摘要一致
+	rootA := treeRoot(t, dirA)
+	if rootB := treeRoot(t, dirB); rootA != rootB {
+		t.Errorf("相同内容的目录根摘要不一致: %s != %s", rootA, rootB)
+	}
+
+	// 修改深层文件后根摘要变化
+	if err := os.WriteFile(filepath.Join(dirB, "sub", "deep", "c.md"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("修改测试文件失败: %v", err)
+	}
+	if rootB := treeRoot(t, dirB); rootA == rootB {
+		t.Error("文件内容变化后根摘要应该不同")
+	}
+
+	// 重命名文件后根摘要变化
+	if err := os.Rename(filepath.Join(dirA, "a.txt"), filepath.Join(dirA, "z.txt")); err != nil {
+		t.Fatalf("重命名测试文件失败: %v", err)
+	}
+	if renamed := treeRoot(t, dirA); renamed == rootA {
+		t.Error("文件重命名后根摘要应该不同")
+	}
+}
+
+// TestProcessTreePathDepth 测试按深度输出子树摘要
+func TestProcessTreePathDepth(t *testing.T) {
+	tempDir := t.TempDir()
+	createTreeFixture(t, tempDir)
+
+	// 切换到临时目录, 使记录路径为相对路径
+	oldDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(oldDir) }()
+	_ = os.Chdir(tempDir)
+
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("md5")
+	_ = hashCmdDepth.Set("1")
+
+	lines, err := processTreePath(".", treeOptions())
+	if err != nil {
+		t.Fatalf("processTreePath() 返回错误: %v", err)
+	}
+
+	var paths []string
+	for _, line := range lines {
+		paths = append(paths, strings.TrimSpace(strings.SplitN(line, "\t", 2)[1]))
+	}
+
+	want := []string{`"."`, `"other"`, `"sub"`}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("输出目录不正确: got %v, want %v", paths, want)
+	}
+
+	// 子树摘要与单独计算该目录的根摘要一致
+	subLines, err := processTreePath("sub", merkle.Options{Algorithm: "md5"})
+	if err != nil {
+		t.Fatalf("processTreePath() 返回错误: %v", err)
+	}
+	if strings.SplitN(subLines[0], "\t", 2)[0] != strings.SplitN(lines[2], "\t", 2)[0] {
+		t.Errorf("子树摘要与单独计算结果不一致: %q != %q", subLines[0], lines[2])
+	}
+}
+
+// TestProcessTreePathNotDir 测试对文件使用 --tree
+func TestProcessTreePathNotDir(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "file.txt")
+	if err := os.WriteFile(testFile, []byte("content"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	hashCmd = InitHashCmd()
+
+	if _, err := processTreePath(testFile, treeOptions()); err == nil {
+		t.Error("对文件使用 --tree 应该返回错误")
+	}
+}
+
+// TestProcessTreePathSymlinks 测试 --tree-symlinks 生成的根摘要与 check 的计算结果一致
+func TestProcessTreePathSymlinks(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "a.txt"), []byte("alpha"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	if err := os.Symlink("a.txt", filepath.Join(tempDir, "l")); err != nil {
+		t.Skipf("无法创建软链接: %v", err)
+	}
+
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("md5")
+	_ = hashCmdTreeSymlinks.Set("true")
+
+	// check 按相同的选项收集文件并计算根摘要
+	opts := treeOptions()
+	files, err := merkle.Collect(tempDir, opts.Hidden)
+	if err != nil {
+		t.Fatalf("merkle.Collect() 返回错误: %v", err)
+	}
+	tree, err := merkle.Build(tempDir, files, opts, nil)
+	if err != nil {
+		t.Fatalf("merkle.Build() 返回错误: %v", err)
+	}
+
+	withLinks := treeRoot(t, tempDir)
+	if withLinks != tree.Root() {
+		t.Errorf("hash 与 check 的根摘要不一致: %s != %s", withLinks, tree.Root())
+	}
+
+	// 未指定 --tree-symlinks 时软链接不参与计算
+	_ = hashCmdTreeSymlinks.Set("false")
+	if withoutLinks := treeRoot(t, tempDir); withoutLinks == withLinks {
+		t.Error("--tree-symlinks 应该将软链接计入根摘要")
+	}
+}
+
+// TestProcessTreePathOutputFile 测试 --output-file 指定的校验文件位于目录内时不参与计算
+func TestProcessTreePathOutputFile(t *testing.T) {
+	tempDir := t.TempDir()
+	createTreeFixture(t, tempDir)
+
+	hashCmd = InitHashCmd()
+	_ = hashCmdType.Set("md5")
+	_ = hashCmdOutputFile.Set(filepath.Join(tempDir, "sub", "sums.hash"))
+
+	before := treeRoot(t, tempDir)
+	for _, name := range []string{"sums.hash", "sums.hash.sig"} {
+		if err := os.WriteFile(filepath.Join(tempDir, "sub", name), []byte("#md5#2024-01-01 10:00:00#TREE\n"), 0644); err != nil {
+			t.Fatalf("创建校验文件失败: %v", err)
+		}
+	}
+	if after := treeRoot(t, tempDir); after != before {
+		t.Errorf("写入校验文件后根摘要发生变化: %s != %s", after, before)
+	}
+}
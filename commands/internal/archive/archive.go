@@ -0,0 +1,245 @@
+// Package archive 实现了压缩包内文件的流式读取。
+// 该文件提供压缩包格式识别、archive.zip!/inner/path 形式的条目路径拼接与拆分, 以及无需解压即可逐个读取条目内容的遍历函数。
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gitee.com/MM-Q/comprx"
+)
+
+// Separator 压缩包路径与条目路径之间的分隔符, 如 archive.zip!/inner/path
+const Separator = "!/"
+
+// typeTarBz2 bzip2 压缩的 tar 包(comprx 将 .bz2 视为单文件压缩, 此处单独识别)
+const typeTarBz2 comprx.CompressType = ".tar.bz2"
+
+// SupportedTypes 支持读取的压缩包格式
+var SupportedTypes = []comprx.CompressType{
+	comprx.CompressTypeZip,
+	comprx.CompressTypeTar,
+	comprx.CompressTypeTgz,
+	comprx.CompressTypeTarGz,
+	typeTarBz2,
+	comprx.CompressTypeGz,
+	comprx.CompressTypeBz2,
+	comprx.CompressTypeBzip2,
+	comprx.CompressTypeZlib,
+}
+
+// WalkFunc 压缩包条目处理函数
+//
+// 参数:
+//   - name: 条目在压缩包内的路径(使用正斜杠分隔, 不以 / 或 ./ 开头)
+//   - r: 条目内容读取器, 仅在本次调用期间有效
+//
+// 返回:
+//   - error: 返回 fs.SkipAll 时停止遍历且 Walk 返回nil, 返回其他错误时停止遍历并返回该错误
+type WalkFunc func(name string, r io.Reader) error
+
+// detectType 根据文件名识别压缩包格式
+//
+// 参数:
+//   - archivePath: 压缩包路径
+//
+// 返回:
+//   - comprx.CompressType: 压缩包格式, 不支持时为空
+func detectType(archivePath string) comprx.CompressType {
+	lower := strings.ToLower(archivePath)
+	for _, t := range SupportedTypes {
+		if strings.HasSuffix(lower, string(t)) {
+			return t
+		}
+	}
+	return ""
+}
+
+// IsSupported 判断文件是否为支持读取的压缩包
+//
+// 参数:
+//   - archivePath: 压缩包路径
+//
+// 返回:
+//   - bool: 扩展名为支持的压缩包格式时返回 true
+func IsSupported(archivePath string) bool {
+	return detectType(archivePath) != ""
+}
+
+// Join 拼接压缩包路径和条目路径
+//
+// 参数:
+//   - archivePath: 压缩包路径
+//   - name: 条目在压缩包内的路径
+//
+// 返回:
+//   - string: archive.zip!/inner/path 形式的路径
+func Join(archivePath, name string) string {
+	return archivePath + Separator + name
+}
+
+// Split 拆分 archive.zip!/inner/path 形式的路径
+//
+// 参数:
+//   - p: 待拆分的路径
+//
+// 返回:
+//   - string: 压缩包路径
+//   - string: 条目在压缩包内的路径
+//   - bool: 路径包含分隔符且分隔符前为支持的压缩包格式时返回 true
+func Split(p string) (string, string, bool) {
+	p = filepath.ToSlash(p)
+	i := strings.Index(p, Separator)
+	if i <= 0 || !IsSupported(p[:i]) {
+		return "", "", false
+	}
+
+	name := CleanName(p[i+len(Separator):])
+	if name == "" {
+		return "", "", false
+	}
+	return p[:i], name, true
+}
+
+// CleanName 规范化条目路径, 去掉开头的 / 和 ./
+//
+// 参数:
+//   - name: 条目路径
+//
+// 返回:
+//   - string: 规范化后的条目路径, 为根目录时返回空字符串
+func CleanName(name string) string {
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	return strings.TrimPrefix(name, "/")
+}
+
+// Walk 按压缩包内的顺序流式读取所有普通文件条目
+//
+// 参数:
+//   - archivePath: 压缩包路径
+//   - fn: 条目处理函数
+//
+// 返回:
+//   - error: 格式不支持、压缩包损坏或 fn 返回错误时返回错误
+//
+// 注意:
+//   - 目录、符号链接等非普通文件条目会被跳过
+//   - gz/bz2/zlib 为单文件压缩, 条目路径为去掉压缩扩展名后的文件名
+func Walk(archivePath string, fn WalkFunc) error {
+	t := detectType(archivePath)
+	if t == "" {
+		return fmt.Errorf("不支持的压缩包格式: %s", archivePath)
+	}
+
+	var err error
+	if t == comprx.CompressTypeZip {
+		err = walkZip(archivePath, fn)
+	} else {
+		err = walkStream(archivePath, t, fn)
+	}
+
+	if errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walkZip 遍历 zip 压缩包中的普通文件
+func walkZip(archivePath string, fn WalkFunc) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	for _, f := range reader.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("打开条目 %s 失败: %w", f.Name, err)
+		}
+		err = fn(CleanName(f.Name), rc)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkStream 遍历 tar 包或单文件压缩流
+func walkStream(archivePath string, t comprx.CompressType, fn WalkFunc) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	var r io.Reader = file
+	switch t {
+	case comprx.CompressTypeTgz, comprx.CompressTypeTarGz, comprx.CompressTypeGz:
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("读取 gzip 数据失败: %w", err)
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	case typeTarBz2, comprx.CompressTypeBz2, comprx.CompressTypeBzip2:
+		r = bzip2.NewReader(file)
+	case comprx.CompressTypeZlib:
+		zr, err := zlib.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("读取 zlib 数据失败: %w", err)
+		}
+		defer func() { _ = zr.Close() }()
+		r = zr
+	}
+
+	switch t {
+	case comprx.CompressTypeTar, comprx.CompressTypeTgz, comprx.CompressTypeTarGz, typeTarBz2:
+		return walkTar(r, fn)
+	default:
+		// 单文件压缩: 条目名为去掉压缩扩展名的文件名
+		base := filepath.Base(archivePath)
+		return fn(base[:len(base)-len(t)], r)
+	}
+}
+
+// walkTar 遍历 tar 流中的普通文件
+func walkTar(r io.Reader, fn WalkFunc) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			// 读完 tar 结束标记后的剩余数据, 使压缩流校验尾部的校验和
+			if _, err := io.Copy(io.Discard, r); err != nil {
+				return fmt.Errorf("读取压缩数据失败: %w", err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("读取 tar 条目失败: %w", err)
+		}
+		if !header.FileInfo().Mode().IsRegular() {
+			continue
+		}
+
+		if err := fn(CleanName(header.Name), tr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package common 提供了原子写入文件的工具函数。
+// 该文件通过 "写入同目录临时文件 + 重命名" 的方式替换目标文件, 保证写入过程中断时目标文件要么是旧内容、要么是完整的新内容。
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// AtomicFile 原子写入文件
+//
+// 注意:
+//   - 写入的数据先保存在目标文件所在目录的临时文件中, 调用 Commit 后才会替换目标文件
+type AtomicFile struct {
+	*os.File
+	path string // 目标文件路径
+}
+
+// CreateAtomicFile 创建原子写入文件
+//
+// 参数:
+//   - path: 目标文件路径
+//   - perm: 目标文件权限
+//
+// 返回:
+//   - *AtomicFile: 原子写入文件
+//   - error: 错误信息
+func CreateAtomicFile(path string, perm os.FileMode) (*AtomicFile, error) {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return nil, fmt.Errorf("设置临时文件权限失败: %w", err)
+	}
+
+	return &AtomicFile{File: tmp, path: path}, nil
+}
+
+// Path 获取目标文件路径
+func (f *AtomicFile) Path() string {
+	return f.path
+}
+
+// Commit 同步并关闭临时文件, 然后重命名为目标文件
+//
+// 返回:
+//   - error: 错误信息(失败时临时文件会被删除, 目标文件保持不变)
+func (f *AtomicFile) Commit() error {
+	if err := f.Sync(); err != nil {
+		f.Abort()
+		return fmt.Errorf("同步文件 %s 失败: %w", f.path, err)
+	}
+
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("关闭文件 %s 失败: %w", f.path, err)
+	}
+
+	if err := os.Rename(f.Name(), f.path); err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("替换文件 %s 失败: %w", f.path, err)
+	}
+
+	return nil
+}
+
+// Abort 放弃写入, 关闭并删除临时文件
+func (f *AtomicFile) Abort() {
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+}
+
+// WriteFileAtomic 原子地写入文件
+//
+// 参数:
+//   - path: 目标文件路径
+//   - data: 文件内容
+//   - perm: 文件权限
+//
+// 返回:
+//   - error: 错误信息
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := CreateAtomicFile(path, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Abort()
+		return fmt.Errorf("写入文件 %s 失败: %w", path, err)
+	}
+
+	return f.Commit()
+}
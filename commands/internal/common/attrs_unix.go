@@ -9,6 +9,8 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"strings"
+	"syscall"
 
 	"golang.org/x/sys/unix"
 )
@@ -78,3 +80,78 @@ func GetFileOwner(filePath string) (string, string) {
 
 	return userInfo.Username, groupInfo.Name
 }
+
+// GetFileID 获取文件的设备号和 inode 号, 用于唯一标识文件
+//
+// 参数:
+//   - info: 文件信息
+//
+// 返回:
+//   - uint64: 设备号
+//   - uint64: inode 号
+//   - bool: 是否成功获取
+func GetFileID(info os.FileInfo) (uint64, uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return uint64(stat.Dev), uint64(stat.Ino), true
+}
+
+// GetFileOwnerID 获取文件所有者的 uid 和 gid
+//
+// 参数:
+//   - info: 文件信息
+//
+// 返回:
+//   - uint32: 用户ID
+//   - uint32: 组ID
+//   - bool: 是否成功获取
+func GetFileOwnerID(info os.FileInfo) (uint32, uint32, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return stat.Uid, stat.Gid, true
+}
+
+// ListXattrs 读取文件的扩展属性(不跟随软链接)
+//
+// 参数:
+//   - path: 文件路径
+//
+// 返回:
+//   - map[string][]byte: 扩展属性名称到属性值的映射
+//   - error: 读取失败时返回错误, 文件系统不支持扩展属性时返回空映射
+func ListXattrs(path string) (map[string][]byte, error) {
+	size, err := unix.Llistxattr(path, nil)
+	if err != nil {
+		if err == unix.ENOTSUP {
+			return map[string][]byte{}, nil
+		}
+		return nil, err
+	}
+
+	buf := make([]byte, size)
+	if size, err = unix.Llistxattr(path, buf); err != nil {
+		return nil, err
+	}
+
+	attrs := make(map[string][]byte)
+	for _, name := range strings.Split(string(buf[:size]), "\x00") {
+		if name == "" {
+			continue
+		}
+
+		n, err := unix.Lgetxattr(path, name, nil)
+		if err != nil {
+			return nil, err
+		}
+		value := make([]byte, n)
+		if n, err = unix.Lgetxattr(path, name, value); err != nil {
+			return nil, err
+		}
+		attrs[name] = value[:n]
+	}
+	return attrs, nil
+}
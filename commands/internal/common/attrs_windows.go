@@ -6,6 +6,7 @@ package common
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"syscall"
 )
@@ -111,3 +112,53 @@ func GetFileOwner(filePath string) (string, string) {
 	// 在Windows环境下, 返回"?"占位符
 	return "?", "?"
 }
+
+// GetFileID 获取文件的设备号和 inode 号, 用于唯一标识文件
+//
+// 参数:
+//   - info - 文件信息
+//
+// 返回:
+//   - uint64 - 设备号
+//   - uint64 - inode 号
+//   - bool - 是否成功获取
+//
+// 注意:
+//   - 该函数在Windows环境下始终返回false, 调用方应退化为使用文件路径标识文件。
+func GetFileID(info os.FileInfo) (uint64, uint64, bool) {
+	_ = info
+	return 0, 0, false
+}
+
+// GetFileOwnerID 获取文件所有者的 uid 和 gid
+//
+// 参数:
+//   - info - 文件信息
+//
+// 返回:
+//   - uint32 - 用户ID
+//   - uint32 - 组ID
+//   - bool - 是否成功获取
+//
+// 注意:
+//   - 该函数在Windows环境下始终返回false。
+func GetFileOwnerID(info os.FileInfo) (uint32, uint32, bool) {
+	_ = info
+	return 0, 0, false
+}
+
+// ListXattrs 读取文件的扩展属性
+//
+// 参数:
+//   - path - 文件路径
+//
+// 返回:
+//   - map[string][]byte - 扩展属性名称到属性值的映射
+//   - error - 错误信息
+//
+// 注意:
+//   - 该函数在Windows环境下始终返回空映射。
+func ListXattrs(path string) (map[string][]byte, error) {
+	_ = path
+	return map[string][]byte{}, nil
+}
@@ -0,0 +1,80 @@
+// Package common 提供了文件大小格式化的工具函数。
+// 该文件将字节数按1024进制转换为可读的大小和单位, 供 list、size 和 dupes 等命令共用。
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 预定义单位和对应的阈值
+var (
+	sizeUnits = []string{"B", "KB", "MB", "GB", "TB", "PB"}
+	// 预计算阈值，避免重复计算
+	sizeThresholds = []float64{
+		1,                                // B
+		1024,                             // KB
+		1024 * 1024,                      // MB
+		1024 * 1024 * 1024,               // GB
+		1024 * 1024 * 1024 * 1024,        // TB
+		1024 * 1024 * 1024 * 1024 * 1024, // PB
+	}
+)
+
+// HumanSize 将字节数转换为可读的大小和单位
+//
+// 参数:
+//   - size: 字节数
+//   - decimals: 数值小于10时保留的小数位数(至少1位)
+//
+// 返回:
+//   - string: 可读的大小数值
+//   - string: 单位(B/KB/MB/GB/TB/PB)
+//
+// 注意:
+//   - 数值大于等于10或单位为B时不保留小数, 末尾的.0会被去除
+func HumanSize(size int64, decimals int) (string, string) {
+	// 处理0值情况 - 提前返回
+	if size == 0 {
+		return "0", sizeUnits[0]
+	}
+
+	sizeFloat := float64(size)
+
+	// 使用循环找到合适的单位，从大到小遍历
+	unitIndex := 0
+	for i := len(sizeThresholds) - 1; i > 0; i-- {
+		if sizeFloat >= sizeThresholds[i] {
+			unitIndex = i
+			sizeFloat /= sizeThresholds[i]
+			break
+		}
+	}
+
+	// 统一的格式化逻辑
+	var formatted string
+	if sizeFloat < 10 && unitIndex > 0 {
+		// 小于10且不是字节时，使用指定小数位数（至少1位）
+		formatted = fmt.Sprintf("%.*f", max(decimals, 1), sizeFloat)
+		// 移除末尾的.0
+		formatted = strings.TrimSuffix(formatted, ".0")
+	} else {
+		// 大于等于10或者是字节时，使用整数格式
+		formatted = fmt.Sprintf("%.0f", sizeFloat)
+	}
+
+	return formatted, sizeUnits[unitIndex]
+}
+
+// FormatSize 将字节数格式化为可读字符串
+//
+// 参数:
+//   - size: 字节数
+//   - decimals: 数值小于10时保留的小数位数(至少1位)
+//
+// 返回:
+//   - string: 格式化后的字符串, 如 "1.5 KB"
+func FormatSize(size int64, decimals int) string {
+	value, unit := HumanSize(size, decimals)
+	return value + " " + unit
+}
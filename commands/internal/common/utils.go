@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
+	"strings"
 
 	"gitee.com/MM-Q/colorlib"
 	"gitee.com/MM-Q/fck/commands/internal/types"
@@ -215,3 +217,43 @@ func SprintStringColor(p string, s string, cl *colorlib.ColorLib) string {
 		return cl.Swhite(s)
 	}
 }
+
+// ParseSize 解析带单位的字节数
+//
+// 参数:
+//   - s: 字节数字符串, 如 64M、512K、1.5G、1048576
+//
+// 返回:
+//   - int64: 字节数
+//   - error: 格式无效或不大于0时返回错误
+//
+// 注意:
+//   - 单位 K/M/G/T 按1024进制计算, 可带 B 或 iB 后缀, 不区分大小写
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	value = strings.TrimSuffix(value, "IB")
+	value = strings.TrimSuffix(value, "B")
+
+	multiplier := float64(1)
+	if value != "" {
+		switch value[len(value)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			value = value[:len(value)-1]
+		}
+	}
+
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || number <= 0 || int64(number*multiplier) <= 0 {
+		return 0, fmt.Errorf("无效的大小: %s", s)
+	}
+	return int64(number * multiplier), nil
+}
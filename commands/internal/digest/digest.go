@@ -0,0 +1,846 @@
+// Package digest 统一管理 fck 支持的哈希算法。
+// 该文件提供算法注册表、摘要长度查询以及文件/数据流的哈希计算函数，供 hash 和 check 子命令共同使用。
+package digest
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"gitee.com/MM-Q/fck/commands/internal/ratelimit"
+	"gitee.com/MM-Q/go-kit/pool"
+	"github.com/cespare/xxhash/v2"
+	"github.com/schollz/progressbar/v3"
+	"golang.org/x/crypto/blake2b"
+	"lukechampine.com/blake3"
+)
+
+// 哈希算法名称
+const (
+	MD5      = "md5"      // MD5
+	SHA1     = "sha1"     // SHA-1
+	SHA256   = "sha256"   // SHA-256
+	SHA512   = "sha512"   // SHA-512
+	SHA3_256 = "sha3-256" // SHA3-256
+	SHA3_512 = "sha3-512" // SHA3-512
+	BLAKE2b  = "blake2b"  // BLAKE2b-512
+	BLAKE3   = "blake3"   // BLAKE3 (256位输出)
+	XXHash64 = "xxhash64" // xxHash64 (非加密哈希, 适用于快速去重)
+	CRC32C   = "crc32c"   // CRC32C (Castagnoli 多项式, 适用于存储系统互通)
+)
+
+// algorithm 哈希算法定义
+type algorithm struct {
+	newFunc func() hash.Hash // 哈希函数构造器
+	size    int              // 摘要字节长度
+	tag     string           // BSD 标签格式中使用的算法标签
+}
+
+// crc32cTable CRC32C 查找表
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// algorithms 受支持的哈希算法注册表
+var algorithms = map[string]algorithm{
+	MD5:      {newFunc: md5.New, size: md5.Size, tag: "MD5"},
+	SHA1:     {newFunc: sha1.New, size: sha1.Size, tag: "SHA1"},
+	SHA256:   {newFunc: sha256.New, size: sha256.Size, tag: "SHA256"},
+	SHA512:   {newFunc: sha512.New, size: sha512.Size, tag: "SHA512"},
+	SHA3_256: {newFunc: func() hash.Hash { return sha3.New256() }, size: 32, tag: "SHA3-256"},
+	SHA3_512: {newFunc: func() hash.Hash { return sha3.New512() }, size: 64, tag: "SHA3-512"},
+	BLAKE2b:  {newFunc: newBlake2b, size: blake2b.Size, tag: "BLAKE2b"},
+	BLAKE3:   {newFunc: func() hash.Hash { return blake3.New(32, nil) }, size: 32, tag: "BLAKE3"},
+	XXHash64: {newFunc: func() hash.Hash { return xxhash.New() }, size: 8, tag: "XXH64"},
+	CRC32C:   {newFunc: func() hash.Hash { return crc32.New(crc32cTable) }, size: crc32.Size, tag: "CRC32C"},
+}
+
+// SupportedAlgorithms 受支持的哈希算法列表(按帮助信息中的展示顺序排列)
+var SupportedAlgorithms = []string{
+	MD5,
+	SHA1,
+	SHA256,
+	SHA512,
+	SHA3_256,
+	SHA3_512,
+	BLAKE2b,
+	BLAKE3,
+	XXHash64,
+	CRC32C,
+}
+
+// readLimiter 计算文件哈希时共享的读取限速器(为nil时不限速)
+var readLimiter *ratelimit.Limiter
+
+// SetReadLimiter 设置计算文件哈希时共享的读取限速器
+//
+// 参数:
+//   - l: 限速器, 为nil时取消限速
+//
+// 注意:
+//   - 仅作用于按路径读取的文件, HashReader 系列函数需要限速时使用 LimitReader 包装数据源
+func SetReadLimiter(l *ratelimit.Limiter) {
+	readLimiter = l
+}
+
+// LimitReader 使用共享的读取限速器包装读取器
+//
+// 参数:
+//   - r: 数据源读取器
+//
+// 返回:
+//   - io.Reader: 限速读取器(未设置限速器和读取上下文时直接返回 r)
+//
+// 注意:
+//   - 设置了读取上下文时, 上下文取消后读取立即返回错误
+func LimitReader(r io.Reader) io.Reader {
+	r = readLimiter.Reader(r)
+	if readContext != nil {
+		r = contextReader{ctx: readContext, r: r}
+	}
+	return r
+}
+
+// readContext 计算文件哈希时检查的上下文(为nil时不检查)
+var readContext context.Context
+
+// SetReadContext 设置计算文件哈希时检查的上下文
+//
+// 参数:
+//   - ctx: 上下文, 为nil时不再检查
+//
+// 注意:
+//   - 与 SetReadLimiter 相同, 仅作用于按路径读取的文件和 LimitReader 包装的读取器
+//   - 上下文取消(如收到中断信号)后正在计算的文件立即返回错误, 不必等待读取完成
+func SetReadContext(ctx context.Context) {
+	readContext = ctx
+}
+
+// contextReader 上下文取消后停止读取的读取器
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read 上下文未取消时读取数据
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// readCounter 计算文件哈希时累计读取字节数的计数器(为nil时不统计)
+var readCounter *atomic.Int64
+
+// SetReadCounter 设置计算文件哈希时累计读取字节数的计数器
+//
+// 参数:
+//   - c: 计数器, 为nil时取消统计
+//
+// 注意:
+//   - 与 SetReadLimiter 相同, 仅作用于按路径读取的文件
+func SetReadCounter(c *atomic.Int64) {
+	readCounter = c
+}
+
+// countWriter 将写入的字节数累加到计数器
+type countWriter struct {
+	n *atomic.Int64
+}
+
+// Write 累加写入的字节数
+func (w countWriter) Write(p []byte) (int, error) {
+	w.n.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// hmacKey 计算哈希时使用的 HMAC 密钥(为nil时计算普通哈希值)
+var hmacKey []byte
+
+// SetHMACKey 设置计算哈希时使用的 HMAC 密钥
+//
+// 参数:
+//   - key: 密钥, 为nil时恢复计算普通哈希值
+//
+// 注意:
+//   - 设置后 New 创建的哈希对象均为 HMAC(算法), 文件和数据流的哈希计算都会受影响
+func SetHMACKey(key []byte) {
+	hmacKey = key
+}
+
+// HMACEnabled 检查是否已设置 HMAC 密钥
+//
+// 返回:
+//   - bool: 已设置 HMAC 密钥时返回 true
+func HMACEnabled() bool {
+	return hmacKey != nil
+}
+
+// LoadHMACKey 读取 HMAC 密钥文件
+//
+// 参数:
+//   - path: 密钥文件路径
+//
+// 返回:
+//   - []byte: 密钥
+//   - error: 文件无法读取或为空时返回错误
+//
+// 注意:
+//   - 密钥为文件的完整内容(包括结尾换行符), 生成和校验时须使用同一文件
+func LoadHMACKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 HMAC 密钥文件失败: %w", err)
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("HMAC 密钥文件 %s 为空", path)
+	}
+	return key, nil
+}
+
+// IsHMACSupported 检查算法是否可以用于 HMAC
+//
+// 参数:
+//   - name: 哈希算法名称
+//
+// 返回:
+//   - bool: 加密哈希算法返回 true, xxHash64 和 CRC32C 等非加密算法返回 false
+func IsHMACSupported(name string) bool {
+	name = strings.ToLower(name)
+	return IsAlgorithmSupported(name) && name != XXHash64 && name != CRC32C
+}
+
+// newBlake2b 创建 BLAKE2b-512 哈希对象
+//
+// 返回:
+//   - hash.Hash: 哈希对象
+//
+// 注意:
+//   - 不使用密钥时 blake2b.New512 不会返回错误
+func newBlake2b() hash.Hash {
+	h, _ := blake2b.New512(nil)
+	return h
+}
+
+// IsAlgorithmSupported 检查给定的哈希算法名称是否受支持(忽略大小写)
+//
+// 参数:
+//   - name: 哈希算法名称
+//
+// 返回:
+//   - bool: 受支持返回 true, 否则返回 false
+func IsAlgorithmSupported(name string) bool {
+	_, ok := algorithms[strings.ToLower(name)]
+	return ok
+}
+
+// New 根据算法名称创建哈希对象(忽略大小写)
+//
+// 参数:
+//   - name: 哈希算法名称
+//
+// 返回:
+//   - hash.Hash: 哈希对象
+//   - error: 算法名称为空或不受支持时返回错误
+func New(name string) (hash.Hash, error) {
+	if name == "" {
+		return nil, fmt.Errorf("哈希算法名称不能为空")
+	}
+
+	algo, ok := algorithms[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("不支持的哈希算法: %s", name)
+	}
+
+	if hmacKey != nil {
+		if !IsHMACSupported(name) {
+			return nil, fmt.Errorf("HMAC 模式不支持非加密哈希算法: %s", name)
+		}
+		return hmac.New(algo.newFunc, hmacKey), nil
+	}
+
+	return algo.newFunc(), nil
+}
+
+// HexLen 获取算法的十六进制摘要长度
+//
+// 参数:
+//   - name: 哈希算法名称
+//
+// 返回:
+//   - int: 十六进制摘要字符数, 算法不受支持时返回0
+func HexLen(name string) int {
+	algo, ok := algorithms[strings.ToLower(name)]
+	if !ok {
+		return 0
+	}
+	return hex.EncodedLen(algo.size)
+}
+
+// HexLens 获取所有受支持算法的十六进制摘要长度集合
+//
+// 返回:
+//   - map[int]struct{}: 摘要长度集合
+func HexLens() map[int]struct{} {
+	lens := make(map[int]struct{}, len(algorithms))
+	for _, algo := range algorithms {
+		lens[hex.EncodedLen(algo.size)] = struct{}{}
+	}
+	return lens
+}
+
+// InferFromHexLen 根据十六进制摘要长度推断哈希算法
+//
+// 参数:
+//   - n: 十六进制摘要字符数
+//
+// 返回:
+//   - string: 推断出的算法名称, 无法推断时返回空字符串
+//
+// 注意:
+//   - 多个算法摘要长度相同时(如 sha256/sha3-256/blake3), 按 SupportedAlgorithms 的顺序返回第一个
+func InferFromHexLen(n int) string {
+	for _, name := range SupportedAlgorithms {
+		if HexLen(name) == n {
+			return name
+		}
+	}
+	return ""
+}
+
+// Tag 获取算法在 BSD 标签格式中使用的标签
+//
+// 参数:
+//   - name: 哈希算法名称
+//
+// 返回:
+//   - string: 算法标签(如 SHA256), 算法不受支持时返回空字符串
+func Tag(name string) string {
+	return algorithms[strings.ToLower(name)].tag
+}
+
+// FromTag 根据 BSD 标签查找算法名称(忽略大小写, 也接受算法名称本身)
+//
+// 参数:
+//   - tag: 算法标签
+//
+// 返回:
+//   - string: 算法名称
+//   - bool: 是否找到对应算法
+func FromTag(tag string) (string, bool) {
+	for _, name := range SupportedAlgorithms {
+		if strings.EqualFold(algorithms[name].tag, tag) || strings.EqualFold(name, tag) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// checksumCore 核心哈希计算逻辑, 支持一次读取同时计算多个算法以及可选的进度条显示
+//
+// 参数:
+//   - filePath: 文件路径
+//   - algorithms: 哈希算法名称列表
+//   - showProgress: 是否显示进度条
+//   - chunks: 分块哈希计算器(为nil时不计算分块哈希值)
+//
+// 返回:
+//   - []string: 文件的十六进制哈希值(与 algorithms 顺序一致)
+//   - error: 错误信息
+func checksumCore(filePath string, algorithms []string, showProgress bool, chunks *chunkHasher) ([]string, error) {
+	if len(algorithms) == 0 {
+		return nil, fmt.Errorf("哈希算法名称不能为空")
+	}
+
+	// 获取哈希对象, 每个算法一个写入器
+	hashes := make([]hash.Hash, len(algorithms))
+	writers := make([]io.Writer, len(algorithms), len(algorithms)+1)
+	for i, algorithm := range algorithms {
+		h, err := New(algorithm)
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = h
+		writers[i] = h
+	}
+
+	// 检查文件是否存在
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("文件不存在或无法访问: %v", err)
+	}
+
+	// 打开文件
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	// 根据文件大小动态分配缓冲区, 确保最小为1KB
+	fileSize := fileInfo.Size()
+	bufferSize := pool.CalculateBufferSize(fileSize)
+	if bufferSize < pool.KB {
+		bufferSize = pool.KB
+	}
+	buf := pool.GetByteCap(bufferSize)
+	defer pool.PutByte(buf)
+
+	// 如果需要显示进度条, 则创建进度条
+	if showProgress {
+		bar := progressbar.NewOptions64(
+			fileSize,                          // 进度条总长度
+			progressbar.OptionClearOnFinish(), // 结束时清除进度条
+			progressbar.OptionSetDescription(fmt.Sprintf("正在处理'%s'('%s')", filepath.Base(filePath), strings.ToUpper(strings.Join(algorithms, ",")))), // 显示描述
+			progressbar.OptionSetElapsedTime(true),             // 显示已用时间
+			progressbar.OptionSetPredictTime(true),             // 显示预计剩余时间
+			progressbar.OptionSetRenderBlankState(true),        // 在进度条完成之前显示空白状态
+			progressbar.OptionShowBytes(true),                  // 显示进度条传输的字节
+			progressbar.OptionShowCount(),                      // 显示当前进度的总和
+			progressbar.OptionSetTheme(progressbar.ThemeASCII), // ASCII 进度条主题
+			progressbar.OptionFullWidth(),                      // 设置进度条为终端最大宽度
+		)
+		defer func() {
+			_ = bar.Finish() // 完成进度条
+			_ = bar.Close()  // 关闭进度条
+		}()
+		writers = append(writers, bar)
+	}
+
+	// 统计读取的字节数
+	if readCounter != nil {
+		writers = append(writers, countWriter{n: readCounter})
+	}
+
+	// 分块哈希与整体哈希在同一次读取中计算
+	if chunks != nil {
+		writers = append(writers, chunks)
+	}
+
+	// 单个写入器时直接写入, 避免 MultiWriter 的额外开销
+	writer := writers[0]
+	if len(writers) > 1 {
+		writer = io.MultiWriter(writers...)
+	}
+
+	// 使用 io.CopyBuffer 进行高效复制并计算哈希
+	if _, err := io.CopyBuffer(writer, LimitReader(file), buf); err != nil {
+		return nil, fmt.Errorf("读取文件失败: %v", err)
+	}
+
+	sums := make([]string, len(hashes))
+	for i, h := range hashes {
+		sums[i] = hex.EncodeToString(h.Sum(nil))
+	}
+	return sums, nil
+}
+
+// Checksum 计算文件哈希值
+//
+// 参数:
+//   - filePath: 文件路径
+//   - algorithm: 哈希算法名称
+//
+// 返回:
+//   - string: 文件的十六进制哈希值
+//   - error: 错误信息
+func Checksum(filePath, algorithm string) (string, error) {
+	sums, err := checksumCore(filePath, []string{algorithm}, false, nil)
+	if err != nil {
+		return "", err
+	}
+	return sums[0], nil
+}
+
+// ChecksumProgress 计算文件哈希值(带进度条)
+//
+// 参数:
+//   - filePath: 文件路径
+//   - algorithm: 哈希算法名称
+//
+// 返回:
+//   - string: 文件的十六进制哈希值
+//   - error: 错误信息
+func ChecksumProgress(filePath, algorithm string) (string, error) {
+	sums, err := checksumCore(filePath, []string{algorithm}, true, nil)
+	if err != nil {
+		return "", err
+	}
+	return sums[0], nil
+}
+
+// ChecksumMulti 读取一次文件, 同时计算多个算法的哈希值
+//
+// 参数:
+//   - filePath: 文件路径
+//   - algorithms: 哈希算法名称列表
+//
+// 返回:
+//   - []string: 文件的十六进制哈希值(与 algorithms 顺序一致)
+//   - error: 错误信息
+func ChecksumMulti(filePath string, algorithms []string) ([]string, error) {
+	return checksumCore(filePath, algorithms, false, nil)
+}
+
+// ChecksumMultiProgress 读取一次文件, 同时计算多个算法的哈希值(带进度条)
+//
+// 参数:
+//   - filePath: 文件路径
+//   - algorithms: 哈希算法名称列表
+//
+// 返回:
+//   - []string: 文件的十六进制哈希值(与 algorithms 顺序一致)
+//   - error: 错误信息
+func ChecksumMultiProgress(filePath string, algorithms []string) ([]string, error) {
+	return checksumCore(filePath, algorithms, true, nil)
+}
+
+// ChecksumFiles 使用固定数量的协程并发计算多个文件的哈希值
+//
+// 参数:
+//   - files: 文件路径列表
+//   - workers: 并发协程数(小于1时使用逻辑处理器数量)
+//   - sum: 单个文件的哈希计算函数
+//
+// 返回:
+//   - []string: 各文件的哈希值(与 files 顺序一致, 计算失败的文件为空字符串)
+//   - []error: 各文件的错误(与 files 顺序一致, 计算成功的文件为nil)
+func ChecksumFiles(files []string, workers int, sum func(filePath string) (string, error)) ([]string, []error) {
+	hashes := make([]string, len(files))
+	errs := make([]error, len(files))
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(files)) {
+		wg.Go(func() {
+			for i := range jobs {
+				hashes[i], errs[i] = sum(files[i])
+			}
+		})
+	}
+
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return hashes, errs
+}
+
+// ChecksumChunks 读取一次文件, 同时计算多个算法的整体哈希值和分块哈希值
+//
+// 参数:
+//   - filePath: 文件路径
+//   - algorithms: 哈希算法名称列表
+//   - chunkSize: 分块大小(字节)
+//
+// 返回:
+//   - []string: 文件的十六进制哈希值(与 algorithms 顺序一致)
+//   - [][]string: 各算法按顺序排列的分块哈希值, 第 i 块覆盖字节 [i*chunkSize, (i+1)*chunkSize)
+//   - int64: 读取的字节数
+//   - error: 错误信息
+//
+// 注意:
+//   - 最后一块可能不足 chunkSize, 空文件没有分块
+func ChecksumChunks(filePath string, algorithms []string, chunkSize int64) ([]string, [][]string, int64, error) {
+	if chunkSize <= 0 {
+		return nil, nil, 0, fmt.Errorf("分块大小必须大于0: %d", chunkSize)
+	}
+
+	chunks, err := newChunkHasher(algorithms, chunkSize)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	sums, err := checksumCore(filePath, algorithms, false, chunks)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return sums, chunks.finish(), chunks.total, nil
+}
+
+// ChecksumChunksFrom 从指定分块开始逐块计算文件的分块哈希值
+//
+// 参数:
+//   - filePath: 文件路径
+//   - algorithm: 哈希算法名称
+//   - chunkSize: 分块大小(字节)
+//   - first: 起始分块序号, 跳过文件开头的 first*chunkSize 字节
+//   - visit: 每计算完一个分块调用一次, 参数为分块序号和十六进制哈希值
+//
+// 返回:
+//   - int64: 文件大小(跳过的字节数加读取的字节数)
+//   - error: 错误信息
+//
+// 注意:
+//   - 用于断点续校验, 不计算整体哈希值; 分块边界与 ChecksumChunks 一致
+func ChecksumChunksFrom(filePath, algorithm string, chunkSize int64, first int, visit func(index int, sum string)) (int64, error) {
+	if chunkSize <= 0 {
+		return 0, fmt.Errorf("分块大小必须大于0: %d", chunkSize)
+	}
+
+	h, err := New(algorithm)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("获取文件信息失败: %v", err)
+	}
+
+	// 跳过已校验的分块
+	offset := min(int64(first)*chunkSize, fileInfo.Size())
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("定位文件失败: %v", err)
+	}
+
+	var writer io.Writer = h
+	if readCounter != nil {
+		writer = io.MultiWriter(h, countWriter{n: readCounter})
+	}
+
+	bufferSize := max(pool.CalculateBufferSize(chunkSize), pool.KB)
+	buf := pool.GetByteCap(bufferSize)
+	defer pool.PutByte(buf)
+
+	reader := LimitReader(file)
+	for index := first; ; index++ {
+		h.Reset()
+		n, err := io.CopyBuffer(writer, io.LimitReader(reader, chunkSize), buf)
+		offset += n
+		if err != nil {
+			return offset, fmt.Errorf("读取文件失败: %v", err)
+		}
+		if n == 0 {
+			return offset, nil
+		}
+
+		visit(index, hex.EncodeToString(h.Sum(nil)))
+		if n < chunkSize {
+			return offset, nil
+		}
+	}
+}
+
+// chunkHasher 分块哈希计算器, 按固定大小切分写入的数据并逐块计算哈希值
+type chunkHasher struct {
+	hashes    []hash.Hash // 当前分块的哈希对象(每个算法一个)
+	chunkSize int64       // 分块大小
+	written   int64       // 当前分块已写入的字节数
+	total     int64       // 已写入的总字节数
+	sums      [][]string  // 已完成分块的哈希值(每个算法一组)
+}
+
+// newChunkHasher 创建分块哈希计算器
+//
+// 参数:
+//   - algorithms: 哈希算法名称列表
+//   - chunkSize: 分块大小
+//
+// 返回:
+//   - *chunkHasher: 分块哈希计算器
+//   - error: 算法不受支持时返回错误
+func newChunkHasher(algorithms []string, chunkSize int64) (*chunkHasher, error) {
+	c := &chunkHasher{
+		hashes:    make([]hash.Hash, len(algorithms)),
+		chunkSize: chunkSize,
+		sums:      make([][]string, len(algorithms)),
+	}
+	for i, algorithm := range algorithms {
+		h, err := New(algorithm)
+		if err != nil {
+			return nil, err
+		}
+		c.hashes[i] = h
+	}
+	return c, nil
+}
+
+// Write 写入数据, 跨越分块边界时结束当前分块
+func (c *chunkHasher) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		part := p[:min(int64(len(p)), c.chunkSize-c.written)]
+		for _, h := range c.hashes {
+			_, _ = h.Write(part)
+		}
+		c.written += int64(len(part))
+		c.total += int64(len(part))
+		p = p[len(part):]
+
+		if c.written == c.chunkSize {
+			c.flush()
+		}
+	}
+	return n, nil
+}
+
+// flush 结束当前分块并记录各算法的哈希值
+func (c *chunkHasher) flush() {
+	for i, h := range c.hashes {
+		c.sums[i] = append(c.sums[i], hex.EncodeToString(h.Sum(nil)))
+		h.Reset()
+	}
+	c.written = 0
+}
+
+// finish 结束最后一个不完整的分块并返回全部分块哈希值
+func (c *chunkHasher) finish() [][]string {
+	if c.written > 0 {
+		c.flush()
+	}
+	return c.sums
+}
+
+// ParseAlgorithms 解析以逗号分隔的算法列表
+//
+// 参数:
+//   - s: 算法列表字符串(如 md5,sha256)
+//
+// 返回:
+//   - []string: 去重后的小写算法名称列表(保持输入顺序)
+//   - error: 列表为空或包含不受支持的算法时返回错误
+func ParseAlgorithms(s string) ([]string, error) {
+	var algorithms []string
+	seen := make(map[string]struct{})
+
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !IsAlgorithmSupported(name) {
+			return nil, fmt.Errorf("不支持的哈希算法: %s", name)
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		algorithms = append(algorithms, name)
+	}
+
+	if len(algorithms) == 0 {
+		return nil, fmt.Errorf("哈希算法名称不能为空")
+	}
+
+	return algorithms, nil
+}
+
+// HashReader 计算 io.Reader 数据的哈希值
+//
+// 参数:
+//   - reader: 数据源读取器
+//   - algorithm: 哈希算法名称
+//
+// 返回:
+//   - string: 读取数据的十六进制哈希值
+//   - error: 错误信息
+//
+// 注意:
+//   - 会完全消费 Reader 中的数据
+func HashReader(reader io.Reader, algorithm string) (string, error) {
+	sums, _, err := HashReaderMulti(reader, []string{algorithm})
+	if err != nil {
+		return "", err
+	}
+	return sums[0], nil
+}
+
+// HashReaderMulti 流式读取 io.Reader 数据, 同时计算多个算法的哈希值
+//
+// 参数:
+//   - reader: 数据源读取器
+//   - algorithms: 哈希算法名称列表
+//
+// 返回:
+//   - []string: 读取数据的十六进制哈希值(与 algorithms 顺序一致)
+//   - int64: 读取的字节数
+//   - error: 错误信息
+//
+// 注意:
+//   - 会完全消费 Reader 中的数据, 使用固定大小的缓冲区, 不限制数据长度
+func HashReaderMulti(reader io.Reader, algorithms []string) ([]string, int64, error) {
+	if reader == nil {
+		return nil, 0, fmt.Errorf("reader 不能为空")
+	}
+	if len(algorithms) == 0 {
+		return nil, 0, fmt.Errorf("哈希算法名称不能为空")
+	}
+
+	hashes := make([]hash.Hash, len(algorithms))
+	writers := make([]io.Writer, len(algorithms))
+	for i, algorithm := range algorithms {
+		h, err := New(algorithm)
+		if err != nil {
+			return nil, 0, err
+		}
+		hashes[i] = h
+		writers[i] = h
+	}
+
+	writer := writers[0]
+	if len(writers) > 1 {
+		writer = io.MultiWriter(writers...)
+	}
+
+	buf := pool.GetByteCap(32 * pool.KB)
+	defer pool.PutByte(buf)
+
+	n, err := io.CopyBuffer(writer, reader, buf)
+	if err != nil {
+		return nil, n, fmt.Errorf("读取数据失败: %v", err)
+	}
+
+	sums := make([]string, len(hashes))
+	for i, h := range hashes {
+		sums[i] = hex.EncodeToString(h.Sum(nil))
+	}
+	return sums, n, nil
+}
+
+// ChecksumLink 以软链接目标文本作为内容计算多个算法的哈希值
+//
+// 参数:
+//   - linkPath: 软链接路径
+//   - algorithms: 哈希算法名称列表
+//
+// 返回:
+//   - []string: 链接目标的十六进制哈希值(与 algorithms 顺序一致)
+//   - error: 读取链接目标失败时返回错误
+//
+// 注意:
+//   - 不跟随软链接, 目标不存在的悬空链接同样可以计算
+func ChecksumLink(linkPath string, algorithms []string) ([]string, error) {
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取软链接目标失败: %w", err)
+	}
+
+	sums, _, err := HashReaderMulti(strings.NewReader(target), algorithms)
+	return sums, err
+}
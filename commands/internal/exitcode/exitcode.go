@@ -0,0 +1,112 @@
+// Package exitcode 定义了 fck 的进程退出码约定。
+// 该文件提供携带退出码的错误类型, 各子命令返回该类型的错误, 由 commands.Run 统一映射为进程退出码:
+//
+//	0   成功
+//	1   校验不一致或未找到匹配项
+//	2   部分文件处理失败或执行出错
+//	3   用法错误(参数无效或相互冲突)
+//	130 被中断(Ctrl+C)
+package exitcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 退出码
+const (
+	OK          = 0   // 成功
+	Failure     = 1   // 校验不一致或未找到匹配项
+	Partial     = 2   // 部分文件处理失败或执行出错(未携带退出码的错误同样按此处理)
+	Usage       = 3   // 用法错误
+	Interrupted = 130 // 被中断
+)
+
+// Error 携带退出码的错误
+type Error struct {
+	Code int   // 退出码
+	Err  error // 原始错误(为nil时表示结果已经输出, 不再输出错误信息)
+}
+
+// Error 返回错误信息
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return ""
+	}
+	return e.Err.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrInterrupted 操作被中断
+var ErrInterrupted = New(Interrupted, errors.New("操作已被中断"))
+
+// New 创建携带退出码的错误
+//
+// 参数:
+//   - code: 退出码
+//   - err: 原始错误(为nil时不输出错误信息)
+//
+// 返回:
+//   - error: 携带退出码的错误
+func New(code int, err error) error {
+	return &Error{Code: code, Err: err}
+}
+
+// Usagef 创建用法错误
+//
+// 参数:
+//   - format: 格式字符串
+//   - args: 格式参数
+//
+// 返回:
+//   - error: 退出码为 Usage 的错误
+func Usagef(format string, args ...any) error {
+	return New(Usage, fmt.Errorf(format, args...))
+}
+
+// Failuref 创建校验不一致或未找到匹配项的错误
+//
+// 参数:
+//   - format: 格式字符串
+//   - args: 格式参数
+//
+// 返回:
+//   - error: 退出码为 Failure 的错误
+func Failuref(format string, args ...any) error {
+	return New(Failure, fmt.Errorf(format, args...))
+}
+
+// Partialf 创建部分文件处理失败的错误
+//
+// 参数:
+//   - format: 格式字符串
+//   - args: 格式参数
+//
+// 返回:
+//   - error: 退出码为 Partial 的错误
+func Partialf(format string, args ...any) error {
+	return New(Partial, fmt.Errorf(format, args...))
+}
+
+// Code 获取错误对应的退出码
+//
+// 参数:
+//   - err: 子命令返回的错误
+//
+// 返回:
+//   - int: 为nil时返回 OK, 携带退出码时返回该退出码, 否则返回 Partial
+func Code(err error) int {
+	if err == nil {
+		return OK
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return Partial
+}
@@ -0,0 +1,392 @@
+// Package hashcache 实现了持久化的文件哈希缓存。
+// 该文件基于嵌入式键值数据库保存已计算的哈希值, 以设备号/inode/大小/修改时间/算法作为缓存键,
+// 文件未发生变化时直接返回缓存的哈希值, 避免重复读取大文件。
+package hashcache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"gitee.com/MM-Q/fck/commands/internal/common"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// 缓存文件名
+	cacheFileName = "hash-cache.db"
+
+	// 缓存数据桶名称
+	bucketName = "hashes"
+
+	// 打开缓存文件的超时时间(其他 fck 进程持有缓存锁时等待的最长时间)
+	openTimeout = time.Second
+)
+
+// 缓存模式
+const (
+	ModeOn      = "on"      // 读取并写入缓存
+	ModeOff     = "off"     // 不使用缓存
+	ModeRebuild = "rebuild" // 清空缓存后重新计算并写入
+)
+
+// 受支持的缓存模式
+var SupportedModes = []string{ModeOn, ModeOff, ModeRebuild}
+
+// Cache 文件哈希缓存
+type Cache struct {
+	db     *bolt.DB     // 嵌入式数据库
+	hits   atomic.Int64 // 缓存命中次数
+	misses atomic.Int64 // 缓存未命中次数
+}
+
+// DefaultPath 获取默认缓存文件路径(用户缓存目录下的 fck/hash-cache.db)
+//
+// 返回:
+//   - string: 缓存文件路径
+//   - error: 无法获取用户缓存目录时返回错误
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户缓存目录失败: %w", err)
+	}
+	return filepath.Join(dir, "fck", cacheFileName), nil
+}
+
+// Open 打开(或创建)缓存文件
+//
+// 参数:
+//   - path: 缓存文件路径
+//
+// 返回:
+//   - *Cache: 缓存对象
+//   - error: 错误信息
+func Open(path string) (*Cache, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建缓存目录失败: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("打开缓存文件 %s 失败: %w", path, err)
+	}
+
+	// 确保数据桶存在
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+		return err
+	}); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("初始化缓存失败: %w", err)
+	}
+
+	return &Cache{db: db}, nil
+}
+
+// OpenDefault 打开默认路径下的缓存文件
+//
+// 返回:
+//   - *Cache: 缓存对象
+//   - error: 错误信息
+func OpenDefault() (*Cache, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Open(path)
+}
+
+// Close 关闭缓存
+//
+// 返回:
+//   - error: 错误信息
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+// Get 查询文件的缓存哈希值
+//
+// 参数:
+//   - filePath: 文件路径
+//   - algorithm: 哈希算法
+//   - info: 文件信息(用于获取设备号、inode、大小和修改时间)
+//
+// 返回:
+//   - string: 缓存的哈希值
+//   - bool: 是否命中(文件大小或修改时间变化视为未命中)
+func (c *Cache) Get(filePath, algorithm string, info os.FileInfo) (string, bool) {
+	key := cacheKey(filePath, algorithm, info)
+
+	var hashValue string
+	_ = c.db.View(func(tx *bolt.Tx) error {
+		e, ok := decodeEntry(tx.Bucket([]byte(bucketName)).Get(key))
+		if ok && e.size == info.Size() && e.modTime == info.ModTime().UnixNano() {
+			hashValue = e.hash
+		}
+		return nil
+	})
+
+	if hashValue == "" {
+		c.misses.Add(1)
+		return "", false
+	}
+
+	c.hits.Add(1)
+	return hashValue, true
+}
+
+// Put 写入文件的哈希值
+//
+// 参数:
+//   - filePath: 文件路径
+//   - algorithm: 哈希算法
+//   - info: 计算哈希前获取的文件信息
+//   - hashValue: 哈希值
+//
+// 返回:
+//   - error: 错误信息
+//
+// 注意:
+//   - 使用批量事务合并多个协程的并发写入
+func (c *Cache) Put(filePath, algorithm string, info os.FileInfo, hashValue string) error {
+	key := cacheKey(filePath, algorithm, info)
+	value := encodeEntry(entry{
+		size:    info.Size(),
+		modTime: info.ModTime().UnixNano(),
+		hash:    hashValue,
+		path:    absPath(filePath),
+	})
+
+	return c.db.Batch(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(bucketName)).Put(key, value)
+	})
+}
+
+// ComputeFunc 哈希计算函数
+type ComputeFunc func(filePath, algorithm string) (string, error)
+
+// Checksum 获取文件哈希值, 文件未变化时直接返回缓存结果, 否则计算后写入缓存
+//
+// 参数:
+//   - filePath: 文件路径
+//   - algorithm: 哈希算法
+//   - compute: 未命中时使用的哈希计算函数
+//
+// 返回:
+//   - string: 文件的十六进制哈希值
+//   - error: 错误信息
+//
+// 注意:
+//   - c 为 nil 时直接调用 compute, 调用方无需判断是否启用缓存
+//   - 文件信息在计算前获取, 计算期间文件被修改时下次查询将不会命中
+//   - 缓存写入失败不影响计算结果
+func (c *Cache) Checksum(filePath, algorithm string, compute ComputeFunc) (string, error) {
+	if c == nil {
+		return compute(filePath, algorithm)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return compute(filePath, algorithm)
+	}
+
+	if hashValue, ok := c.Get(filePath, algorithm, info); ok {
+		return hashValue, nil
+	}
+
+	hashValue, err := compute(filePath, algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	_ = c.Put(filePath, algorithm, info, hashValue)
+
+	return hashValue, nil
+}
+
+// MultiComputeFunc 多算法哈希计算函数(一次读取计算多个算法)
+type MultiComputeFunc func(filePath string, algorithms []string) ([]string, error)
+
+// ChecksumMulti 获取文件多个算法的哈希值, 仅对未命中的算法重新计算
+//
+// 参数:
+//   - filePath: 文件路径
+//   - algorithms: 哈希算法列表
+//   - compute: 未命中时使用的多算法哈希计算函数
+//
+// 返回:
+//   - []string: 文件的十六进制哈希值(与 algorithms 顺序一致)
+//   - error: 错误信息
+//
+// 注意:
+//   - c 为 nil 时直接调用 compute
+//   - 命中统计按算法计数
+func (c *Cache) ChecksumMulti(filePath string, algorithms []string, compute MultiComputeFunc) ([]string, error) {
+	if c == nil {
+		return compute(filePath, algorithms)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return compute(filePath, algorithms)
+	}
+
+	// 查询各算法的缓存
+	sums := make([]string, len(algorithms))
+	var missing []int
+	for i, algorithm := range algorithms {
+		if hashValue, ok := c.Get(filePath, algorithm, info); ok {
+			sums[i] = hashValue
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return sums, nil
+	}
+
+	// 一次读取计算所有未命中的算法
+	missingAlgorithms := make([]string, len(missing))
+	for j, i := range missing {
+		missingAlgorithms[j] = algorithms[i]
+	}
+
+	computed, err := compute(filePath, missingAlgorithms)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range missing {
+		sums[i] = computed[j]
+		_ = c.Put(filePath, algorithms[i], info, computed[j])
+	}
+
+	return sums, nil
+}
+
+// Clear 清空所有缓存条目
+//
+// 返回:
+//   - error: 错误信息
+func (c *Cache) Clear() error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket([]byte(bucketName)); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket([]byte(bucketName))
+		return err
+	})
+}
+
+// Prune 清理失效的缓存条目(文件已删除、已被替换或内容已变化)
+//
+// 返回:
+//   - int: 删除的条目数
+//   - error: 错误信息
+func (c *Cache) Prune() (int, error) {
+	var removed int
+
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(bucketName)).Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			if c.isEntryValid(k, v) {
+				continue
+			}
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+
+	return removed, err
+}
+
+// isEntryValid 检查缓存条目对应的文件是否仍然存在且未发生变化
+func (c *Cache) isEntryValid(key, value []byte) bool {
+	e, ok := decodeEntry(value)
+	if !ok {
+		return false
+	}
+
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return false
+	}
+
+	// 文件身份(设备号/inode)和元数据均需一致
+	algorithm, _, _ := bytes.Cut(key, []byte{0})
+	return bytes.Equal(key, cacheKey(e.path, string(algorithm), info)) &&
+		e.size == info.Size() && e.modTime == info.ModTime().UnixNano()
+}
+
+// Stats 获取缓存命中统计
+//
+// 返回:
+//   - hits: 命中次数
+//   - misses: 未命中次数
+func (c *Cache) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+// entry 缓存条目
+type entry struct {
+	size    int64  // 文件大小
+	modTime int64  // 修改时间(纳秒)
+	hash    string // 哈希值
+	path    string // 文件绝对路径(用于清理失效条目)
+}
+
+// cacheKey 生成缓存键: 算法\x00设备号+inode, 无法获取 inode 时退化为 算法\x00绝对路径
+func cacheKey(filePath, algorithm string, info os.FileInfo) []byte {
+	key := append([]byte(algorithm), 0)
+
+	if dev, ino, ok := common.GetFileID(info); ok {
+		key = binary.BigEndian.AppendUint64(key, dev)
+		return binary.BigEndian.AppendUint64(key, ino)
+	}
+
+	return append(key, absPath(filePath)...)
+}
+
+// encodeEntry 编码缓存条目: size(8) + modTime(8) + hashLen(2) + hash + path
+func encodeEntry(e entry) []byte {
+	buf := make([]byte, 0, 18+len(e.hash)+len(e.path))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(e.size))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(e.modTime))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(e.hash)))
+	buf = append(buf, e.hash...)
+	return append(buf, e.path...)
+}
+
+// decodeEntry 解码缓存条目
+func decodeEntry(data []byte) (entry, bool) {
+	if len(data) < 18 {
+		return entry{}, false
+	}
+
+	hashLen := int(binary.BigEndian.Uint16(data[16:18]))
+	if len(data) < 18+hashLen {
+		return entry{}, false
+	}
+
+	return entry{
+		size:    int64(binary.BigEndian.Uint64(data[0:8])),
+		modTime: int64(binary.BigEndian.Uint64(data[8:16])),
+		hash:    string(data[18 : 18+hashLen]),
+		path:    string(data[18+hashLen:]),
+	}, true
+}
+
+// absPath 获取绝对路径, 失败时返回清理后的原路径
+func absPath(filePath string) string {
+	if abs, err := filepath.Abs(filePath); err == nil {
+		return abs
+	}
+	return filepath.Clean(filePath)
+}
@@ -0,0 +1,426 @@
+// Package merkle 实现了目录的 Merkle 树摘要计算。
+// 该文件根据目录下文件的相对路径和哈希值自底向上计算每个目录的子树摘要,
+// 整个目录最终得到一个根摘要, 供 hash --tree 生成和 check 校验使用。
+package merkle
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gitee.com/MM-Q/fck/commands/internal/common"
+	"gitee.com/MM-Q/fck/commands/internal/digest"
+	"gitee.com/MM-Q/fck/commands/internal/signature"
+	"gitee.com/MM-Q/fck/commands/internal/types"
+)
+
+// 树选项名称(写入校验文件头, 多个选项以逗号分隔)
+const (
+	OptionMode     = "mode"     // 包含文件权限位
+	OptionSymlinks = "symlinks" // 包含软链接及其目标
+	OptionHidden   = "hidden"   // 包含隐藏文件/目录
+)
+
+// 子节点类型
+const (
+	kindFile    = 'f' // 普通文件
+	kindDir     = 'd' // 目录
+	kindSymlink = 'l' // 软链接
+)
+
+// RootPath 根目录在树中的相对路径
+const RootPath = "."
+
+// Options Merkle 树计算选项
+type Options struct {
+	Algorithm string   // 哈希算法(文件和目录节点使用同一算法)
+	Mode      bool     // 是否包含文件权限位
+	Symlinks  bool     // 是否包含软链接(以链接目标作为内容), 否则跳过软链接
+	Hidden    bool     // 是否包含隐藏文件/目录(仅影响 Collect)
+	Workers   int      // 并发计算文件摘要的协程数(0表示逻辑处理器数量, 不写入校验文件头)
+	Exclude   []string // 不参与计算的文件路径(校验文件及其签名文件, 不写入校验文件头)
+}
+
+// String 生成选项字符串(用于写入校验文件头)
+//
+// 返回:
+//   - string: 以逗号分隔的选项名称, 未启用任何选项时返回空字符串
+func (o Options) String() string {
+	var opts []string
+	if o.Mode {
+		opts = append(opts, OptionMode)
+	}
+	if o.Symlinks {
+		opts = append(opts, OptionSymlinks)
+	}
+	if o.Hidden {
+		opts = append(opts, OptionHidden)
+	}
+	return strings.Join(opts, ",")
+}
+
+// ParseOptions 解析选项字符串
+//
+// 参数:
+//   - algorithm: 哈希算法
+//   - s: 以逗号分隔的选项名称
+//
+// 返回:
+//   - Options: 树计算选项
+//   - error: 包含未知选项时返回错误
+func ParseOptions(algorithm, s string) (Options, error) {
+	opts := Options{Algorithm: algorithm}
+
+	for _, name := range strings.Split(s, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case OptionMode:
+			opts.Mode = true
+		case OptionSymlinks:
+			opts.Symlinks = true
+		case OptionHidden:
+			opts.Hidden = true
+		default:
+			return Options{}, fmt.Errorf("未知的树选项: %s", name)
+		}
+	}
+
+	return opts, nil
+}
+
+// ChecksumFunc 文件哈希计算函数
+type ChecksumFunc func(filePath, algorithm string) (string, error)
+
+// Tree Merkle 树计算结果
+type Tree struct {
+	dirs map[string]string // 目录相对路径(以/分隔, 根目录为".") -> 子树摘要
+}
+
+// Root 获取根摘要
+//
+// 返回:
+//   - string: 根目录的十六进制摘要
+func (t *Tree) Root() string {
+	return t.dirs[RootPath]
+}
+
+// Digest 获取指定目录的子树摘要
+//
+// 参数:
+//   - rel: 目录相对路径(以/分隔, 根目录为".")
+//
+// 返回:
+//   - string: 子树摘要
+//   - bool: 目录是否存在于树中
+func (t *Tree) Digest(rel string) (string, bool) {
+	d, ok := t.dirs[rel]
+	return d, ok
+}
+
+// Dirs 获取不超过指定深度的目录列表
+//
+// 参数:
+//   - depth: 最大深度(0 表示仅根目录, 1 表示根目录及其直接子目录, 以此类推)
+//
+// 返回:
+//   - []string: 按路径排序的目录相对路径列表, 根目录为"."且排在最前
+func (t *Tree) Dirs(depth int) []string {
+	var dirs []string
+	for rel := range t.dirs {
+		if dirDepth(rel) <= depth {
+			dirs = append(dirs, rel)
+		}
+	}
+
+	sort.Slice(dirs, func(i, j int) bool {
+		if dirs[i] == RootPath || dirs[j] == RootPath {
+			return dirs[i] == RootPath && dirs[j] != RootPath
+		}
+		return dirs[i] < dirs[j]
+	})
+
+	return dirs
+}
+
+// dirDepth 计算目录相对路径的深度
+func dirDepth(rel string) int {
+	if rel == RootPath {
+		return 0
+	}
+	return strings.Count(rel, "/") + 1
+}
+
+// node 目录节点
+type node struct {
+	children map[string]child // 子节点名称 -> 子节点
+}
+
+// child 子节点
+type child struct {
+	kind   byte   // 节点类型
+	mode   uint32 // 权限位(未启用 Mode 选项时为0)
+	digest []byte // 摘要(目录节点在计算子树时填充)
+}
+
+// leaf 文件叶子节点的计算任务
+type leaf struct {
+	rel  string // 相对路径
+	path string // 实际路径
+}
+
+// Build 计算目录的 Merkle 树
+//
+// 参数:
+//   - root: 根目录路径
+//   - files: 根目录下的文件路径列表(由调用方收集, 目录本身不需要包含在内)
+//   - opts: 树计算选项
+//   - sum: 文件哈希计算函数, 为 nil 时使用 digest.Checksum
+//
+// 返回:
+//   - *Tree: Merkle 树
+//   - error: 任意文件计算失败时返回错误
+//
+// 注意:
+//   - 子树摘要 = H(按名称排序的子节点序列), 每个子节点序列化为 "类型 权限位 名称\x00" 加上子节点的原始摘要
+//   - 软链接的摘要为链接目标字符串的哈希值
+//   - 只有包含文件的目录会出现在树中, 空目录不参与计算
+//   - 根目录下的 checksum.hash 及其签名文件, 以及 opts.Exclude 中的文件不参与计算
+func Build(root string, files []string, opts Options, sum ChecksumFunc) (*Tree, error) {
+	if !digest.IsAlgorithmSupported(opts.Algorithm) {
+		return nil, fmt.Errorf("不支持的哈希算法: %s", opts.Algorithm)
+	}
+	if sum == nil {
+		sum = digest.Checksum
+	}
+
+	excluded := make(map[string]struct{}, len(opts.Exclude))
+	for _, p := range opts.Exclude {
+		excluded[absPath(p)] = struct{}{}
+	}
+
+	nodes := map[string]*node{RootPath: {children: make(map[string]child)}}
+	var leaves []leaf
+
+	for _, file := range files {
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return nil, fmt.Errorf("无法计算相对路径 %s: %w", file, err)
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == RootPath || strings.HasPrefix(rel, "../") {
+			return nil, fmt.Errorf("文件 %s 不在目录 %s 下", file, root)
+		}
+
+		// 根目录下的校验文件本身不参与计算, 否则写入校验文件后根摘要会发生变化
+		if rel == types.OutputFileName || rel == signature.SignatureFileName(types.OutputFileName) {
+			continue
+		}
+		if _, ok := excluded[absPath(file)]; ok {
+			continue
+		}
+
+		info, err := os.Lstat(file)
+		if err != nil {
+			return nil, fmt.Errorf("获取文件信息失败: %w", err)
+		}
+
+		c := child{kind: kindFile}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if !opts.Symlinks {
+				continue
+			}
+			c.kind = kindSymlink
+		} else if opts.Mode {
+			c.mode = uint32(info.Mode().Perm())
+		}
+
+		addChild(nodes, rel, c)
+		if c.kind == kindFile {
+			leaves = append(leaves, leaf{rel: rel, path: file})
+		} else if err := setSymlinkDigest(nodes, rel, file, opts.Algorithm); err != nil {
+			return nil, err
+		}
+	}
+
+	// 并发计算文件摘要
+	if err := computeLeaves(nodes, leaves, opts, sum); err != nil {
+		return nil, err
+	}
+
+	// 自底向上计算目录摘要
+	tree := &Tree{dirs: make(map[string]string, len(nodes))}
+	if _, err := computeDir(nodes, RootPath, opts.Algorithm, tree); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// absPath 获取规范化的绝对路径, 失败时返回清理后的原路径
+func absPath(p string) string {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return filepath.Clean(p)
+	}
+	return abs
+}
+
+// addChild 将子节点加入所在目录, 并按需创建各级父目录节点
+func addChild(nodes map[string]*node, rel string, c child) {
+	dir, name := path.Split(rel)
+	dir = strings.TrimSuffix(dir, "/")
+	if dir == "" {
+		dir = RootPath
+	}
+
+	n, ok := nodes[dir]
+	if !ok {
+		n = &node{children: make(map[string]child)}
+		nodes[dir] = n
+		addChild(nodes, dir, child{kind: kindDir})
+	}
+	n.children[name] = c
+}
+
+// setChildDigest 设置子节点摘要
+func setChildDigest(nodes map[string]*node, rel string, d []byte) {
+	dir, name := path.Split(rel)
+	dir = strings.TrimSuffix(dir, "/")
+	if dir == "" {
+		dir = RootPath
+	}
+
+	n := nodes[dir]
+	c := n.children[name]
+	c.digest = d
+	n.children[name] = c
+}
+
+// setSymlinkDigest 以软链接目标计算软链接节点摘要
+func setSymlinkDigest(nodes map[string]*node, rel, file, algorithm string) error {
+	target, err := os.Readlink(file)
+	if err != nil {
+		return fmt.Errorf("读取软链接 %s 失败: %w", file, err)
+	}
+
+	h, err := digest.New(algorithm)
+	if err != nil {
+		return err
+	}
+	_, _ = h.Write([]byte(filepath.ToSlash(target)))
+	setChildDigest(nodes, rel, h.Sum(nil))
+	return nil
+}
+
+// computeLeaves 并发计算文件叶子节点摘要
+func computeLeaves(nodes map[string]*node, leaves []leaf, opts Options, sum ChecksumFunc) error {
+	paths := make([]string, len(leaves))
+	for i, l := range leaves {
+		paths[i] = l.path
+	}
+
+	hexDigests, errs := digest.ChecksumFiles(paths, opts.Workers, func(filePath string) (string, error) {
+		return sum(filePath, opts.Algorithm)
+	})
+
+	// 按文件顺序返回第一个错误
+	for i, l := range leaves {
+		if errs[i] != nil {
+			return fmt.Errorf("计算文件 %s 哈希失败: %w", l.path, errs[i])
+		}
+		d, err := hex.DecodeString(hexDigests[i])
+		if err != nil {
+			return err
+		}
+		setChildDigest(nodes, l.rel, d)
+	}
+
+	return nil
+}
+
+// computeDir 递归计算目录子树摘要
+func computeDir(nodes map[string]*node, rel, algorithm string, tree *Tree) ([]byte, error) {
+	n := nodes[rel]
+
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h, err := digest.New(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		c := n.children[name]
+		if c.kind == kindDir {
+			childRel := name
+			if rel != RootPath {
+				childRel = rel + "/" + name
+			}
+			if c.digest, err = computeDir(nodes, childRel, algorithm, tree); err != nil {
+				return nil, err
+			}
+		}
+
+		_, _ = fmt.Fprintf(h, "%c %04o %s\x00", c.kind, c.mode, name)
+		_, _ = h.Write(c.digest)
+	}
+
+	d := h.Sum(nil)
+	tree.dirs[rel] = hex.EncodeToString(d)
+	return d, nil
+}
+
+// Collect 递归收集目录下的文件(不跟随软链接)
+//
+// 参数:
+//   - root: 根目录路径
+//   - hidden: 是否包含隐藏文件/目录
+//
+// 返回:
+//   - []string: 文件路径列表(包含软链接)
+//   - error: 错误信息
+//
+// 注意:
+//   - 与 hash 子命令递归收集文件的规则一致, 保证校验时得到相同的文件集合
+func Collect(root string, hidden bool) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s 不是目录", root)
+	}
+
+	var files []string
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !hidden && p != root && common.IsHidden(p) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !d.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("遍历目录失败: %w", err)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,216 @@
+// Package metadata 实现了文件元数据的采集与比较。
+// 该文件负责按指定字段采集文件的权限位、所有者、软链接目标和扩展属性, 并提供编码、解析和逐字段比较功能,
+// 用于 hash --with-metadata 记录元数据以及 check 报告具体发生变化的属性。
+package metadata
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gitee.com/MM-Q/fck/commands/internal/common"
+)
+
+// 元数据字段
+const (
+	FieldMode  = "mode"  // 权限位(包括 setuid/setgid/sticky)
+	FieldOwner = "owner" // 所有者的 uid 和 gid
+	FieldLink  = "link"  // 软链接目标
+	FieldXattr = "xattr" // 扩展属性
+)
+
+// DefaultFields --with-metadata 默认记录的字段
+var DefaultFields = []string{FieldMode, FieldOwner, FieldLink}
+
+// SupportedFields 支持的元数据字段
+var SupportedFields = []string{FieldMode, FieldOwner, FieldLink, FieldXattr}
+
+// 属性键
+const (
+	keyMode        = "mode"   // 权限位(八进制)
+	keyUID         = "uid"    // 用户ID
+	keyGID         = "gid"    // 组ID
+	keyLink        = "link"   // 软链接目标
+	keyXattrPrefix = "xattr." // 扩展属性键前缀, 后接属性名
+)
+
+// Attrs 文件元数据, 键为属性名, 值为属性值
+//
+// 注意:
+//   - 未记录的属性不出现在映射中(如普通文件没有 link 属性)
+type Attrs map[string]string
+
+// ParseFields 解析以逗号分隔的字段列表
+//
+// 参数:
+//   - s: 字段列表字符串
+//
+// 返回:
+//   - []string: 按 SupportedFields 顺序排列且去重的字段列表
+//   - error: 包含不支持的字段时返回错误
+func ParseFields(s string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !slices.Contains(SupportedFields, field) {
+			return nil, fmt.Errorf("不支持的元数据字段: %s, 支持的字段: %s", field, strings.Join(SupportedFields, ", "))
+		}
+		fields = append(fields, field)
+	}
+
+	// 按固定顺序排列, 保证记录的字段列表一致
+	var sorted []string
+	for _, field := range SupportedFields {
+		if slices.Contains(fields, field) {
+			sorted = append(sorted, field)
+		}
+	}
+	return sorted, nil
+}
+
+// Collect 采集文件的元数据(不跟随软链接)
+//
+// 参数:
+//   - path: 文件路径
+//   - fields: 需要采集的字段列表
+//
+// 返回:
+//   - Attrs: 文件元数据
+//   - error: 获取文件信息失败时返回错误
+//
+// 注意:
+//   - 当前平台不支持的属性(如 Windows 下的 uid/gid)不会被采集
+func Collect(path string, fields []string) (Attrs, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make(Attrs)
+	for _, field := range fields {
+		switch field {
+		case FieldMode:
+			attrs[keyMode] = formatMode(info.Mode())
+		case FieldOwner:
+			if uid, gid, ok := common.GetFileOwnerID(info); ok {
+				attrs[keyUID] = strconv.FormatUint(uint64(uid), 10)
+				attrs[keyGID] = strconv.FormatUint(uint64(gid), 10)
+			}
+		case FieldLink:
+			if info.Mode()&fs.ModeSymlink != 0 {
+				target, err := os.Readlink(path)
+				if err != nil {
+					return nil, fmt.Errorf("读取软链接目标失败: %w", err)
+				}
+				attrs[keyLink] = target
+			}
+		case FieldXattr:
+			xattrs, err := common.ListXattrs(path)
+			if err != nil {
+				return nil, fmt.Errorf("读取扩展属性失败: %w", err)
+			}
+			for name, value := range xattrs {
+				attrs[keyXattrPrefix+name] = string(value)
+			}
+		}
+	}
+	return attrs, nil
+}
+
+// formatMode 将文件权限位格式化为四位八进制数
+func formatMode(mode fs.FileMode) string {
+	perm := uint32(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		perm |= 0o4000
+	}
+	if mode&fs.ModeSetgid != 0 {
+		perm |= 0o2000
+	}
+	if mode&fs.ModeSticky != 0 {
+		perm |= 0o1000
+	}
+	return fmt.Sprintf("%04o", perm)
+}
+
+// Encode 将元数据编码为单行文本
+//
+// 返回:
+//   - string: 按属性名排序的 key=value 列表(以 & 分隔, 键和值经过 URL 转义)
+func (a Attrs) Encode() string {
+	values := make(url.Values, len(a))
+	for key, value := range a {
+		values.Set(key, value)
+	}
+	return values.Encode()
+}
+
+// Parse 解析 Encode 生成的元数据文本
+//
+// 参数:
+//   - s: 元数据文本
+//
+// 返回:
+//   - Attrs: 文件元数据
+//   - error: 格式无效时返回错误
+func Parse(s string) (Attrs, error) {
+	values, err := url.ParseQuery(s)
+	if err != nil {
+		return nil, fmt.Errorf("无效的元数据记录: %w", err)
+	}
+
+	attrs := make(Attrs, len(values))
+	for key, value := range values {
+		attrs[key] = value[len(value)-1]
+	}
+	return attrs, nil
+}
+
+// Diff 逐个属性比较元数据
+//
+// 参数:
+//   - expected: 记录的元数据
+//   - actual: 当前的元数据
+//
+// 返回:
+//   - []string: 按属性名排序的差异描述(如 "mode: 0644 -> 0755"), 完全一致时为nil
+func Diff(expected, actual Attrs) []string {
+	keys := make([]string, 0, len(expected)+len(actual))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	for key := range actual {
+		if _, ok := expected[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	var diffs []string
+	for _, key := range keys {
+		want, wantOK := expected[key]
+		got, gotOK := actual[key]
+		if wantOK == gotOK && want == got {
+			continue
+		}
+		diffs = append(diffs, fmt.Sprintf("%s: %s -> %s", key, describe(key, want, wantOK), describe(key, got, gotOK)))
+	}
+	return diffs
+}
+
+// describe 生成差异描述中的属性值, 扩展属性值可能为二进制数据, 按转义后的形式显示
+func describe(key, value string, ok bool) string {
+	if !ok {
+		return "(无)"
+	}
+	if strings.HasPrefix(key, keyXattrPrefix) {
+		return strconv.Quote(value)
+	}
+	return value
+}
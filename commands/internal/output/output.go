@@ -0,0 +1,170 @@
+// Package output 实现了面向脚本和流水线的结构化结果输出。
+// 该文件提供 JSON、NDJSON 和 CSV 三种格式的流式记录写入器, 供 hash 和 check 等命令输出逐文件记录及汇总信息。
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// 输出格式
+const (
+	FormatText   = "text"   // 人类可读的文本格式(默认)
+	FormatJSON   = "json"   // 单个 JSON 对象: {"files":[...],"summary":{...}}
+	FormatNDJSON = "ndjson" // 每行一个 JSON 记录, 最后一行为 {"summary":{...}}
+	FormatCSV    = "csv"    // 带表头的 CSV, 不包含汇总信息
+)
+
+// 受支持的输出格式
+var SupportedFormats = []string{FormatText, FormatJSON, FormatNDJSON, FormatCSV}
+
+// Record 结构化输出记录
+//
+// 注意:
+//   - JSON/NDJSON 格式使用结构体的 json 标签序列化
+//   - CSV 格式使用 Values 返回的字段值, 顺序与写入器的表头一致
+type Record interface {
+	Values() []string
+}
+
+// Writer 结构化记录写入器
+type Writer struct {
+	w       io.Writer   // 输出目标
+	format  string      // 输出格式
+	columns []string    // CSV 表头
+	csv     *csv.Writer // CSV 写入器
+	count   int         // 已写入的记录数
+	started bool        // 是否已写入开头(JSON 的对象开头或 CSV 的表头)
+}
+
+// NewWriter 创建结构化记录写入器
+//
+// 参数:
+//   - w: 输出目标
+//   - format: 输出格式(json/ndjson/csv)
+//   - columns: CSV 表头
+//
+// 返回:
+//   - *Writer: 记录写入器
+//   - error: 格式不受支持时返回错误
+func NewWriter(w io.Writer, format string, columns []string) (*Writer, error) {
+	switch format {
+	case FormatJSON, FormatNDJSON:
+		return &Writer{w: w, format: format, columns: columns}, nil
+	case FormatCSV:
+		return &Writer{w: w, format: format, columns: columns, csv: csv.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("不支持的结构化输出格式: %s", format)
+	}
+}
+
+// IsStructured 判断输出格式是否为结构化格式
+//
+// 参数:
+//   - format: 输出格式
+//
+// 返回:
+//   - bool: json/ndjson/csv 返回 true, text 返回 false
+func IsStructured(format string) bool {
+	return format == FormatJSON || format == FormatNDJSON || format == FormatCSV
+}
+
+// Write 写入一条记录
+//
+// 参数:
+//   - r: 记录
+//
+// 返回:
+//   - error: 错误信息
+func (w *Writer) Write(r Record) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	switch w.format {
+	case FormatCSV:
+		if err := w.csv.Write(r.Values()); err != nil {
+			return err
+		}
+		w.csv.Flush()
+		return w.csv.Error()
+
+	case FormatNDJSON:
+		return w.writeJSON("", r, "\n")
+
+	default:
+		sep := ",\n"
+		if w.count == 0 {
+			sep = ""
+		}
+		w.count++
+		return w.writeJSON(sep+"  ", r, "")
+	}
+}
+
+// Close 写入汇总信息并结束输出
+//
+// 参数:
+//   - summary: 汇总信息(为 nil 时不输出; CSV 格式忽略汇总信息)
+//
+// 返回:
+//   - error: 错误信息
+func (w *Writer) Close(summary any) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	switch w.format {
+	case FormatCSV:
+		w.csv.Flush()
+		return w.csv.Error()
+
+	case FormatNDJSON:
+		if summary == nil {
+			return nil
+		}
+		return w.writeJSON("", map[string]any{"summary": summary}, "\n")
+
+	default:
+		if w.count > 0 {
+			if _, err := io.WriteString(w.w, "\n"); err != nil {
+				return err
+			}
+		}
+		if summary == nil {
+			_, err := io.WriteString(w.w, "]}\n")
+			return err
+		}
+		return w.writeJSON("],\"summary\":", summary, "}\n")
+	}
+}
+
+// start 写入输出开头(仅执行一次)
+func (w *Writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+
+	switch w.format {
+	case FormatCSV:
+		return w.csv.Write(w.columns)
+	case FormatJSON:
+		_, err := io.WriteString(w.w, "{\"files\":[\n")
+		return err
+	}
+	return nil
+}
+
+// writeJSON 序列化并写入 JSON 值
+func (w *Writer) writeJSON(prefix string, v any, suffix string) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("序列化记录失败: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w.w, "%s%s%s", prefix, data, suffix)
+	return err
+}
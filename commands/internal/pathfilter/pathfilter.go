@@ -0,0 +1,286 @@
+// Package pathfilter 实现了文件收集时的路径过滤。
+// 该文件支持 --include/--exclude 通配符(含 ** 跨目录匹配)以及 .gitignore 风格的忽略文件,
+// 被排除的目录可在遍历时整体跳过。
+package pathfilter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// rule 单条匹配规则
+type rule struct {
+	segments []string // 按 / 分割的模式
+	anchored bool     // 含 / 时相对基准目录匹配完整路径, 否则匹配任意层级的名称
+	dirOnly  bool     // 以 / 结尾时仅匹配目录
+	negate   bool     // 以 ! 开头时重新包含已排除的路径(仅忽略文件)
+}
+
+// ruleSet 共享同一基准目录的一组规则
+type ruleSet struct {
+	base  string // 基准目录(绝对路径)
+	rules []rule
+}
+
+// Filter 路径过滤器
+//
+// 注意:
+//   - nil 过滤器不排除任何路径
+//   - --exclude 和忽略文件中任一规则集排除的路径即被排除, 同一规则集内后出现的规则优先
+//   - --include 仅作用于文件, 指定后只保留匹配的文件, 目录仍会被遍历
+type Filter struct {
+	cwd      string     // 创建过滤器时的工作目录, 用于转换相对路径
+	include  *ruleSet   // --include 规则
+	excludes []*ruleSet // --exclude 规则和忽略文件规则
+}
+
+// New 创建路径过滤器
+//
+// 参数:
+//   - includes: 包含模式列表
+//   - excludes: 排除模式列表
+//
+// 返回:
+//   - *Filter: 路径过滤器
+//   - error: 错误信息
+//
+// 注意:
+//   - 含 / 的模式相对当前工作目录匹配完整路径, 否则匹配任意层级的文件或目录名
+func New(includes, excludes []string) (*Filter, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("获取当前工作目录失败: %w", err)
+	}
+
+	f := &Filter{cwd: cwd}
+
+	if len(includes) > 0 {
+		set, err := newRuleSet(cwd, includes, false)
+		if err != nil {
+			return nil, err
+		}
+		f.include = set
+	}
+
+	if len(excludes) > 0 {
+		set, err := newRuleSet(cwd, excludes, false)
+		if err != nil {
+			return nil, err
+		}
+		f.excludes = append(f.excludes, set)
+	}
+
+	return f, nil
+}
+
+// AddIgnoreFile 加载 .gitignore 风格的忽略文件
+//
+// 参数:
+//   - ignorePath: 忽略文件路径
+//
+// 返回:
+//   - error: 错误信息
+//
+// 注意:
+//   - 规则相对忽略文件所在目录匹配
+//   - 支持 # 注释、! 取反、结尾 / 仅匹配目录、开头 / 锚定以及 ** 跨目录匹配
+//   - 与 git 一致, 父目录被排除后其中的文件无法通过 ! 重新包含
+func (f *Filter) AddIgnoreFile(ignorePath string) error {
+	file, err := os.Open(ignorePath)
+	if err != nil {
+		return fmt.Errorf("打开忽略文件失败: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取忽略文件 %s 失败: %w", ignorePath, err)
+	}
+
+	base, err := filepath.Abs(filepath.Dir(ignorePath))
+	if err != nil {
+		return fmt.Errorf("获取忽略文件目录失败: %w", err)
+	}
+
+	set, err := newRuleSet(base, patterns, true)
+	if err != nil {
+		return fmt.Errorf("解析忽略文件 %s 失败: %w", ignorePath, err)
+	}
+	f.excludes = append(f.excludes, set)
+
+	return nil
+}
+
+// Excluded 检查路径是否被排除
+//
+// 参数:
+//   - p: 文件或目录路径
+//   - isDir: 是否为目录
+//
+// 返回:
+//   - bool: 被排除时返回 true(目录被排除时应跳过整个目录)
+func (f *Filter) Excluded(p string, isDir bool) bool {
+	if f == nil {
+		return false
+	}
+
+	abs := f.abs(p)
+	for _, set := range f.excludes {
+		if set.match(abs, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// Included 检查文件是否满足 --include 规则
+//
+// 参数:
+//   - p: 文件路径
+//
+// 返回:
+//   - bool: 未指定 --include 或文件匹配任一包含模式时返回 true
+func (f *Filter) Included(p string) bool {
+	if f == nil || f.include == nil {
+		return true
+	}
+	return f.include.match(f.abs(p), false)
+}
+
+// abs 将路径转换为绝对路径
+func (f *Filter) abs(p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(f.cwd, p)
+}
+
+// newRuleSet 解析模式列表
+//
+// 参数:
+//   - base: 基准目录
+//   - patterns: 模式列表
+//   - gitignore: 是否按忽略文件语法解析(支持注释和取反)
+//
+// 返回:
+//   - *ruleSet: 规则集
+//   - error: 模式无效时返回错误
+func newRuleSet(base string, patterns []string, gitignore bool) (*ruleSet, error) {
+	set := &ruleSet{base: base}
+
+	for _, pattern := range patterns {
+		var r rule
+
+		pattern = strings.TrimRight(pattern, " \t\r")
+		if gitignore {
+			if pattern == "" || strings.HasPrefix(pattern, "#") {
+				continue
+			}
+			if strings.HasPrefix(pattern, "!") {
+				r.negate = true
+				pattern = pattern[1:]
+			} else if strings.HasPrefix(pattern, `\#`) || strings.HasPrefix(pattern, `\!`) {
+				pattern = pattern[1:]
+			}
+		}
+		pattern = filepath.ToSlash(pattern)
+
+		if strings.HasSuffix(pattern, "/") {
+			r.dirOnly = true
+			pattern = strings.TrimRight(pattern, "/")
+		}
+		if strings.Contains(pattern, "/") {
+			r.anchored = true
+			pattern = strings.TrimPrefix(pattern, "/")
+		}
+		if pattern == "" {
+			continue
+		}
+
+		r.segments = strings.Split(pattern, "/")
+		for _, segment := range r.segments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("无效的匹配模式: %s", pattern)
+			}
+		}
+
+		set.rules = append(set.rules, r)
+	}
+
+	return set, nil
+}
+
+// match 检查路径是否匹配规则集
+//
+// 参数:
+//   - abs: 绝对路径
+//   - isDir: 是否为目录
+//
+// 返回:
+//   - bool: 最后一条匹配的规则不是取反规则时返回 true
+func (s *ruleSet) match(abs string, isDir bool) bool {
+	rel, err := filepath.Rel(s.base, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false // 基准目录之外的路径不受规则约束
+	}
+	names := strings.Split(filepath.ToSlash(rel), "/")
+
+	matched := false
+	for _, r := range s.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.matches(names) {
+			matched = !r.negate
+		}
+	}
+	return matched
+}
+
+// matches 检查路径是否匹配单条规则
+func (r rule) matches(names []string) bool {
+	if !r.anchored {
+		ok, _ := path.Match(r.segments[0], names[len(names)-1])
+		return ok
+	}
+	return matchSegments(r.segments, names)
+}
+
+// matchSegments 按路径段匹配, ** 匹配零个或多个路径段
+//
+// 注意:
+//   - 结尾的 ** 至少匹配一个路径段, 即 "dir/**" 匹配目录中的内容而不匹配目录本身
+func matchSegments(segments, names []string) bool {
+	for len(segments) > 0 {
+		if segments[0] == "**" {
+			rest := segments[1:]
+			if len(rest) == 0 {
+				return len(names) > 0
+			}
+			for i := 0; i <= len(names); i++ {
+				if matchSegments(rest, names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(names) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(segments[0], names[0]); !ok {
+			return false
+		}
+		segments, names = segments[1:], names[1:]
+	}
+
+	return len(names) == 0
+}
@@ -0,0 +1,117 @@
+// Package ratelimit 实现了多个协程共享的读取速率限制。
+// 该文件提供基于令牌桶的限速器和速率字符串解析, 用于 --bwlimit 限制哈希计算的磁盘读取带宽。
+package ratelimit
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/MM-Q/fck/commands/internal/common"
+)
+
+// Limiter 令牌桶限速器
+//
+// 注意:
+//   - 可被多个协程共享, 所有协程的读取总速率不超过设定值
+//   - 令牌桶容量为一秒的读取量, 空闲后允许短时突发
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64   // 每秒字节数
+	tokens float64   // 当前可用字节数(为负时表示已透支)
+	last   time.Time // 上次补充令牌的时间
+}
+
+// New 创建限速器
+//
+// 参数:
+//   - bytesPerSec: 每秒允许读取的字节数
+//
+// 返回:
+//   - *Limiter: 限速器, bytesPerSec 不大于0时返回nil(不限速)
+func New(bytesPerSec int64) *Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return &Limiter{
+		rate:   float64(bytesPerSec),
+		tokens: float64(bytesPerSec),
+		last:   time.Now(),
+	}
+}
+
+// Wait 消耗 n 字节的配额, 配额不足时阻塞到可用为止
+//
+// 参数:
+//   - n: 字节数
+func (l *Limiter) Wait(n int) {
+	if l == nil || n <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.rate, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens -= float64(n)
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	// 先预留配额再等待, 多个协程按请求顺序依次获得配额
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// Reader 包装读取器, 按限速器的速率读取数据
+//
+// 参数:
+//   - r: 原始读取器
+//
+// 返回:
+//   - io.Reader: 限速读取器, 限速器为nil时返回原始读取器
+func (l *Limiter) Reader(r io.Reader) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &reader{r: r, limiter: l}
+}
+
+// reader 限速读取器
+type reader struct {
+	r       io.Reader
+	limiter *Limiter
+}
+
+// Read 读取数据后消耗相应配额
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.limiter.Wait(n)
+	return n, err
+}
+
+// ParseRate 解析速率字符串
+//
+// 参数:
+//   - s: 速率字符串, 如 50M/s、512K、1.5G/s、1048576
+//
+// 返回:
+//   - int64: 每秒字节数
+//   - error: 格式无效或速率不大于0时返回错误
+//
+// 注意:
+//   - 单位 K/M/G/T 按1024进制计算, 可带 B 或 iB 后缀, /s 后缀可省略, 不区分大小写
+func ParseRate(s string) (int64, error) {
+	value := strings.TrimSpace(s)
+	if strings.HasSuffix(strings.ToLower(value), "/s") {
+		value = value[:len(value)-2]
+	}
+
+	rate, err := common.ParseSize(value)
+	if err != nil {
+		return 0, fmt.Errorf("无效的速率: %s (示例: 50M/s、512K)", s)
+	}
+	return rate, nil
+}
@@ -0,0 +1,244 @@
+// Package signature 实现了校验文件的 Ed25519 签名和验证。
+// 该文件负责密钥的生成与读写(PKCS#8/PKIX PEM 格式, 可与 openssl 互通)以及分离式签名文件的生成和验证,
+// 全部操作均在本地完成, 无需联网。
+package signature
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"gitee.com/MM-Q/fck/commands/internal/common"
+)
+
+// 签名相关常量
+const (
+	Extension     = ".sig"          // 签名文件扩展名(追加在校验文件名之后)
+	PublicKeyExt  = ".pub"          // 公钥文件扩展名
+	Algorithm     = "ed25519"       // 签名算法
+	signatureType = "FCK SIGNATURE" // 签名文件的 PEM 类型
+	headerAlgo    = "Algorithm"     // 签名文件头: 签名算法
+	headerKeyID   = "Key-Id"        // 签名文件头: 公钥标识
+)
+
+// ErrUnsigned 校验文件没有签名文件
+var ErrUnsigned = errors.New("校验文件未签名")
+
+// ErrInvalid 签名与校验文件内容或公钥不匹配
+var ErrInvalid = errors.New("签名验证失败, 校验文件可能已被篡改")
+
+// GenerateKey 生成 Ed25519 密钥对
+//
+// 返回:
+//   - ed25519.PublicKey: 公钥
+//   - ed25519.PrivateKey: 私钥
+//   - error: 错误信息
+func GenerateKey() (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("生成密钥失败: %w", err)
+	}
+	return pub, priv, nil
+}
+
+// KeyID 获取公钥标识(公钥 SHA-256 的前8字节)
+//
+// 参数:
+//   - pub: 公钥
+//
+// 返回:
+//   - string: 十六进制公钥标识
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// SignatureFileName 获取校验文件对应的签名文件路径
+//
+// 参数:
+//   - manifest: 校验文件路径
+//
+// 返回:
+//   - string: 签名文件路径
+func SignatureFileName(manifest string) string {
+	return manifest + Extension
+}
+
+// WritePrivateKey 以 PKCS#8 PEM 格式写入私钥
+//
+// 参数:
+//   - path: 私钥文件路径
+//   - priv: 私钥
+//
+// 返回:
+//   - error: 错误信息
+//
+// 注意:
+//   - 私钥文件权限为 0600
+func WritePrivateKey(path string, priv ed25519.PrivateKey) error {
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return fmt.Errorf("编码私钥失败: %w", err)
+	}
+	return common.WriteFileAtomic(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+}
+
+// WritePublicKey 以 PKIX PEM 格式写入公钥
+//
+// 参数:
+//   - path: 公钥文件路径
+//   - pub: 公钥
+//
+// 返回:
+//   - error: 错误信息
+func WritePublicKey(path string, pub ed25519.PublicKey) error {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return fmt.Errorf("编码公钥失败: %w", err)
+	}
+	return common.WriteFileAtomic(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644)
+}
+
+// LoadPrivateKey 读取 PKCS#8 PEM 格式的 Ed25519 私钥
+//
+// 参数:
+//   - path: 私钥文件路径
+//
+// 返回:
+//   - ed25519.PrivateKey: 私钥
+//   - error: 错误信息
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析私钥 %s 失败: %w", path, err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("私钥 %s 不是 Ed25519 密钥", path)
+	}
+	return priv, nil
+}
+
+// LoadPublicKey 读取 PKIX PEM 格式的 Ed25519 公钥
+//
+// 参数:
+//   - path: 公钥文件路径
+//
+// 返回:
+//   - ed25519.PublicKey: 公钥
+//   - error: 错误信息
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析公钥 %s 失败: %w", path, err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("公钥 %s 不是 Ed25519 密钥", path)
+	}
+	return pub, nil
+}
+
+// SignFile 为校验文件生成分离式签名文件
+//
+// 参数:
+//   - manifest: 校验文件路径
+//   - priv: 私钥
+//
+// 返回:
+//   - string: 签名文件路径
+//   - error: 错误信息
+func SignFile(manifest string, priv ed25519.PrivateKey) (string, error) {
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		return "", fmt.Errorf("读取校验文件 %s 失败: %w", manifest, err)
+	}
+
+	block := &pem.Block{
+		Type: signatureType,
+		Headers: map[string]string{
+			headerAlgo:  Algorithm,
+			headerKeyID: KeyID(priv.Public().(ed25519.PublicKey)),
+		},
+		Bytes: ed25519.Sign(priv, data),
+	}
+
+	sigPath := SignatureFileName(manifest)
+	if err := common.WriteFileAtomic(sigPath, pem.EncodeToMemory(block), 0644); err != nil {
+		return "", fmt.Errorf("写入签名文件失败: %w", err)
+	}
+	return sigPath, nil
+}
+
+// Verify 使用公钥验证校验文件内容的签名
+//
+// 参数:
+//   - data: 校验文件内容
+//   - sigPath: 签名文件路径
+//   - pub: 公钥
+//
+// 返回:
+//   - error: 签名文件不存在时返回 ErrUnsigned, 签名不匹配时返回 ErrInvalid
+//
+// 注意:
+//   - 调用方应使用验证过的 data 继续解析, 避免验证后文件被替换
+func Verify(data []byte, sigPath string, pub ed25519.PublicKey) error {
+	if _, err := os.Stat(sigPath); os.IsNotExist(err) {
+		return fmt.Errorf("%w: 缺少签名文件 %s", ErrUnsigned, sigPath)
+	}
+
+	block, err := readPEM(sigPath, signatureType)
+	if err != nil {
+		return err
+	}
+
+	if algo := block.Headers[headerAlgo]; algo != Algorithm {
+		return fmt.Errorf("不支持的签名算法: %s", algo)
+	}
+	if keyID := block.Headers[headerKeyID]; keyID != KeyID(pub) {
+		return fmt.Errorf("%w: 签名使用的密钥(%s)与指定公钥(%s)不一致", ErrInvalid, keyID, KeyID(pub))
+	}
+	if !ed25519.Verify(pub, data, block.Bytes) {
+		return ErrInvalid
+	}
+
+	return nil
+}
+
+// readPEM 读取指定类型的 PEM 块
+//
+// 参数:
+//   - path: 文件路径
+//   - blockType: PEM 类型
+//
+// 返回:
+//   - *pem.Block: PEM 块
+//   - error: 错误信息
+func readPEM(path, blockType string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件 %s 失败: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("文件 %s 不是有效的 %s 文件", path, blockType)
+	}
+	return block, nil
+}
@@ -0,0 +1,91 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 分块哈希记录前缀(仅 fck 格式使用, 以 # 开头, 不支持分块的解析器会将其视为注释跳过)
+const (
+	ChunkSizePrefix = "#chunk-size#" // 分块大小记录, 紧跟在文件头之后: #chunk-size#字节数
+	ChunkPrefix     = "#chunk#"      // 分块哈希记录, 紧跟在所属文件的记录之后: #chunk#起始偏移-结束偏移#哈希值
+)
+
+// FormatChunkSizeLine 生成分块大小记录
+//
+// 参数:
+//   - chunkSize: 分块大小(字节)
+//
+// 返回值:
+//   - string: 以换行符结尾的记录
+func FormatChunkSizeLine(chunkSize int64) string {
+	return fmt.Sprintf("%s%d\n", ChunkSizePrefix, chunkSize)
+}
+
+// FormatChunkLine 生成分块哈希记录
+//
+// 参数:
+//   - start: 分块起始偏移(包含)
+//   - end: 分块结束偏移(包含)
+//   - hashValue: 十六进制哈希值
+//
+// 返回值:
+//   - string: 以换行符结尾的记录
+func FormatChunkLine(start, end int64, hashValue string) string {
+	return fmt.Sprintf("%s%d-%d#%s\n", ChunkPrefix, start, end, hashValue)
+}
+
+// ParseChunkSizeLine 解析分块大小记录
+//
+// 参数:
+//   - line: 校验文件中的一行
+//
+// 返回值:
+//   - int64: 分块大小
+//   - bool: 是否为有效的分块大小记录
+func ParseChunkSizeLine(line string) (int64, bool) {
+	value, ok := strings.CutPrefix(strings.TrimSpace(line), ChunkSizePrefix)
+	if !ok {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size <= 0 {
+		return 0, false
+	}
+	return size, true
+}
+
+// ParseChunkLine 解析分块哈希记录
+//
+// 参数:
+//   - line: 校验文件中的一行
+//
+// 返回值:
+//   - int64: 分块起始偏移(包含)
+//   - int64: 分块结束偏移(包含)
+//   - string: 小写十六进制哈希值
+//   - bool: 是否为有效的分块哈希记录
+func ParseChunkLine(line string) (int64, int64, string, bool) {
+	value, ok := strings.CutPrefix(strings.TrimSpace(line), ChunkPrefix)
+	if !ok {
+		return 0, 0, "", false
+	}
+	rangeStr, hashValue, ok := strings.Cut(value, "#")
+	if !ok || hashValue == "" {
+		return 0, 0, "", false
+	}
+	startStr, endStr, ok := strings.Cut(rangeStr, "-")
+	if !ok {
+		return 0, 0, "", false
+	}
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, "", false
+	}
+	end, err := strconv.ParseInt(endStr, 10, 64)
+	if err != nil || end < start {
+		return 0, 0, "", false
+	}
+	return start, end, strings.ToLower(hashValue), true
+}
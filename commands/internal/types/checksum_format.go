@@ -0,0 +1,142 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 校验文件格式
+const (
+	ChecksumFormatFck = "fck" // fck 原生格式: 带 #hashType#timestamp#mode 文件头, 行格式为 hash\t"path"
+	ChecksumFormatGNU = "gnu" // GNU coreutils 格式(sha256sum 等): hash  path
+	ChecksumFormatBSD = "bsd" // BSD 标签格式: ALGO (path) = hash
+)
+
+// 受支持的校验文件格式
+var SupportedChecksumFormats = []string{
+	ChecksumFormatFck,
+	ChecksumFormatGNU,
+	ChecksumFormatBSD,
+}
+
+var (
+	// BSDLineRegex 匹配 BSD 标签格式: [\]ALGO (path) = hash
+	BSDLineRegex = regexp.MustCompile(`^(\\?)([A-Za-z0-9-]+) \((.+)\) = ([0-9a-fA-F]+)$`)
+
+	// GNULineRegex 匹配 GNU coreutils 格式: [\]hash  path 或 [\]hash *path(二进制模式)
+	GNULineRegex = regexp.MustCompile(`^(\\?)([0-9a-fA-F]+) [ *](.+)$`)
+)
+
+// gnuEscaper GNU coreutils 文件名转义规则(反斜杠和换行)
+var gnuEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+
+// FormatChecksumLine 按指定格式生成一行校验记录
+//
+// 参数:
+//   - format: 校验文件格式(fck/gnu/bsd)
+//   - tag: BSD 格式使用的算法标签(如 SHA256)
+//   - hashValue: 十六进制哈希值
+//   - filePath: 文件路径
+//
+// 返回值:
+//   - string: 以换行符结尾的校验记录
+//
+// 注意:
+//   - GNU/BSD 格式下, 文件名包含反斜杠或换行时会按 coreutils 规则转义, 并在行首添加反斜杠
+func FormatChecksumLine(format, tag, hashValue, filePath string) string {
+	switch format {
+	case ChecksumFormatGNU, ChecksumFormatBSD:
+		prefix := ""
+		if strings.ContainsAny(filePath, "\\\n\r") {
+			prefix = `\`
+			filePath = gnuEscaper.Replace(filePath)
+		}
+
+		if format == ChecksumFormatBSD {
+			return fmt.Sprintf("%s%s (%s) = %s\n", prefix, tag, filePath, hashValue)
+		}
+		return fmt.Sprintf("%s%s  %s\n", prefix, hashValue, filePath)
+
+	default:
+		return fmt.Sprintf("%s\t%q\n", hashValue, filePath)
+	}
+}
+
+// UnescapeChecksumPath 还原 GNU/BSD 格式中被转义的文件名
+//
+// 参数:
+//   - filePath: 转义后的文件名
+//
+// 返回值:
+//   - string: 原始文件名
+func UnescapeChecksumPath(filePath string) string {
+	var b strings.Builder
+	b.Grow(len(filePath))
+
+	for i := 0; i < len(filePath); i++ {
+		c := filePath[i]
+		if c == '\\' && i+1 < len(filePath) {
+			switch filePath[i+1] {
+			case '\\':
+				b.WriteByte('\\')
+				i++
+				continue
+			case 'n':
+				b.WriteByte('\n')
+				i++
+				continue
+			case 'r':
+				b.WriteByte('\r')
+				i++
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// ParseChecksumLine 解析 FormatChecksumLine 生成的一行校验记录
+//
+// 参数:
+//   - line: 校验记录(不含换行符)
+//
+// 返回值:
+//   - format: 识别出的格式(fck/gnu/bsd)
+//   - tag: BSD 格式的算法标签, 其他格式为空
+//   - hashValue: 十六进制哈希值
+//   - filePath: 原始文件路径
+//   - ok: 是否为有效的校验记录(空行、注释行和无法识别的行返回 false)
+//
+// 注意:
+//   - 仅做格式解析, 不检查哈希值长度和路径安全性
+func ParseChecksumLine(line string) (format, tag, hashValue, filePath string, ok bool) {
+	line = strings.TrimRight(line, "\r")
+	if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+		return "", "", "", "", false
+	}
+
+	// fck 原生格式: hash\t"path"
+	if hashPart, pathPart, found := strings.Cut(line, "\t"); found && strings.HasPrefix(pathPart, `"`) {
+		if unquoted, err := strconv.Unquote(pathPart); err == nil {
+			return ChecksumFormatFck, "", hashPart, unquoted, true
+		}
+	}
+
+	var escaped bool
+	if m := BSDLineRegex.FindStringSubmatch(line); m != nil {
+		format, escaped, tag, filePath, hashValue = ChecksumFormatBSD, m[1] != "", m[2], m[3], m[4]
+	} else if m := GNULineRegex.FindStringSubmatch(line); m != nil {
+		format, escaped, hashValue, filePath = ChecksumFormatGNU, m[1] != "", m[2], m[3]
+	} else {
+		return "", "", "", "", false
+	}
+
+	if escaped {
+		filePath = UnescapeChecksumPath(filePath)
+	}
+	return format, tag, hashValue, filePath, true
+}
@@ -2,22 +2,70 @@ package types
 
 import (
 	"fmt"
+	"strings"
 )
 
 // ChecksumHeader 校验文件头信息结构体
 type ChecksumHeader struct {
-	HashType  string // 哈希类型 (md5, sha1, sha256等)
-	Timestamp string // 生成时间戳
-	Mode      string // 模式 (PORTABLE/LOCAL)
-	BasePath  string // 基准路径 (仅LOCAL模式下使用)
+	HashType  string   // 哈希类型 (md5, sha1, sha256等)
+	Timestamp string   // 生成时间戳
+	Mode      string   // 模式 (PORTABLE/LOCAL)
+	BasePath  string   // 基准路径 (仅LOCAL模式下使用)
+	Tree      string   // Merkle 树选项 (仅TREE模式下使用, 如 mode,symlinks)
+	Format    string   // 校验文件格式 (fck/gnu/bsd, 解析时识别, 不写入文件头)
+	Keyed     bool     // 是否为 HMAC 密钥模式 (文件头中算法记录为 hmac-<算法>, 不记录密钥)
+	ChunkSize int64    // 分块大小 (由文件头之后的 #chunk-size# 行记录, 0表示未分块)
+	Metadata  []string // 记录的元数据字段 (由文件头之后的 #metadata# 行记录, 为空表示未记录元数据)
+	Symlinks  string   // 软链接处理策略 (由文件头之后的 #symlinks# 行记录, 为空表示未记录, 按旧版本行为处理)
 }
 
 // String 生成文件头字符串
+//
+// 注意:
+//   - 指定分块大小时, 额外输出一行 #chunk-size# 记录
+//   - 记录元数据时, 额外输出一行 #metadata# 记录
+//   - 指定软链接处理策略时, 额外输出一行 #symlinks# 记录
 func (h *ChecksumHeader) String() string {
-	if h.Mode == ChecksumModeLocal && h.BasePath != "" {
-		return fmt.Sprintf("#%s#%s#%s#%s\n", h.HashType, h.Timestamp, h.Mode, h.BasePath)
+	hashType := h.HashType
+	if h.Keyed {
+		hashType = HMACPrefix + hashType
 	}
-	return fmt.Sprintf("#%s#%s#%s\n", h.HashType, h.Timestamp, h.Mode)
+
+	var header string
+	switch {
+	case h.Mode == ChecksumModeLocal && h.BasePath != "":
+		header = fmt.Sprintf("#%s#%s#%s#%s\n", hashType, h.Timestamp, h.Mode, h.BasePath)
+	case h.Mode == ChecksumModeTree && h.Tree != "":
+		header = fmt.Sprintf("#%s#%s#%s#%s\n", hashType, h.Timestamp, h.Mode, h.Tree)
+	default:
+		header = fmt.Sprintf("#%s#%s#%s\n", hashType, h.Timestamp, h.Mode)
+	}
+
+	if h.ChunkSize > 0 {
+		header += FormatChunkSizeLine(h.ChunkSize)
+	}
+	if len(h.Metadata) > 0 {
+		header += FormatMetadataFieldsLine(h.Metadata)
+	}
+	if h.Symlinks != "" {
+		header += FormatSymlinksLine(h.Symlinks)
+	}
+	return header
+}
+
+// ParseHashType 解析文件头中的算法字段
+//
+// 参数:
+//   - field: 文件头中的算法字段(如 sha256 或 hmac-sha256)
+//
+// 返回:
+//   - string: 哈希算法名称
+//   - bool: 是否为 HMAC 密钥模式
+func ParseHashType(field string) (string, bool) {
+	if len(field) > len(HMACPrefix) && strings.EqualFold(field[:len(HMACPrefix)], HMACPrefix) {
+		return field[len(HMACPrefix):], true
+	}
+	return field, false
 }
 
 // IsPortableMode 判断是否为便携模式
@@ -29,3 +77,8 @@ func (h *ChecksumHeader) IsPortableMode() bool {
 func (h *ChecksumHeader) IsLocalMode() bool {
 	return h.Mode == ChecksumModeLocal
 }
+
+// IsTreeMode 判断是否为目录 Merkle 树模式
+func (h *ChecksumHeader) IsTreeMode() bool {
+	return h.Mode == ChecksumModeTree
+}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"strings"
+)
+
+// 元数据记录前缀(仅 fck 格式使用, 以 # 开头, 不支持元数据的解析器会将其视为注释跳过)
+const (
+	MetadataFieldsPrefix = "#metadata#" // 元数据字段记录, 紧跟在文件头之后: #metadata#字段1,字段2
+	MetadataPrefix       = "#meta#"     // 文件元数据记录, 紧跟在所属文件的记录之后: #meta#key=value&key=value
+)
+
+// FormatMetadataFieldsLine 生成元数据字段记录
+//
+// 参数:
+//   - fields: 记录的元数据字段列表
+//
+// 返回值:
+//   - string: 以换行符结尾的记录
+func FormatMetadataFieldsLine(fields []string) string {
+	return MetadataFieldsPrefix + strings.Join(fields, ",") + "\n"
+}
+
+// FormatMetadataLine 生成文件元数据记录
+//
+// 参数:
+//   - encoded: 编码后的元数据
+//
+// 返回值:
+//   - string: 以换行符结尾的记录
+func FormatMetadataLine(encoded string) string {
+	return MetadataPrefix + encoded + "\n"
+}
+
+// ParseMetadataFieldsLine 解析元数据字段记录
+//
+// 参数:
+//   - line: 校验文件中的一行
+//
+// 返回值:
+//   - string: 以逗号分隔的字段列表
+//   - bool: 是否为元数据字段记录
+func ParseMetadataFieldsLine(line string) (string, bool) {
+	value, ok := strings.CutPrefix(strings.TrimSpace(line), MetadataFieldsPrefix)
+	if !ok || value == "" {
+		return "", false
+	}
+	return value, true
+}
+
+// ParseMetadataLine 解析文件元数据记录
+//
+// 参数:
+//   - line: 校验文件中的一行
+//
+// 返回值:
+//   - string: 编码后的元数据
+//   - bool: 是否为文件元数据记录
+func ParseMetadataLine(line string) (string, bool) {
+	return strings.CutPrefix(strings.TrimSpace(line), MetadataPrefix)
+}
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatPrefix 文件状态记录前缀, 紧跟在所属文件的记录之后: #stat#文件大小#修改时间(Unix纳秒)
+// (仅 fck 格式使用, 由 --update 写入, 用于判断文件是否变化; 校验时不使用, 旧版本解析器会将其视为注释跳过)
+const StatPrefix = "#stat#"
+
+// FormatStatLine 生成文件状态记录
+//
+// 参数:
+//   - size: 文件大小
+//   - modTime: 修改时间(Unix纳秒)
+//
+// 返回值:
+//   - string: 以换行符结尾的记录
+func FormatStatLine(size, modTime int64) string {
+	return fmt.Sprintf("%s%d#%d\n", StatPrefix, size, modTime)
+}
+
+// ParseStatLine 解析文件状态记录
+//
+// 参数:
+//   - line: 校验文件中的一行
+//
+// 返回值:
+//   - int64: 文件大小
+//   - int64: 修改时间(Unix纳秒)
+//   - bool: 是否为有效的文件状态记录
+func ParseStatLine(line string) (int64, int64, bool) {
+	value, ok := strings.CutPrefix(strings.TrimSpace(line), StatPrefix)
+	if !ok {
+		return 0, 0, false
+	}
+	sizeStr, modTimeStr, ok := strings.Cut(value, "#")
+	if !ok {
+		return 0, 0, false
+	}
+	size, err := strconv.ParseInt(sizeStr, 10, 64)
+	if err != nil || size < 0 {
+		return 0, 0, false
+	}
+	modTime, err := strconv.ParseInt(modTimeStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return size, modTime, true
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"slices"
+	"strings"
+)
+
+// 软链接处理策略
+const (
+	SymlinksFollow = "follow" // 跟随软链接, 按目标文件的内容计算(目录软链接继续遍历, 按设备号/inode检测循环)
+	SymlinksSkip   = "skip"   // 跳过软链接
+	SymlinksLink   = "link"   // 以链接目标文本作为内容计算, 不跟随
+)
+
+// SupportedSymlinkPolicies 受支持的软链接处理策略
+var SupportedSymlinkPolicies = []string{
+	SymlinksFollow,
+	SymlinksSkip,
+	SymlinksLink,
+}
+
+// SymlinksPrefix 软链接策略记录前缀, 紧跟在文件头之后: #symlinks#策略(仅 fck 格式使用, 旧版本解析器会将其视为注释跳过)
+const SymlinksPrefix = "#symlinks#"
+
+// FormatSymlinksLine 生成软链接策略记录
+//
+// 参数:
+//   - policy: 软链接处理策略
+//
+// 返回值:
+//   - string: 以换行符结尾的记录
+func FormatSymlinksLine(policy string) string {
+	return SymlinksPrefix + policy + "\n"
+}
+
+// ParseSymlinksLine 解析软链接策略记录
+//
+// 参数:
+//   - line: 校验文件中的一行
+//
+// 返回值:
+//   - string: 软链接处理策略
+//   - bool: 是否为软链接策略记录
+func ParseSymlinksLine(line string) (string, bool) {
+	value, ok := strings.CutPrefix(strings.TrimSpace(line), SymlinksPrefix)
+	if !ok || value == "" {
+		return "", false
+	}
+	return value, true
+}
+
+// IsValidSymlinkPolicy 判断软链接处理策略是否受支持
+func IsValidSymlinkPolicy(policy string) bool {
+	return slices.Contains(SupportedSymlinkPolicies, policy)
+}
@@ -29,6 +29,10 @@ const (
 	// 校验文件模式
 	ChecksumModePortable = "PORTABLE"
 	ChecksumModeLocal    = "LOCAL"
+	ChecksumModeTree     = "TREE" // 目录 Merkle 树模式, 每行记录一个目录的子树摘要
+
+	// HMAC 密钥模式下文件头算法字段的前缀(如 hmac-sha256)
+	HMACPrefix = "hmac-"
 )
 
 // 虚拟哈希表条目
@@ -38,6 +42,15 @@ type VirtualHashEntry struct {
 
 	// 哈希值
 	Hash string
+
+	// 分块哈希值(按块序号排列, 仅 --chunk-size 生成的校验文件)
+	Chunks []string
+
+	// 记录分块哈希值时的文件大小(由最后一个分块的结束偏移得出)
+	ChunkedSize int64
+
+	// 编码后的文件元数据(仅 --with-metadata 生成的校验文件)
+	Metadata string
 }
 
 // 虚拟哈希表
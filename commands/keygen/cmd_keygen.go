@@ -0,0 +1,52 @@
+// Package keygen 实现了签名密钥生成命令的主要逻辑。
+// 该文件包含 keygen 子命令的入口函数，负责生成 Ed25519 密钥对并写入私钥和公钥文件。
+package keygen
+
+import (
+	"fmt"
+	"os"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/signature"
+)
+
+// KeygenCmdMain 是 keygen 子命令的主函数
+//
+// 参数:
+//   - cl: 颜色库对象
+//
+// 返回:
+//   - error: 错误信息
+func KeygenCmdMain(cl *colorlib.ColorLib) error {
+	privPath := keygenCmd.Arg(0)
+	if privPath == "" {
+		privPath = defaultKeyName
+	}
+	pubPath := privPath + signature.PublicKeyExt
+
+	// 避免误覆盖已有密钥, 私钥一旦被覆盖将无法恢复
+	if !keygenCmdForce.Get() {
+		for _, path := range []string{privPath, pubPath} {
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("密钥文件 %s 已存在, 如需覆盖请使用 --force", path)
+			}
+		}
+	}
+
+	pub, priv, err := signature.GenerateKey()
+	if err != nil {
+		return err
+	}
+
+	if err := signature.WritePrivateKey(privPath, priv); err != nil {
+		return fmt.Errorf("写入私钥失败: %w", err)
+	}
+	if err := signature.WritePublicKey(pubPath, pub); err != nil {
+		return fmt.Errorf("写入公钥失败: %w", err)
+	}
+
+	cl.PrintOkf("已生成 Ed25519 密钥对 (密钥 %s)\n", signature.KeyID(pub))
+	cl.PrintOkf("私钥: %s\n", privPath)
+	cl.PrintOkf("公钥: %s\n", pubPath)
+	return nil
+}
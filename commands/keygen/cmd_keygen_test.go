@@ -0,0 +1,66 @@
+package keygen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/signature"
+)
+
+// TestKeygenCmdMain 测试生成密钥对
+func TestKeygenCmdMain(t *testing.T) {
+	cl := colorlib.NewColorLib()
+	cl.SetColor(false)
+
+	privPath := filepath.Join(t.TempDir(), "key")
+
+	keygenCmd = InitKeygenCmd()
+	if err := keygenCmd.Parse([]string{privPath}); err != nil {
+		t.Fatalf("解析参数失败: %v", err)
+	}
+	if err := KeygenCmdMain(cl); err != nil {
+		t.Fatalf("KeygenCmdMain() 返回错误: %v", err)
+	}
+
+	// 私钥仅所有者可读写
+	info, err := os.Stat(privPath)
+	if err != nil {
+		t.Fatalf("私钥文件不存在: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("私钥文件权限不正确: %v", info.Mode().Perm())
+	}
+
+	// 生成的密钥对可以相互验证
+	priv, err := signature.LoadPrivateKey(privPath)
+	if err != nil {
+		t.Fatalf("读取私钥失败: %v", err)
+	}
+	pub, err := signature.LoadPublicKey(privPath + signature.PublicKeyExt)
+	if err != nil {
+		t.Fatalf("读取公钥失败: %v", err)
+	}
+	if !pub.Equal(priv.Public()) {
+		t.Error("公钥与私钥不匹配")
+	}
+
+	// 已存在时拒绝覆盖
+	if err := KeygenCmdMain(cl); err == nil {
+		t.Error("密钥文件已存在时应返回错误")
+	}
+
+	// --force 覆盖已有密钥
+	_ = keygenCmdForce.Set("true")
+	if err := KeygenCmdMain(cl); err != nil {
+		t.Errorf("--force 覆盖时不应返回错误: %v", err)
+	}
+	newPriv, err := signature.LoadPrivateKey(privPath)
+	if err != nil {
+		t.Fatalf("读取私钥失败: %v", err)
+	}
+	if newPriv.Equal(priv) {
+		t.Error("--force 后私钥应被重新生成")
+	}
+}
@@ -0,0 +1,41 @@
+// Package keygen 定义了签名密钥生成命令的标志和参数配置。
+// 该文件负责初始化 keygen 子命令的命令行参数解析和帮助信息设置。
+package keygen
+
+import (
+	"flag"
+	"fmt"
+
+	"gitee.com/MM-Q/qflag"
+)
+
+// defaultKeyName 默认的私钥文件名(公钥为同名加 .pub 后缀)
+const defaultKeyName = "fck_ed25519"
+
+var (
+	// fck keygen 子命令
+	keygenCmd      *qflag.Cmd
+	keygenCmdForce *qflag.BoolFlag // force 标志
+)
+
+func InitKeygenCmd() *qflag.Cmd {
+	// fck keygen 子命令
+	keygenCmd = qflag.NewCmd("keygen", "k", flag.ContinueOnError)
+
+	keygenCmdCfg := qflag.CmdConfig{
+		UseChinese: true,
+		Desc:       "签名密钥生成工具, 生成用于 hash --sign 和 check --verify-key 的 Ed25519 密钥对",
+		Notes: []string{
+			"私钥以 PKCS#8 PEM 格式写入指定路径(权限为0600), 公钥以 PEM 格式写入同名 .pub 文件, 可与 openssl 互通",
+			"未指定路径时生成当前目录下的 fck_ed25519 和 fck_ed25519.pub",
+			"私钥用于签名, 请妥善保管; 公钥可随校验文件一起分发, 用于验证签名",
+		},
+		UsageSyntax: fmt.Sprintf("%s keygen [options] [私钥路径]\n", qflag.Root.LongName()),
+	}
+
+	keygenCmd.ApplyConfig(keygenCmdCfg)
+
+	keygenCmdForce = keygenCmd.Bool("force", "f", false, "覆盖已存在的密钥文件")
+
+	return keygenCmd
+}
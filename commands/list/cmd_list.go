@@ -4,7 +4,6 @@ package list
 
 import (
 	"errors"
-	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -12,6 +11,7 @@ import (
 
 	"gitee.com/MM-Q/colorlib"
 	"gitee.com/MM-Q/fck/commands/internal/common"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
 	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
@@ -221,7 +221,7 @@ func validateArgs() error {
 
 	// 如果指定了-ho检查是否指定-a
 	if (listCmdType.Get() == types.FindTypeHiddenShort || listCmdType.Get() == types.FindTypeHidden) && !listCmdAll.Get() {
-		return fmt.Errorf("必须指定 %s 选项才能使用 %s 选项", listCmdAll.Name(), listCmdType.Name())
+		return exitcode.Usagef("必须指定 %s 选项才能使用 %s 选项", listCmdAll.Name(), listCmdType.Name())
 	}
 
 	return nil
@@ -32,7 +32,7 @@ var (
 
 func InitListCmd() *qflag.Cmd {
 	// fck list 子命令
-	listCmd = qflag.NewCmd("list", "ls", flag.ExitOnError)
+	listCmd = qflag.NewCmd("list", "ls", flag.ContinueOnError)
 
 	listCmdCfg := qflag.CmdConfig{
 		UseChinese:  true,
@@ -452,7 +452,7 @@ func (f *FileFormatter) addTableRow(t table.Writer, info FileInfo, opts FormatOp
 	infoType := GetColorString(info, info.EntryType.String(), f.colorLib)
 
 	// 文件大小和单位
-	infoSize, infoSizeUnit := f.humanSize(info.Size)
+	infoSize, infoSizeUnit := common.HumanSize(info.Size, 1)
 	f.colorLib.SetBold(false)
 	infoSize = f.colorLib.Syellow(infoSize)
 	infoSizeUnit = f.colorLib.Syellow(infoSizeUnit)
@@ -582,56 +582,3 @@ func (f *FileFormatter) configureColumns(t table.Writer) {
 		{Name: "Name", Align: text.AlignLeft},
 	})
 }
-
-// humanSize 转换文件大小为可读格式
-//
-// 参数:
-//   - size: 文件大小
-//
-// 返回:
-//   - string: 可读的文件大小
-//   - string: 单位
-func (f *FileFormatter) humanSize(size int64) (string, string) {
-	units := []string{"B", "KB", "MB", "GB", "TB", "PB"}
-	base := float64(1024)
-
-	var unit string
-	sizeFloat := float64(size)
-
-	// 判断处理单位
-	if sizeFloat < base {
-		unit = units[0]
-	} else if sizeFloat < base*base {
-		unit = units[1]
-		sizeFloat /= base
-	} else if sizeFloat < base*base*base {
-		unit = units[2]
-		sizeFloat /= base * base
-	} else if sizeFloat < base*base*base*base {
-		unit = units[3]
-		sizeFloat /= base * base * base
-	} else if sizeFloat < base*base*base*base*base {
-		unit = units[4]
-		sizeFloat /= base * base * base * base
-	} else {
-		unit = units[5]
-		sizeFloat /= base * base * base * base * base
-	}
-
-	var sizeStr string
-	if sizeFloat < 10 {
-		sizeStr = fmt.Sprintf("%.1f", sizeFloat) // 保留一位小数
-	} else {
-		sizeStr = fmt.Sprintf("%.0f", sizeFloat) // 不保留小数
-	}
-
-	// 处理0值情况
-	sizeStr = strings.TrimSuffix(sizeStr, ".0")
-
-	// 处理0.0值情况
-	if sizeStr == "0" || sizeStr == "0.0" {
-		return "0", "B"
-	}
-
-	return sizeStr, unit
-}
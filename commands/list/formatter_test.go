@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/common"
 )
 
 func TestNewFileFormatter(t *testing.T) {
@@ -33,10 +34,7 @@ func TestFileFormatter_GetSafeTerminalWidth(t *testing.T) {
 	}
 }
 
-func TestFileFormatter_HumanSize(t *testing.T) {
-	cl := colorlib.New()
-	formatter := NewFileFormatter(cl)
-
+func TestHumanSize(t *testing.T) {
 	tests := []struct {
 		name         string
 		size         int64
@@ -89,14 +87,14 @@ func TestFileFormatter_HumanSize(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			size, unit := formatter.humanSize(tt.size)
+			size, unit := common.HumanSize(tt.size, 1)
 
 			if size != tt.expectedSize {
-				t.Errorf("humanSize(%v) size = %v, 期望 %v", tt.size, size, tt.expectedSize)
+				t.Errorf("HumanSize(%v) size = %v, 期望 %v", tt.size, size, tt.expectedSize)
 			}
 
 			if unit != tt.expectedUnit {
-				t.Errorf("humanSize(%v) unit = %v, 期望 %v", tt.size, unit, tt.expectedUnit)
+				t.Errorf("HumanSize(%v) unit = %v, 期望 %v", tt.size, unit, tt.expectedUnit)
 			}
 		})
 	}
@@ -287,10 +285,7 @@ func TestFileFormatter_Render(t *testing.T) {
 }
 
 // 基准测试
-func BenchmarkFileFormatter_HumanSize(b *testing.B) {
-	cl := colorlib.New()
-	formatter := NewFileFormatter(cl)
-
+func BenchmarkHumanSize(b *testing.B) {
 	sizes := []int64{
 		0,
 		1024,
@@ -302,7 +297,7 @@ func BenchmarkFileFormatter_HumanSize(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		for _, size := range sizes {
-			formatter.humanSize(size)
+			common.HumanSize(size, 1)
 		}
 	}
 }
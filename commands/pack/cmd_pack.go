@@ -1,10 +1,8 @@
 package pack
 
 import (
-	"errors"
-	"fmt"
-
 	"gitee.com/MM-Q/comprx"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
 	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
@@ -12,13 +10,13 @@ func PackCmdMain() error {
 	// 获取压缩包路径
 	packPath := packCmd.Arg(0)
 	if packPath == "" {
-		return errors.New("压缩包名称不能为空")
+		return exitcode.Usagef("压缩包名称不能为空")
 	}
 
 	// 获取源路径
 	srcPath := packCmd.Arg(1)
 	if srcPath == "" {
-		return errors.New("源路径不能为空")
+		return exitcode.Usagef("源路径不能为空")
 	}
 
 	// 过滤器配置
@@ -32,13 +30,13 @@ func PackCmdMain() error {
 	// 获取压缩级别并检查有效性
 	compressionLevelVal, isValid := types.GetCompressionLevel(compressionLevel.Get())
 	if !isValid {
-		return fmt.Errorf("无效的压缩级别: %s", compressionLevel.Get())
+		return exitcode.Usagef("无效的压缩级别: %s", compressionLevel.Get())
 	}
 
 	// 获取进度条样式并检查有效性
 	progressStyleVal, isValid := types.GetProgressStyle(progressStyle.Get())
 	if !isValid {
-		return fmt.Errorf("无效的进度条样式: %s", progressStyle.Get())
+		return exitcode.Usagef("无效的进度条样式: %s", progressStyle.Get())
 	}
 
 	// 压缩配置
@@ -26,7 +26,7 @@ var (
 )
 
 func InitPackCmd() *qflag.Cmd {
-	packCmd = qflag.NewCmd("pack", "p", flag.ExitOnError)
+	packCmd = qflag.NewCmd("pack", "p", flag.ContinueOnError)
 
 	packCmdCfg := qflag.CmdConfig{
 		UseChinese:  true,
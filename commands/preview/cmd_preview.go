@@ -1,16 +1,15 @@
 package preview
 
 import (
-	"errors"
-
 	"gitee.com/MM-Q/comprx"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
 )
 
 func PreviewCmdMain() error {
 	// 获取压缩包路径
 	packPath := previewCmd.Arg(0)
 	if packPath == "" {
-		return errors.New("压缩包路径不能为空")
+		return exitcode.Usagef("压缩包路径不能为空")
 	}
 
 	// 打印压缩包信息
@@ -19,7 +19,7 @@ var (
 
 // 初始化预览命令
 func InitPreviewCmd() *qflag.Cmd {
-	previewCmd = qflag.NewCmd("preview", "pv", flag.ExitOnError)
+	previewCmd = qflag.NewCmd("preview", "pv", flag.ContinueOnError)
 
 	previewCmdCfg := qflag.CmdConfig{
 		UseChinese:  true,
@@ -6,10 +6,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"gitee.com/MM-Q/fck/commands/internal/common"
 )
 
-// BenchmarkHumanReadableSizeVariousSizes 测试不同大小的格式化性能
-func BenchmarkHumanReadableSizeVariousSizes(b *testing.B) {
+// BenchmarkFormatSizeVariousSizes 测试不同大小的格式化性能
+func BenchmarkFormatSizeVariousSizes(b *testing.B) {
 	testCases := []struct {
 		name string
 		size int64
@@ -24,7 +26,7 @@ func BenchmarkHumanReadableSizeVariousSizes(b *testing.B) {
 	for _, tc := range testCases {
 		b.Run(tc.name, func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
-				humanReadableSize(tc.size, 2)
+				common.FormatSize(tc.size, 2)
 			}
 		})
 	}
@@ -124,7 +124,7 @@ func addPathToList(path string, itemList *items, cl *colorlib.ColorLib) {
 	// 添加到数组
 	*itemList = append(*itemList, item{
 		Name: path,
-		Size: humanReadableSize(size, 2),
+		Size: common.FormatSize(size, 2),
 	})
 }
 
@@ -233,65 +233,6 @@ func getPathSize(path string) (int64, error) {
 	return totalSize, nil
 }
 
-// 预定义单位和对应的阈值
-var (
-	units = []string{"B", "KB", "MB", "GB", "TB", "PB"}
-	// 预计算阈值，避免重复计算
-	thresholds = []float64{
-		1,                                // B
-		1024,                             // KB
-		1024 * 1024,                      // MB
-		1024 * 1024 * 1024,               // GB
-		1024 * 1024 * 1024 * 1024,        // TB
-		1024 * 1024 * 1024 * 1024 * 1024, // PB
-	}
-)
-
-// humanReadableSize 函数用于将字节大小转换为可读的字符串格式
-//
-// 参数:
-//   - size: 需要转换的字节大小
-//   - fn: 保留的小数位数
-//
-// 返回:
-//   - 字节大小的文字表示
-func humanReadableSize(size int64, fn int) string {
-	// 处理0值情况 - 提前返回
-	if size == 0 {
-		return "0 B"
-	}
-
-	sizeFloat := float64(size)
-
-	// 使用循环找到合适的单位，从大到小遍历
-	unitIndex := 0
-	for i := len(thresholds) - 1; i > 0; i-- {
-		if sizeFloat >= thresholds[i] {
-			unitIndex = i
-			sizeFloat /= thresholds[i]
-			break
-		}
-	}
-
-	// 统一的格式化逻辑
-	var formatted string
-	if sizeFloat < 10 && unitIndex > 0 {
-		// 小于10且不是字节时，使用指定小数位数（至少1位）
-		decimals := fn
-		if decimals < 1 {
-			decimals = 1
-		}
-		formatted = fmt.Sprintf("%."+fmt.Sprintf("%d", decimals)+"f", sizeFloat)
-		// 移除末尾的.0
-		formatted = strings.TrimSuffix(formatted, ".0")
-	} else {
-		// 大于等于10或者是字节时，使用整数格式
-		formatted = fmt.Sprintf("%.0f", sizeFloat)
-	}
-
-	return fmt.Sprintf("%s %s", formatted, units[unitIndex])
-}
-
 // 打印文件大小表格到控制台
 func printSizeTable(its items, cl *colorlib.ColorLib) {
 	// 创建表格
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"gitee.com/MM-Q/fck/commands/internal/common"
 )
 
 // TestMain 全局测试入口，控制非verbose模式下的输出重定向
@@ -40,8 +42,8 @@ func TestMain(m *testing.M) {
 	os.Exit(exitCode)
 }
 
-// TestHumanReadableSize 测试人类可读大小格式化函数
-func TestHumanReadableSize(t *testing.T) {
+// TestFormatSize 测试人类可读大小格式化函数
+func TestFormatSize(t *testing.T) {
 	tests := []struct {
 		name     string
 		size     int64
@@ -66,9 +68,9 @@ func TestHumanReadableSize(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := humanReadableSize(tt.size, tt.decimals)
+			result := common.FormatSize(tt.size, tt.decimals)
 			if result != tt.expected {
-				t.Errorf("humanReadableSize(%d, %d) = %s, 期望 %s", tt.size, tt.decimals, result, tt.expected)
+				t.Errorf("common.FormatSize(%d, %d) = %s, 期望 %s", tt.size, tt.decimals, result, tt.expected)
 			}
 		})
 	}
@@ -216,8 +218,8 @@ func TestGetPathSizeWithHiddenFiles(t *testing.T) {
 	})
 }
 
-// BenchmarkHumanReadableSize 性能测试
-func BenchmarkHumanReadableSize(b *testing.B) {
+// BenchmarkFormatSize 性能测试
+func BenchmarkFormatSize(b *testing.B) {
 	sizes := []int64{
 		512,        // B
 		1536,       // KB
@@ -226,9 +228,9 @@ func BenchmarkHumanReadableSize(b *testing.B) {
 	}
 
 	for _, size := range sizes {
-		b.Run(humanReadableSize(size, 2), func(b *testing.B) {
+		b.Run(common.FormatSize(size, 2), func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
-				humanReadableSize(size, 2)
+				common.FormatSize(size, 2)
 			}
 		})
 	}
@@ -287,8 +289,8 @@ func TestExpandPathEdgeCases(t *testing.T) {
 	}
 }
 
-// TestHumanReadableSizeEdgeCases 测试humanReadableSize的边界情况
-func TestHumanReadableSizeEdgeCases(t *testing.T) {
+// TestFormatSizeEdgeCases 测试FormatSize的边界情况
+func TestFormatSizeEdgeCases(t *testing.T) {
 	tests := []struct {
 		name     string
 		size     int64
@@ -304,12 +306,12 @@ func TestHumanReadableSizeEdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := humanReadableSize(tt.size, tt.decimals)
+			result := common.FormatSize(tt.size, tt.decimals)
 			// 对于一些边界情况，我们只检查结果不为空
 			if result == "" {
-				t.Errorf("humanReadableSize(%d, %d) 返回空字符串", tt.size, tt.decimals)
+				t.Errorf("common.FormatSize(%d, %d) 返回空字符串", tt.size, tt.decimals)
 			}
-			t.Logf("humanReadableSize(%d, %d) = %s", tt.size, tt.decimals, result)
+			t.Logf("common.FormatSize(%d, %d) = %s", tt.size, tt.decimals, result)
 		})
 	}
 }
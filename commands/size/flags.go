@@ -21,7 +21,7 @@ var (
 // 初始化
 func InitSizeCmd() *qflag.Cmd {
 	// fck size 子命令
-	sizeCmd = qflag.NewCmd("size", "s", flag.ExitOnError)
+	sizeCmd = qflag.NewCmd("size", "s", flag.ContinueOnError)
 
 	sizeCmdCfg := qflag.CmdConfig{
 		UseChinese:  true,
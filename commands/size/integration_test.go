@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"gitee.com/MM-Q/colorlib"
+	"gitee.com/MM-Q/fck/commands/internal/common"
 )
 
 // 在测试开始前初始化命令
@@ -128,7 +129,7 @@ func TestRealFileSystem(t *testing.T) {
 		if size <= 0 {
 			t.Errorf("当前目录大小应该大于0，实际为%d", size)
 		}
-		t.Logf("当前目录大小: %s", humanReadableSize(size, 2))
+		t.Logf("当前目录大小: %s", common.FormatSize(size, 2))
 	})
 
 	// 测试Go源文件
@@ -145,7 +146,7 @@ func TestRealFileSystem(t *testing.T) {
 				t.Errorf("获取文件%s大小失败: %v", file, err)
 				continue
 			}
-			t.Logf("文件%s大小: %s", file, humanReadableSize(size, 2))
+			t.Logf("文件%s大小: %s", file, common.FormatSize(size, 2))
 		}
 	})
 }
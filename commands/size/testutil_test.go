@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"gitee.com/MM-Q/fck/commands/internal/common"
 )
 
 // TestHelper 测试辅助结构体
@@ -154,7 +156,7 @@ func TestWithHelper(t *testing.T) {
 		t.Errorf("目录大小应该大于0, 实际 %d", dirSize)
 	}
 
-	t.Logf("临时目录大小: %s", humanReadableSize(dirSize, 2))
+	t.Logf("临时目录大小: %s", common.FormatSize(dirSize, 2))
 }
 
 // BenchmarkTestHelper 测试辅助器性能测试
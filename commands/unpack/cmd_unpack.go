@@ -1,10 +1,8 @@
 package unpack
 
 import (
-	"errors"
-	"fmt"
-
 	"gitee.com/MM-Q/comprx"
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
 	"gitee.com/MM-Q/fck/commands/internal/types"
 )
 
@@ -13,7 +11,7 @@ func UnpackCmdMain() error {
 	// 获取压缩包路径
 	packPath := unpackCmd.Arg(0)
 	if packPath == "" {
-		return errors.New("压缩包名称不能为空")
+		return exitcode.Usagef("压缩包名称不能为空")
 	}
 
 	// 获取目标路径
@@ -33,7 +31,7 @@ func UnpackCmdMain() error {
 	// 获取进度条样式并检查有效性
 	progressStyleVal, isValid := types.GetProgressStyle(progressStyle.Get())
 	if !isValid {
-		return fmt.Errorf("无效的进度条样式: %s", progressStyle.Get())
+		return exitcode.Usagef("无效的进度条样式: %s", progressStyle.Get())
 	}
 
 	// 压缩配置
@@ -26,7 +26,7 @@ var (
 
 // InitUnpackCmd 初始化unpack命令及其所有标志
 func InitUnpackCmd() *qflag.Cmd {
-	unpackCmd = qflag.NewCmd("unpack", "up", flag.ExitOnError)
+	unpackCmd = qflag.NewCmd("unpack", "up", flag.ContinueOnError)
 
 	unpackCmdCfg := qflag.CmdConfig{
 		UseChinese:  true,
@@ -4,14 +4,12 @@ package watch
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"os"
-	"os/signal"
 	"strings"
-	"syscall"
 	"time"
 
+	"gitee.com/MM-Q/fck/commands/internal/exitcode"
 	"gitee.com/MM-Q/shellx"
 )
 
@@ -41,9 +39,15 @@ var shellMap = map[string]shellx.ShellType{
 
 // WatchCmdMain 是 watch 子命令的主函数
 //
+// 参数:
+//   - ctx: 上下文, 收到中断信号时取消
+//
 // 返回:
 //   - error: 如果发生错误，返回错误信息，否则返回 nil
-func WatchCmdMain() error {
+//
+// 注意:
+//   - 上下文取消时返回 exitcode.ErrInterrupted, 启用 --exit-on-error 且命令执行失败时返回退出码为 exitcode.Partial 的错误
+func WatchCmdMain(ctx context.Context) error {
 	// 获取命令参数
 	args := watchCmd.Args()                // 执行的命令
 	interval := watchCmdInterval.Get()     // 间隔时间
@@ -58,7 +62,7 @@ func WatchCmdMain() error {
 	// 验证命令参数
 	var command string
 	if len(args) == 0 {
-		return errors.New("command is empty")
+		return exitcode.Usagef("command is empty")
 	} else if len(args) == 1 {
 		command = args[0]
 	} else {
@@ -67,28 +71,14 @@ func WatchCmdMain() error {
 
 	// 验证最大执行次数参数
 	if maxCount < -1 || maxCount == 0 {
-		return errors.New("maxCount must be -1 (unlimited) or a positive number")
+		return exitcode.Usagef("maxCount must be -1 (unlimited) or a positive number")
 	}
 
 	// 验证超时时间参数
 	if timeout <= 0 {
-		return errors.New("timeout must be greater than 0")
+		return exitcode.Usagef("timeout must be greater than 0")
 	}
 
-	// 设置信号处理
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	// 创建上下文
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// 启动信号监听
-	go func() {
-		<-sigChan
-		cancel()
-	}()
-
 	// 执行计数器
 	executionCount := 0
 
@@ -96,7 +86,7 @@ func WatchCmdMain() error {
 	for {
 		select {
 		case <-ctx.Done():
-			return nil
+			return exitcode.ErrInterrupted
 		default:
 		}
 
@@ -124,7 +114,7 @@ func WatchCmdMain() error {
 			// 错误信息始终显示，无论是否静默模式
 			fmt.Println(err)
 			if exitOnError {
-				break
+				return exitcode.New(exitcode.Partial, nil)
 			}
 		}
 
@@ -137,7 +127,7 @@ func WatchCmdMain() error {
 		if interval > 0 && (maxCount <= 0 || executionCount < maxCount) {
 			select {
 			case <-ctx.Done():
-				return nil
+				return exitcode.ErrInterrupted
 			case <-time.After(interval): // 等待指定时间
 			}
 		}
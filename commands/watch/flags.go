@@ -25,7 +25,7 @@ var (
 
 func InitWatchCmd() *qflag.Cmd {
 	// fck watch 子命令
-	watchCmd = qflag.NewCmd("watch", "w", flag.ExitOnError)
+	watchCmd = qflag.NewCmd("watch", "w", flag.ContinueOnError)
 
 	watchCmdCfg := qflag.CmdConfig{
 		UseChinese:  true,
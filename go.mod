@@ -10,13 +10,18 @@ require (
 	gitee.com/MM-Q/qflag v0.4.8
 	gitee.com/MM-Q/shellx v1.0.11
 	gitee.com/MM-Q/verman v0.0.18
+	github.com/cespare/xxhash/v2 v2.3.0
 	github.com/jedib0t/go-pretty/v6 v6.6.8
 	github.com/schollz/progressbar/v3 v3.18.0
+	go.etcd.io/bbolt v1.4.3
+	golang.org/x/crypto v0.42.0
 	golang.org/x/sys v0.37.0
 	golang.org/x/term v0.36.0
+	lukechampine.com/blake3 v1.4.1
 )
 
 require (
+	github.com/klauspost/cpuid/v2 v2.3.0 // indirect
 	github.com/mattn/go-runewidth v0.0.16 // indirect
 	github.com/mitchellh/colorstring v0.0.0-20190213212951-d06e56a500db // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect